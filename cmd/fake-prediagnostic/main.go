@@ -0,0 +1,57 @@
+// fake-prediagnostic levanta el servicio prediagnostic falso de
+// internal/clients/prediagnostictest con algunos casos de ejemplo, para
+// correr BusinessLogic sin el servicio Python:
+//
+//	go run ./cmd/fake-prediagnostic -addr :8000 -usuario 1
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients/prediagnostictest"
+)
+
+func main() {
+	addr := flag.String("addr", ":8000", "dirección en la que escucha")
+	usuario := flag.String("usuario", "1", "user_id del paciente dueño de los casos de ejemplo")
+	flag.Parse()
+
+	service := prediagnostictest.NewService()
+	sembrarCasos(service, *usuario)
+
+	log.Printf("prediagnostic falso escuchando en %s (casos de ejemplo del usuario %s)", *addr, *usuario)
+	log.Fatal(http.ListenAndServe(*addr, service))
+}
+
+// sembrarCasos crea un caso pendiente, uno procesado y uno ya validado por
+// un doctor, con sus radiografías
+func sembrarCasos(service *prediagnostictest.Service, usuario string) {
+	ahora := time.Now()
+	casos := []prediagnostictest.Caso{
+		{Estado: "pending", FechaSubida: ahora.Add(-time.Hour)},
+		{Estado: "completed", FechaSubida: ahora.Add(-24 * time.Hour), ProbNeumonia: 0.87, Etiqueta: "Neumonía"},
+		{Estado: "completed", FechaSubida: ahora.Add(-72 * time.Hour), ProbNeumonia: 0.08, Etiqueta: "Normal"},
+	}
+
+	for i, c := range casos {
+		c.ID = fmt.Sprintf("RAD-EJEMPLO-%d", i+1)
+		c.UserID = usuario
+		c.Radiografia = c.ID + ".jpg"
+		if c.Etiqueta != "" {
+			c.FechaProcesamiento = c.FechaSubida.Add(time.Minute)
+		}
+		service.AgregarCaso(c)
+		service.AgregarImagen(c.Radiografia, []byte{0xFF, 0xD8, 0xFF, 0xD9}) // JPEG vacío
+	}
+
+	service.AgregarDiagnostico(prediagnostictest.Diagnostico{
+		CaseID:       "RAD-EJEMPLO-3",
+		Validacion:   "Si",
+		Diagnostico:  "Sin hallazgos de neumonía",
+		DoctorNombre: "Dra. Ejemplo",
+	})
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/database"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+const (
+	commandsUsage      = "comandos disponibles: migrate, revoke-tokens, unlock-account, set-role"
+	migrateUsage       = "uso: server migrate up | down [pasos] | status"
+	revokeTokensUsage  = "uso: server revoke-tokens <usuario_id> [motivo]"
+	unlockAccountUsage = "uso: server unlock-account <correo>"
+	setRoleUsage       = "uso: server set-role <correo> paciente|doctor|admin"
+)
+
+// runCommand despacha los subcomandos administrativos del binario
+func runCommand(ctx context.Context, cfg config.Config, db *sql.DB, command string, args []string) error {
+	switch command {
+	case "migrate":
+		return runMigrate(ctx, db, args)
+	case "revoke-tokens":
+		return runRevokeTokens(ctx, cfg, db, args)
+	case "unlock-account":
+		return runUnlockAccount(ctx, cfg, db, args)
+	case "set-role":
+		return runSetRole(ctx, cfg, db, args)
+	default:
+		return fmt.Errorf("comando desconocido %q; %s", command, commandsUsage)
+	}
+}
+
+// runMigrate implementa el subcomando `server migrate up|down|status`
+func runMigrate(ctx context.Context, db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("número de pasos inválido %q: %w", args[1], err)
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSIÓN\tNOMBRE\tAPLICADA")
+		for _, st := range statuses {
+			applied := "pendiente"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// runRevokeTokens implementa `server revoke-tokens <usuario_id> [motivo]`:
+// invalida todos los access y refresh tokens del usuario.
+func runRevokeTokens(ctx context.Context, cfg config.Config, db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(revokeTokensUsage)
+	}
+	usuarioID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("usuario_id inválido %q: %w", args[0], err)
+	}
+	motivo := "revocación administrativa"
+	if len(args) > 1 {
+		motivo = strings.Join(args[1:], " ")
+	}
+
+	authService := newCLIAuthService(cfg, db)
+	if err := authService.RevocarTokensUsuario(ctx, usuarioID, motivo); err != nil {
+		return err
+	}
+
+	fmt.Printf("tokens del usuario %d revocados (%s)\n", usuarioID, motivo)
+	return nil
+}
+
+// runUnlockAccount implementa `server unlock-account <correo>`: reinicia los
+// intentos fallidos de la cuenta y deja el desbloqueo en la auditoría.
+func runUnlockAccount(ctx context.Context, cfg config.Config, db *sql.DB, args []string) error {
+	if len(args) != 1 {
+		return errors.New(unlockAccountUsage)
+	}
+	if cfg.Auth.Lockout.Store == "memory" {
+		return errors.New("los intentos de login están en memoria (auth.lockout.store=memory); reinicia el servicio para desbloquear")
+	}
+
+	lockoutService := services.NewLockoutService(
+		repository.NewPostgresLoginAttemptRepository(db),
+		repository.NewPostgresAuditRepository(db),
+		repository.NewPostgresUserRepository(db),
+		repository.NewPostgresUserTokenRepository(db),
+		nil, // desbloquear no envía correos
+		cfg.Auth.Lockout,
+		cfg.Frontend.URL,
+	)
+	if err := lockoutService.DesbloquearCuenta(ctx, args[0], "cli"); err != nil {
+		return err
+	}
+
+	fmt.Printf("cuenta %s desbloqueada\n", args[0])
+	return nil
+}
+
+// runSetRole implementa `server set-role <correo> <rol>`. Es la forma de crear
+// el primer administrador, ya que /register no permite el rol admin.
+func runSetRole(ctx context.Context, cfg config.Config, db *sql.DB, args []string) error {
+	if len(args) != 2 {
+		return errors.New(setRoleUsage)
+	}
+
+	users := repository.NewPostgresUserRepository(db)
+	usuario, err := users.FindByEmail(ctx, args[0])
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("no existe un usuario con correo %s", args[0])
+		}
+		return err
+	}
+
+	adminService := services.NewAdminService(
+		users,
+		repository.NewPostgresDoctorProfileRepository(db),
+		repository.NewPostgresAuditRepository(db),
+		newCLIAuthService(cfg, db),
+		nil, // cambiar el rol no envía correos
+	)
+	if _, err := adminService.CambiarRol(ctx, 0, usuario.ID, args[1]); err != nil {
+		switch {
+		case errors.Is(err, services.ErrRolInvalido):
+			return errors.New(setRoleUsage)
+		case errors.Is(err, services.ErrPerfilDoctorRequerido):
+			return errors.New("solo puede ser doctor quien se registró en /register/doctor")
+		case errors.Is(err, services.ErrTransicionInvalida):
+			return errors.New("la cuenta es una solicitud de doctor sin aprobar; revísala con approveDoctor o rejectDoctor")
+		}
+		return err
+	}
+
+	fmt.Printf("usuario %s ahora tiene rol %s\n", args[0], args[1])
+	return nil
+}
+
+// newCLIAuthService crea un AuthService que solo sirve para revocar tokens
+func newCLIAuthService(cfg config.Config, db *sql.DB) *services.AuthService {
+	return services.NewAuthService(
+		repository.NewPostgresUserRepository(db),
+		repository.NewPostgresRefreshTokenRepository(db),
+		services.NewRevocationService(repository.NewPostgresTokenRevocationRepository(db), cfg.JWT.RevocationCacheTTL),
+		nil, // revocar no requiere las claves de firma
+		nil, // ni el control de intentos de login
+		nil, // ni el segundo factor
+		cfg.JWT,
+	)
+}
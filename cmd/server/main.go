@@ -2,49 +2,161 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
-	"os"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/database"
 	"github.com/unobeswarch/businesslogic/internal/graph"
 	"github.com/unobeswarch/businesslogic/internal/graph/generated"
 	"github.com/unobeswarch/businesslogic/internal/handlers"
+	"github.com/unobeswarch/businesslogic/internal/repository"
 	"github.com/unobeswarch/businesslogic/internal/services"
 )
 
-const defaultPort = "8080"
-
 func main() {
-	port := defaultPort
-	if p := os.Getenv("PORT"); p != "" {
-		port = p
+	configPath := flag.String("config", "", "ruta a un archivo de configuración YAML (también CONFIG_FILE)")
+	flag.Parse()
+
+	// Cargamos la configuración antes de levantar cualquier dependencia
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("error cargando configuración: %v", err)
+	}
+
+	// Subcomandos administrativos (migrate, revoke-tokens): solo requieren la base de datos
+	if command := flag.Arg(0); command != "" {
+		if err := cfg.Database.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		db, err := database.Open(cfg.Database)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+
+		if err := runCommand(context.Background(), cfg, db, command, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Pool de conexiones compartido por todos los repositorios
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	if cfg.Database.AutoMigrate {
+		migrator, err := database.NewMigrator(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := migrator.Up(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	userRepository := repository.NewPostgresUserRepository(db)
+	refreshTokenRepository := repository.NewPostgresRefreshTokenRepository(db)
+	userTokenRepository := repository.NewPostgresUserTokenRepository(db)
+	auditRepository := repository.NewPostgresAuditRepository(db)
+	doctorProfileRepository := repository.NewPostgresDoctorProfileRepository(db)
+	dataExportRepository := repository.NewPostgresDataExportRepository(db)
+	twoFactorRepository := repository.NewPostgresTwoFactorRepository(db)
+	revocationService := services.NewRevocationService(
+		repository.NewPostgresTokenRevocationRepository(db), cfg.JWT.RevocationCacheTTL)
+
+	// Claves asimétricas de firma JWT; se recargan y rotan en segundo plano
+	keyManager, err := services.NewKeyManager(cfg.JWT)
+	if err != nil {
+		log.Fatalf("error cargando claves JWT: %v", err)
+	}
+	go keyManager.Run(context.Background())
+
+	mailSender, err := clients.NewMailSender(cfg.Mail)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// URL del servicio de prediagnóstico (configurable por variable de entorno)
-	prediagnosticURL := os.Getenv("PREDIAGNOSTIC_SERVICE_URL")
-	if prediagnosticURL == "" {
-		prediagnosticURL = "http://localhost:8000" // URL por defecto
+	// Un único cliente de prediagnostic para que los services compartan las conexiones
+	prediagnosticClient := clients.NewPrediagnosticClient(cfg.Prediagnostic)
+
+	// Contadores de intentos de login: en memoria solo sirven con una única instancia
+	var loginAttemptRepository repository.LoginAttemptRepository = repository.NewPostgresLoginAttemptRepository(db)
+	if cfg.Auth.Lockout.Store == "memory" {
+		loginAttemptRepository = repository.NewMemoryLoginAttemptRepository()
 	}
 
 	// Instanciamos los services
-	prediagnosticService := services.NewPrediagnosticService(prediagnosticURL)
-	caseService := services.NewCaseService(prediagnosticURL)
-	authService := services.NewAuthService()
-	diagnosticService := services.NewDiagnosticService(prediagnosticURL)
+	prediagnosticService := services.NewPrediagnosticService(prediagnosticClient)
+	caseService := services.NewCaseService(prediagnosticClient, cfg.Prediagnostic, userRepository)
+	lockoutService := services.NewLockoutService(loginAttemptRepository, auditRepository, userRepository, userTokenRepository,
+		mailSender, cfg.Auth.Lockout, cfg.Frontend.URL)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepository, userTokenRepository,
+		userRepository, lockoutService, cfg.Auth.TwoFactor)
+	authService := services.NewAuthService(userRepository, refreshTokenRepository, revocationService, keyManager,
+		lockoutService, twoFactorService, cfg.JWT)
+	adminService := services.NewAdminService(userRepository, doctorProfileRepository, auditRepository, authService, mailSender)
+	doctorService := services.NewDoctorService(userRepository, doctorProfileRepository)
+	permissionService := services.NewPermissionService(userRepository, cfg.Auth.Permissions)
+	consentRepository := repository.NewPostgresConsentRepository(db)
+	consentService := services.NewConsentService(consentRepository, auditRepository)
+	diagnosticService := services.NewDiagnosticService(prediagnosticClient)
+	passwordService := services.NewPasswordService(userRepository, userTokenRepository, mailSender, authService,
+		cfg.Auth.PasswordResetTTL, cfg.Auth.PasswordResetInterval, cfg.Frontend.URL)
+	verificationService := services.NewVerificationService(userRepository, userTokenRepository, mailSender,
+		cfg.Auth.EmailVerificationTTL, cfg.Auth.VerificationResendInterval, cfg.Frontend.URL)
+	profileService := services.NewProfileService(userRepository, verificationService, authService, mailSender)
+	dataExportService := services.NewDataExportService(userRepository, dataExportRepository, consentRepository, auditRepository,
+		mailSender, prediagnosticClient, cfg.Privacy)
+	go dataExportService.Run(context.Background())
+	accountDeletionService := services.NewAccountDeletionService(userRepository,
+		repository.NewPostgresAccountDeletionRepository(db), userTokenRepository, twoFactorRepository, dataExportRepository,
+		doctorProfileRepository, consentRepository, auditRepository, authService, mailSender, prediagnosticClient, cfg.Privacy, cfg.Frontend.URL)
+	go accountDeletionService.Run(context.Background())
+	userHandler := handlers.NewUserHandler(authService, verificationService, consentService, cfg.Server.TrustForwardedFor)
+	doctorHandler := handlers.NewDoctorHandler(doctorService, verificationService, consentService, cfg.Server.TrustForwardedFor)
+	lockoutHandler := handlers.NewLockoutHandler(lockoutService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(authService, twoFactorService, cfg.Server.TrustForwardedFor)
+	verificationHandler := handlers.NewVerificationHandler(verificationService)
+	passwordHandler := handlers.NewPasswordHandler(passwordService)
+	jwksHandler := handlers.NewJWKSHandler(keyManager)
+	dataExportHandler := handlers.NewDataExportHandler(authService, dataExportService)
+	accountDeletionHandler := handlers.NewAccountDeletionHandler(accountDeletionService)
+	healthHandler := handlers.NewHealthHandler(db, prediagnosticClient)
 
 	// Inyectamos los services en el resolver
 	resolver := &graph.Resolver{
-		PrediagnosticSrv: prediagnosticService,
-		CaseSrv:          caseService,
-		AuthSrv:          authService,
-		DiagnosticSrv:    diagnosticService,
+		PrediagnosticSrv:   prediagnosticService,
+		CaseSrv:            caseService,
+		AuthSrv:            authService,
+		DiagnosticSrv:      diagnosticService,
+		AdminSrv:           adminService,
+		DoctorSrv:          doctorService,
+		PermissionSrv:      permissionService,
+		ProfileSrv:         profileService,
+		DataExportSrv:      dataExportService,
+		AccountDeletionSrv: accountDeletionService,
+		ConsentSrv:         consentService,
 	}
 
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{
+		Resolvers:  resolver,
+		Directives: graph.NewDirectives(authService, permissionService),
+	}))
 
-	// Middleware para extraer Authorization header y agregarlo al contexto
+	// Middleware de CORS; la autenticación de /query la resuelve
+	// handlers.AuthContextMiddleware
 	authMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			//CORS headers
@@ -56,27 +168,39 @@ func main() {
 				w.WriteHeader(http.StatusOK)
 				return
 			}
-			// Extraer Authorization header
-			authHeader := r.Header.Get("Authorization")
-
-			// Agregar al contexto si existe
-			ctx := r.Context()
-			if authHeader != "" {
-				ctx = context.WithValue(ctx, "Authorization", authHeader)
-			}
 
 			// Continuar con el siguiente handler
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r)
 		})
 	}
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", authMiddleware(srv))
-	http.Handle("/register", authMiddleware(http.HandlerFunc(handlers.HandlerRegistrarUsuario)))
-	http.Handle("/auth", authMiddleware(http.HandlerFunc(handlers.HandlerIniciarSesion)))
-	http.Handle("/validation", authMiddleware(http.HandlerFunc(handlers.HandlerValidacion)))
-
-	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Printf("prediagnostic service URL: %s", prediagnosticURL)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	dataLoaderMiddleware := handlers.DataLoaderMiddleware(userRepository, caseService, prediagnosticService)
+	http.Handle("/query", authMiddleware(handlers.AuthContextMiddleware(authService, consentService, cfg.Server.TrustForwardedFor)(dataLoaderMiddleware(srv))))
+	http.Handle("/register", authMiddleware(http.HandlerFunc(userHandler.HandlerRegistrarUsuario)))
+	http.Handle("/register/doctor", authMiddleware(http.HandlerFunc(doctorHandler.HandlerRegistrarDoctor)))
+	http.Handle("/auth", authMiddleware(http.HandlerFunc(userHandler.HandlerIniciarSesion)))
+	http.Handle("/auth/refresh", authMiddleware(http.HandlerFunc(userHandler.HandlerRefrescarSesion)))
+	http.Handle("/auth/2fa", authMiddleware(http.HandlerFunc(twoFactorHandler.HandlerVerificarSegundoFactor)))
+	http.Handle("/auth/2fa/enroll", authMiddleware(http.HandlerFunc(twoFactorHandler.HandlerEnrolar)))
+	http.Handle("/auth/2fa/confirm", authMiddleware(http.HandlerFunc(twoFactorHandler.HandlerConfirmar)))
+	http.Handle("/auth/2fa/disable", authMiddleware(http.HandlerFunc(twoFactorHandler.HandlerDesactivar)))
+	http.Handle("/auth/2fa/recovery-codes", authMiddleware(http.HandlerFunc(twoFactorHandler.HandlerRegenerarCodigos)))
+	http.Handle("/auth/unlock", authMiddleware(http.HandlerFunc(lockoutHandler.HandlerDesbloquearCuenta)))
+	http.Handle("/auth/logout", authMiddleware(http.HandlerFunc(userHandler.HandlerCerrarSesion)))
+	http.Handle("/password/forgot", authMiddleware(http.HandlerFunc(passwordHandler.HandlerOlvidoContrasena)))
+	http.Handle("/password/reset", authMiddleware(http.HandlerFunc(passwordHandler.HandlerRestablecerContrasena)))
+	http.Handle("/verify-email", authMiddleware(http.HandlerFunc(verificationHandler.HandlerVerificarCorreo)))
+	http.Handle("/verify-email/resend", authMiddleware(http.HandlerFunc(verificationHandler.HandlerReenviarVerificacion)))
+	http.Handle("/verify-email/change", authMiddleware(http.HandlerFunc(verificationHandler.HandlerConfirmarCambioCorreo)))
+	http.Handle("/data-export/download", authMiddleware(http.HandlerFunc(dataExportHandler.HandlerDescargarExportacion)))
+	http.Handle("/account/deletion/cancel", authMiddleware(http.HandlerFunc(accountDeletionHandler.HandlerCancelarEliminacion)))
+	http.Handle("/validation", authMiddleware(http.HandlerFunc(userHandler.HandlerValidacion)))
+	http.Handle("/.well-known/jwks.json", authMiddleware(http.HandlerFunc(jwksHandler.HandlerJWKS)))
+	http.HandleFunc("/health", healthHandler.HandlerHealth)
+	http.HandleFunc("/metrics", healthHandler.HandlerMetrics)
+
+	log.Printf("connect to http://localhost:%s/ for GraphQL playground", cfg.Server.Port)
+	log.Printf("prediagnostic service URL: %s", cfg.Prediagnostic.URL)
+	log.Fatal(http.ListenAndServe(":"+cfg.Server.Port, nil))
 }
@@ -0,0 +1,147 @@
+package clients
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitoAbierto indica que el servicio se considera caído y la petición
+// se rechazó sin enviarla
+var ErrCircuitoAbierto = errors.New("servicio prediagnostic no disponible: circuito abierto")
+
+// Estados del circuito
+const (
+	CircuitoCerrado     = "cerrado"
+	CircuitoAbierto     = "abierto"
+	CircuitoSemiabierto = "semiabierto"
+)
+
+type resultadoLlamada int
+
+const (
+	llamadaExitosa resultadoLlamada = iota
+	llamadaFallida
+	// llamadaCancelada es una petición que canceló quien la hizo; no dice
+	// nada sobre el servicio
+	llamadaCancelada
+)
+
+// CircuitBreaker deja de enviar peticiones al servicio después de
+// maxFallos fallos seguidos. Mientras está abierto rechaza todo con
+// ErrCircuitoAbierto; pasado duracionAbierto deja pasar una sola petición de
+// prueba (semiabierto): si tiene éxito se cierra y si falla se vuelve a abrir.
+//
+// Cada cambio de estado inicia una generación nueva y solo cuentan los
+// resultados de peticiones autorizadas en la generación vigente: una petición
+// lenta enviada antes de abrirse el circuito no puede cerrarlo ni reabrirlo
+// mientras se espera el resultado de la petición de prueba.
+type CircuitBreaker struct {
+	maxFallos       int
+	duracionAbierto time.Duration
+	ahora           func() time.Time
+
+	mu             sync.Mutex
+	estado         string
+	fallosSeguidos int
+	abiertoHasta   time.Time
+	probando       bool
+	generacion     uint64
+	aperturas      uint64
+}
+
+func NewCircuitBreaker(maxFallos int, duracionAbierto time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		maxFallos:       maxFallos,
+		duracionAbierto: duracionAbierto,
+		ahora:           time.Now,
+		estado:          CircuitoCerrado,
+	}
+}
+
+// permitir indica si se puede enviar una petición y retorna la generación
+// en la que se autorizó. Cada llamada sin error debe seguirse de registrar
+// con esa generación y el resultado.
+func (b *CircuitBreaker) permitir() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.estado {
+	case CircuitoAbierto:
+		if b.ahora().Before(b.abiertoHasta) {
+			return 0, ErrCircuitoAbierto
+		}
+		b.cambiarEstado(CircuitoSemiabierto)
+		b.probando = true
+	case CircuitoSemiabierto:
+		// Solo una petición de prueba a la vez
+		if b.probando {
+			return 0, ErrCircuitoAbierto
+		}
+		b.probando = true
+	}
+	return b.generacion, nil
+}
+
+// registrar cuenta el resultado de una petición autorizada en generacion;
+// si el circuito cambió de estado desde entonces, el resultado se descarta
+func (b *CircuitBreaker) registrar(generacion uint64, resultado resultadoLlamada) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generacion != b.generacion {
+		return
+	}
+	if b.estado == CircuitoSemiabierto {
+		b.probando = false
+	}
+	switch resultado {
+	case llamadaExitosa:
+		if b.estado != CircuitoCerrado {
+			b.cambiarEstado(CircuitoCerrado)
+		}
+		b.fallosSeguidos = 0
+	case llamadaFallida:
+		b.fallosSeguidos++
+		if b.estado == CircuitoSemiabierto || b.fallosSeguidos >= b.maxFallos {
+			b.abrir()
+		}
+	}
+}
+
+func (b *CircuitBreaker) abrir() {
+	b.aperturas++
+	b.cambiarEstado(CircuitoAbierto)
+	b.abiertoHasta = b.ahora().Add(b.duracionAbierto)
+}
+
+func (b *CircuitBreaker) cambiarEstado(estado string) {
+	b.estado = estado
+	b.generacion++
+}
+
+// EstadoCircuito es una foto del circuito para health checks y métricas
+type EstadoCircuito struct {
+	Estado         string
+	FallosSeguidos int
+	// AbiertoHasta es cuándo se permitirá la siguiente petición de prueba; solo
+	// si Estado es CircuitoAbierto
+	AbiertoHasta time.Time
+	// Aperturas cuenta las veces que el circuito pasó a abierto
+	Aperturas uint64
+}
+
+func (b *CircuitBreaker) Estado() EstadoCircuito {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := EstadoCircuito{
+		Estado:         b.estado,
+		FallosSeguidos: b.fallosSeguidos,
+		Aperturas:      b.aperturas,
+	}
+	if b.estado == CircuitoAbierto {
+		e.AbiertoHasta = b.abiertoHasta
+	}
+	return e
+}
@@ -0,0 +1,173 @@
+package clients
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// pasoCircuito es una acción sobre el circuito: autorizar la petición
+// nombrada, registrar su resultado o adelantar el reloj
+type pasoCircuito func(t *testing.T, b *CircuitBreaker, generaciones map[string]uint64, reloj *time.Time)
+
+func permitir(peticion string, esperado error) pasoCircuito {
+	return func(t *testing.T, b *CircuitBreaker, generaciones map[string]uint64, reloj *time.Time) {
+		t.Helper()
+		generacion, err := b.permitir()
+		if !errors.Is(err, esperado) {
+			t.Fatalf("permitir %s: se esperaba %v, se obtuvo %v", peticion, esperado, err)
+		}
+		if err == nil {
+			generaciones[peticion] = generacion
+		}
+	}
+}
+
+func registrar(peticion string, resultado resultadoLlamada) pasoCircuito {
+	return func(t *testing.T, b *CircuitBreaker, generaciones map[string]uint64, reloj *time.Time) {
+		t.Helper()
+		generacion, ok := generaciones[peticion]
+		if !ok {
+			t.Fatalf("la petición %s no fue autorizada", peticion)
+		}
+		b.registrar(generacion, resultado)
+	}
+}
+
+func avanzar(d time.Duration) pasoCircuito {
+	return func(t *testing.T, b *CircuitBreaker, generaciones map[string]uint64, reloj *time.Time) {
+		*reloj = reloj.Add(d)
+	}
+}
+
+func TestCircuitBreakerGeneraciones(t *testing.T) {
+	const abierto = 10 * time.Second
+
+	casos := []struct {
+		nombre    string
+		pasos     []pasoCircuito
+		estado    string
+		aperturas uint64
+	}{
+		{
+			nombre: "fallos seguidos abren el circuito",
+			pasos: []pasoCircuito{
+				permitir("a", nil), registrar("a", llamadaFallida),
+				permitir("b", nil), registrar("b", llamadaFallida),
+				permitir("c", nil), registrar("c", llamadaFallida),
+				permitir("d", ErrCircuitoAbierto),
+			},
+			estado: CircuitoAbierto, aperturas: 1,
+		},
+		{
+			nombre: "un éxito reinicia los fallos seguidos",
+			pasos: []pasoCircuito{
+				permitir("a", nil), registrar("a", llamadaFallida),
+				permitir("b", nil), registrar("b", llamadaFallida),
+				permitir("c", nil), registrar("c", llamadaExitosa),
+				permitir("d", nil), registrar("d", llamadaFallida),
+			},
+			estado: CircuitoCerrado,
+		},
+		{
+			nombre: "las cancelaciones no cuentan como fallos",
+			pasos: []pasoCircuito{
+				permitir("a", nil), registrar("a", llamadaCancelada),
+				permitir("b", nil), registrar("b", llamadaCancelada),
+				permitir("c", nil), registrar("c", llamadaCancelada),
+			},
+			estado: CircuitoCerrado,
+		},
+		{
+			nombre: "semiabierto deja pasar una sola prueba",
+			pasos: []pasoCircuito{
+				permitir("a", nil), permitir("b", nil), permitir("c", nil),
+				registrar("a", llamadaFallida), registrar("b", llamadaFallida), registrar("c", llamadaFallida),
+				avanzar(abierto),
+				permitir("prueba", nil),
+				permitir("otra", ErrCircuitoAbierto),
+			},
+			estado: CircuitoSemiabierto, aperturas: 1,
+		},
+		{
+			nombre: "la prueba exitosa cierra el circuito",
+			pasos: []pasoCircuito{
+				permitir("a", nil), permitir("b", nil), permitir("c", nil),
+				registrar("a", llamadaFallida), registrar("b", llamadaFallida), registrar("c", llamadaFallida),
+				avanzar(abierto),
+				permitir("prueba", nil), registrar("prueba", llamadaExitosa),
+				permitir("siguiente", nil),
+			},
+			estado: CircuitoCerrado, aperturas: 1,
+		},
+		{
+			nombre: "la prueba fallida reabre el circuito",
+			pasos: []pasoCircuito{
+				permitir("a", nil), permitir("b", nil), permitir("c", nil),
+				registrar("a", llamadaFallida), registrar("b", llamadaFallida), registrar("c", llamadaFallida),
+				avanzar(abierto),
+				permitir("prueba", nil), registrar("prueba", llamadaFallida),
+				permitir("siguiente", ErrCircuitoAbierto),
+			},
+			estado: CircuitoAbierto, aperturas: 2,
+		},
+		{
+			nombre: "una petición lenta anterior a la apertura no cierra el circuito",
+			pasos: []pasoCircuito{
+				permitir("lenta", nil),
+				permitir("a", nil), permitir("b", nil), permitir("c", nil),
+				registrar("a", llamadaFallida), registrar("b", llamadaFallida), registrar("c", llamadaFallida),
+				registrar("lenta", llamadaExitosa),
+				permitir("d", ErrCircuitoAbierto),
+			},
+			estado: CircuitoAbierto, aperturas: 1,
+		},
+		{
+			nombre: "una petición lenta no decide por la prueba",
+			pasos: []pasoCircuito{
+				permitir("lenta", nil),
+				permitir("a", nil), permitir("b", nil), permitir("c", nil),
+				registrar("a", llamadaFallida), registrar("b", llamadaFallida), registrar("c", llamadaFallida),
+				avanzar(abierto),
+				permitir("prueba", nil),
+				registrar("lenta", llamadaFallida),
+				// La prueba sigue en curso: no se reabrió ni se liberó
+				permitir("otra", ErrCircuitoAbierto),
+				registrar("prueba", llamadaExitosa),
+			},
+			estado: CircuitoCerrado, aperturas: 1,
+		},
+		{
+			nombre: "una prueba cancelada libera el lugar para otra",
+			pasos: []pasoCircuito{
+				permitir("a", nil), permitir("b", nil), permitir("c", nil),
+				registrar("a", llamadaFallida), registrar("b", llamadaFallida), registrar("c", llamadaFallida),
+				avanzar(abierto),
+				permitir("prueba", nil), registrar("prueba", llamadaCancelada),
+				permitir("otra prueba", nil),
+			},
+			estado: CircuitoSemiabierto, aperturas: 1,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			reloj := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			b := NewCircuitBreaker(3, abierto)
+			b.ahora = func() time.Time { return reloj }
+
+			generaciones := make(map[string]uint64)
+			for _, paso := range c.pasos {
+				paso(t, b, generaciones, &reloj)
+			}
+
+			estado := b.Estado()
+			if estado.Estado != c.estado {
+				t.Fatalf("se esperaba el circuito %s, está %s", c.estado, estado.Estado)
+			}
+			if estado.Aperturas != c.aperturas {
+				t.Fatalf("se esperaban %d aperturas, hubo %d", c.aperturas, estado.Aperturas)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+)
+
+// MailMessage es un correo de texto plano
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MailSender envía correos transaccionales (recuperación de contraseña, etc.)
+type MailSender interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// NewMailSender crea el MailSender indicado por la configuración
+func NewMailSender(cfg config.MailConfig) (MailSender, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailSender(cfg), nil
+	case "file":
+		return NewFileMailSender(cfg.From, cfg.Dir), nil
+	case "log":
+		return NewLogMailSender(cfg.From), nil
+	default:
+		return nil, fmt.Errorf("driver de correo desconocido: %q", cfg.Driver)
+	}
+}
+
+// SMTPMailSender envía correos por SMTP con autenticación PLAIN
+type SMTPMailSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailSender(cfg config.MailConfig) *SMTPMailSender {
+	var auth smtp.Auth
+	if cfg.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.Host)
+	}
+	return &SMTPMailSender{
+		addr: net.JoinHostPort(cfg.SMTP.Host, strconv.Itoa(cfg.SMTP.Port)),
+		from: cfg.From,
+		auth: auth,
+	}
+}
+
+func (s *SMTPMailSender) Send(ctx context.Context, msg MailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateRecipient(msg.To); err != nil {
+		return err
+	}
+
+	// smtp.SendMail usa STARTTLS automáticamente si el servidor lo soporta
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, buildMail(s.from, msg)); err != nil {
+		return fmt.Errorf("error enviando correo a %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// LogMailSender escribe los correos en el log en lugar de enviarlos (desarrollo local)
+type LogMailSender struct {
+	from string
+}
+
+func NewLogMailSender(from string) *LogMailSender {
+	return &LogMailSender{from: from}
+}
+
+func (s *LogMailSender) Send(ctx context.Context, msg MailMessage) error {
+	log.Printf("Correo (no enviado) de %s para %s\nAsunto: %s\n%s", s.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailSender guarda cada correo como un archivo .eml en un directorio (desarrollo local)
+type FileMailSender struct {
+	from string
+	dir  string
+}
+
+func NewFileMailSender(from, dir string) *FileMailSender {
+	return &FileMailSender{from: from, dir: dir}
+}
+
+func (s *FileMailSender) Send(ctx context.Context, msg MailMessage) error {
+	if err := validateRecipient(msg.To); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), sanitizeFilename(msg.To))
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, buildMail(s.from, msg), 0o644); err != nil {
+		return fmt.Errorf("error guardando correo en %s: %w", path, err)
+	}
+
+	log.Printf("Correo para %s guardado en %s", msg.To, path)
+	return nil
+}
+
+// buildMail arma el mensaje RFC 5322 con el asunto codificado para admitir tildes
+func buildMail(from string, msg MailMessage) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// validateRecipient evita inyección de cabeceras a través del destinatario
+func validateRecipient(to string) error {
+	if to == "" || strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("destinatario de correo inválido: %q", to)
+	}
+	return nil
+}
+
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -2,29 +2,97 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math/rand/v2"
+	"mime/multipart"
 	"net/http"
+	"sync/atomic"
 	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+)
+
+var (
+	// ErrDiagnosticoNoEncontrado indica que el caso aún no tiene diagnóstico médico
+	ErrDiagnosticoNoEncontrado = errors.New("diagnóstico no encontrado")
+	// ErrSinRadiografias indica que el usuario no tiene casos en el servicio
+	ErrSinRadiografias = errors.New("no radiografias")
 )
 
+// DetalleSinCasos es el detail con el que el servicio responde 404 a
+// anonymize y DELETE de los casos de un usuario que no tiene casos. Un 404
+// con otro detail (por ejemplo "Not Found" de una versión del servicio sin
+// esas rutas) es un error.
+const DetalleSinCasos = "No se encontraron casos para el usuario"
+
+// maxImagenBytes limita el tamaño de una radiografía descargada
+const maxImagenBytes = 20 << 20
+
+// PrediagnosticAPI son las operaciones del servicio prediagnostic que usan
+// los services. PreDiagnosticClient la implementa; para pruebas sin el
+// servicio real se puede apuntar un PreDiagnosticClient a
+// prediagnostictest.Server o usar otra implementación.
+type PrediagnosticAPI interface {
+	GetCases(ctx context.Context) ([]CaseSummaryDTO, error)
+	GetCasesByUserID(ctx context.Context, userID string) ([]CaseSummaryDTO, error)
+	GetPreDiagnostic(ctx context.Context, id string) (*CaseDTO, error)
+	GetDiagnostic(ctx context.Context, caseID string) (*DiagnosticDTO, error)
+	GetImage(ctx context.Context, filename string) ([]byte, error)
+	CreateDiagnostic(ctx context.Context, idempotencyKey, prediagnosticID, aprobacion, comentario string) (*CreateDiagnosticResponseDTO, error)
+	ProcessImage(ctx context.Context, userID, filename string, imagen io.Reader) (*ProcessResponseDTO, error)
+	AnonymizeCasesByUserID(ctx context.Context, userID string) error
+	DeleteCasesByUserID(ctx context.Context, userID string) error
+}
+
+// PreDiagnosticClient llama al servicio prediagnostic. Cada método recibe el
+// contexto de quien lo llama, así una petición GraphQL cancelada cancela
+// también la llamada, y la limita con el tiempo máximo de su operación.
+//
+// Todas las peticiones pasan por un CircuitBreaker compartido. Solo las
+// consultas (GET) se reintentan; las escrituras no, porque el servicio pudo
+// haberlas aplicado aunque la respuesta no llegara.
 type PreDiagnosticClient struct {
 	BaseURL string
+	// HTTPClient se comparte entre todas las llamadas para reutilizar las
+	// conexiones; se puede reemplazar, por ejemplo para usar un proxy
+	HTTPClient *http.Client
+	cfg        config.PrediagnosticConfig
+	breaker    *CircuitBreaker
+
+	peticiones atomic.Uint64
+	fallos     atomic.Uint64
+	reintentos atomic.Uint64
+	rechazadas atomic.Uint64
+}
+
+// EstadoPrediagnostic resume el estado del cliente para health checks y métricas
+type EstadoPrediagnostic struct {
+	Circuito   EstadoCircuito
+	Peticiones uint64 // peticiones enviadas, incluidos los reintentos
+	Fallos     uint64 // errores de conexión, timeouts y respuestas 5xx
+	Reintentos uint64
+	Rechazadas uint64 // rechazadas sin enviarlas porque el circuito estaba abierto
 }
 
 // GetCasesByUserID obtiene los casos del usuario desde el servicio prediagnostic
-func (c *PreDiagnosticClient) GetCasesByUserID(userID string) ([]map[string]interface{}, error) {
+func (c *PreDiagnosticClient) GetCasesByUserID(ctx context.Context, userID string) ([]CaseSummaryDTO, error) {
 	url := fmt.Sprintf("%s/prediagnostic/cases/%s", c.BaseURL, userID)
 
-	resp, err := http.Get(url)
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.QueryTimeout)
+	defer cancel()
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("no radiografias")
+		return nil, fmt.Errorf("%w del usuario %s", ErrSinRadiografias, userID)
 	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("respuesta HTTP %d: %s", resp.StatusCode, resp.Status)
@@ -34,31 +102,133 @@ func (c *PreDiagnosticClient) GetCasesByUserID(userID string) ([]map[string]inte
 	if err != nil {
 		return nil, err
 	}
-	if len(body) == 0 {
-		return nil, fmt.Errorf("respuesta vacía del servidor")
-	}
 
 	// El servicio Python devuelve {cases: [...]} no directamente [...]
-	var responseWrapper struct {
-		Cases []map[string]interface{} `json:"cases"`
-	}
-	if err := json.Unmarshal(body, &responseWrapper); err != nil {
-		return nil, fmt.Errorf("error parseando JSON de casos: %w", err)
+	var responseWrapper caseListDTO
+	if err := decodificarRespuesta(body, &responseWrapper, "casos"); err != nil {
+		return nil, err
 	}
 
 	return responseWrapper.Cases, nil
 }
 
-func NewPrediagnosticClient(baseURL string) *PreDiagnosticClient {
-	return &PreDiagnosticClient{BaseURL: baseURL}
+func NewPrediagnosticClient(cfg config.PrediagnosticConfig) *PreDiagnosticClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 16
+	return &PreDiagnosticClient{
+		BaseURL:    cfg.URL,
+		HTTPClient: &http.Client{Transport: transport},
+		cfg:        cfg,
+		breaker:    NewCircuitBreaker(cfg.BreakerFailures, cfg.BreakerOpenDuration),
+	}
+}
+
+// Estado retorna el estado del circuito y los contadores de peticiones
+func (c *PreDiagnosticClient) Estado() EstadoPrediagnostic {
+	return EstadoPrediagnostic{
+		Circuito:   c.breaker.Estado(),
+		Peticiones: c.peticiones.Load(),
+		Fallos:     c.fallos.Load(),
+		Reintentos: c.reintentos.Load(),
+		Rechazadas: c.rechazadas.Load(),
+	}
+}
+
+// do envía la petición a través del circuito. Un error de conexión, un
+// timeout o una respuesta 5xx cuentan como fallo del servicio; una petición
+// que canceló quien la hizo no cuenta.
+func (c *PreDiagnosticClient) do(req *http.Request) (*http.Response, error) {
+	generacion, err := c.breaker.permitir()
+	if err != nil {
+		c.rechazadas.Add(1)
+		return nil, err
+	}
+	c.peticiones.Add(1)
+
+	resp, err := c.HTTPClient.Do(req)
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		c.breaker.registrar(generacion, llamadaCancelada)
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		c.fallos.Add(1)
+		c.breaker.registrar(generacion, llamadaFallida)
+	default:
+		c.breaker.registrar(generacion, llamadaExitosa)
+	}
+	return resp, err
+}
+
+// get envía un GET con el contexto dado, reintentando los errores de
+// conexión y las respuestas 5xx con espera exponencial y jitter. Si se
+// agotan los intentos retorna el último error o la última respuesta.
+func (c *PreDiagnosticClient) get(ctx context.Context, url string) (*http.Response, error) {
+	for intento := 0; ; intento++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.do(req)
+		if intento >= c.cfg.MaxRetries || !reintentable(ctx, resp, err) {
+			return resp, err
+		}
+
+		motivo := fmt.Sprint(err)
+		if resp != nil {
+			motivo = resp.Status
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		espera := c.esperaReintento(intento)
+		log.Printf("prediagnostic: reintentando GET %s en %v (intento %d de %d): %s", url, espera, intento+1, c.cfg.MaxRetries, motivo)
+
+		temporizador := time.NewTimer(espera)
+		select {
+		case <-temporizador.C:
+		case <-ctx.Done():
+			temporizador.Stop()
+			return nil, fmt.Errorf("GET %s: %w", url, ctx.Err())
+		}
+		c.reintentos.Add(1)
+	}
 }
 
-func (c *PreDiagnosticClient) GetPreDiagnostic(id string) (map[string]interface{}, error) {
+// reintentable indica si vale la pena repetir una consulta: el servicio no
+// respondió o respondió con un error temporal, y quien llama sigue esperando
+func reintentable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrCircuitoAbierto) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// esperaReintento es un tiempo aleatorio entre cero y
+// RetryBackoffBase·2^intento, como máximo RetryBackoffMax ("full jitter"),
+// para que las instancias no reintenten todas a la vez
+func (c *PreDiagnosticClient) esperaReintento(intento int) time.Duration {
+	limite := c.cfg.RetryBackoffMax
+	if intento < 30 {
+		limite = min(c.cfg.RetryBackoffBase<<intento, c.cfg.RetryBackoffMax)
+	}
+	if limite <= 0 {
+		return 0
+	}
+	return rand.N(limite)
+}
+
+func (c *PreDiagnosticClient) GetPreDiagnostic(ctx context.Context, id string) (*CaseDTO, error) {
 	url := fmt.Sprintf("%s/prediagnostic/case/%s", c.BaseURL, id)
 
-	resp, err := http.Get(url)
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.QueryTimeout)
+	defer cancel()
+	resp, err := c.get(ctx, url)
 	if err != nil {
-		fmt.Printf("Error en la petición HTTP: %v\n", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -70,39 +240,25 @@ func (c *PreDiagnosticClient) GetPreDiagnostic(id string) (map[string]interface{
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("Error leyendo el body: %v\n", err)
 		return nil, err
 	}
 
-	// Debug: Mostrar el contenido de la respuesta
-	fmt.Printf("Respuesta del servidor: %s\n", string(body))
-
-	// Verificar si el body está vacío
-	if len(body) == 0 {
-		return nil, fmt.Errorf("respuesta vacía del servidor")
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		fmt.Printf("Error parseando JSON: %v\n", err)
-		return nil, fmt.Errorf("error parseando JSON: %w", err)
-	}
-
-	// Verificar si el resultado es nil
-	if result == nil {
-		return nil, fmt.Errorf("el servidor devolvió null")
+	var result CaseDTO
+	if err := decodificarRespuesta(body, &result, "prediagnóstico "+id); err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return &result, nil
 }
 
 // GetCases obtiene todos los casos del servicio de prediagnóstico
-func (c *PreDiagnosticClient) GetCases() ([]map[string]interface{}, error) {
+func (c *PreDiagnosticClient) GetCases(ctx context.Context) ([]CaseSummaryDTO, error) {
 	url := fmt.Sprintf("%s/prediagnostic/cases", c.BaseURL)
 
-	resp, err := http.Get(url)
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.QueryTimeout)
+	defer cancel()
+	resp, err := c.get(ctx, url)
 	if err != nil {
-		fmt.Printf("Error en la petición HTTP para obtener casos: %v\n", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -114,29 +270,22 @@ func (c *PreDiagnosticClient) GetCases() ([]map[string]interface{}, error) {
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("Error leyendo el body de casos: %v\n", err)
 		return nil, err
 	}
 
-	// Debug: Mostrar el contenido de la respuesta
-	fmt.Printf("Respuesta de casos del servidor: %s\n", string(body))
-
-	// Verificar si el body está vacío
-	if len(body) == 0 {
-		return nil, fmt.Errorf("respuesta vacía del servidor")
-	}
-
-	var result []map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		fmt.Printf("Error parseando JSON de casos: %v\n", err)
-		return nil, fmt.Errorf("error parseando JSON de casos: %w", err)
+	var result []CaseSummaryDTO
+	if err := decodificarRespuesta(body, &result, "casos"); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
-// CreateDiagnostic envía una solicitud POST para crear un diagnóstico
-func (c *PreDiagnosticClient) CreateDiagnostic(prediagnosticID, aprobacion, comentario string) (map[string]interface{}, error) {
+// CreateDiagnostic envía una solicitud POST para crear un diagnóstico. No se
+// reintenta: si la respuesta no llega, quien llama puede repetirla con la
+// misma idempotencyKey (header Idempotency-Key) y el servicio retorna el
+// diagnóstico ya creado en lugar de crear otro.
+func (c *PreDiagnosticClient) CreateDiagnostic(ctx context.Context, idempotencyKey, prediagnosticID, aprobacion, comentario string) (*CreateDiagnosticResponseDTO, error) {
 	url := fmt.Sprintf("%s/prediagnostic/diagnostic/%s", c.BaseURL, prediagnosticID)
 
 	// Convertir "Si"/"No" a boolean para el servicio externo
@@ -148,26 +297,29 @@ func (c *PreDiagnosticClient) CreateDiagnostic(prediagnosticID, aprobacion, come
 	}
 
 	// Preparar el payload
-	payload := map[string]interface{}{
-		"prediagnostic_id": prediagnosticID,
-		"aprobacion":       aprobacionBool, // Enviamos boolean al servicio externo
-		"comentario":       comentario,
-		"fecha_revision":   fmt.Sprintf("%d", time.Now().Unix()), // timestamp actual
+	payload := createDiagnosticRequestDTO{
+		PrediagnosticID: prediagnosticID,
+		Aprobacion:      aprobacionBool, // Enviamos boolean al servicio externo
+		Comentario:      comentario,
+		FechaRevision:   fmt.Sprintf("%d", time.Now().Unix()), // timestamp actual
 	}
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		fmt.Printf("Error marshaling payload: %v\n", err)
 		return nil, err
 	}
 
-	// Debug: Mostrar URL y payload
-	fmt.Printf("Enviando POST a: %s\n", url)
-	fmt.Printf("Payload enviado: %s\n", string(jsonPayload))
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.WriteTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonPayload))
+	resp, err := c.do(req)
 	if err != nil {
-		fmt.Printf("Error en la petición POST: %v\n", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -175,32 +327,24 @@ func (c *PreDiagnosticClient) CreateDiagnostic(prediagnosticID, aprobacion, come
 	// Leer el body antes de verificar el código de estado para obtener más información del error
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("Error leyendo el body de diagnóstico: %v\n", err)
 		return nil, err
 	}
 
-	// Debug: Mostrar el contenido de la respuesta
-	fmt.Printf("Respuesta de diagnóstico del servidor (Status %d): %s\n", resp.StatusCode, string(body))
-
 	// Verificar el código de estado
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		// Intentar parsear el error del body
-		var errorResult map[string]interface{}
-		if err := json.Unmarshal(body, &errorResult); err == nil {
-			if detail, ok := errorResult["detail"]; ok {
-				return nil, fmt.Errorf("respuesta HTTP %d: %v", resp.StatusCode, detail)
-			}
+		if detail := detalleError(body); detail != "" {
+			return nil, fmt.Errorf("respuesta HTTP %d: %s", resp.StatusCode, detail)
 		}
 		return nil, fmt.Errorf("respuesta HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		fmt.Printf("Error parseando JSON de diagnóstico: %v\n", err)
-		return nil, fmt.Errorf("error parseando JSON de diagnóstico: %w", err)
+	var result CreateDiagnosticResponseDTO
+	if err := decodificarRespuesta(body, &result, "diagnóstico creado"); err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return &result, nil
 }
 
 // GetCase obtiene información básica de UN caso específico (HU7)
@@ -209,11 +353,13 @@ func (c *PreDiagnosticClient) CreateDiagnostic(prediagnosticID, aprobacion, come
 // Parámetros:
 //   - caseID: ID del caso a obtener
 //
-// Retorna: map[string]interface{} con datos del caso o error
-func (c *PreDiagnosticClient) GetCase(caseID string) (map[string]interface{}, error) {
+// Retorna: *CaseDTO con datos del caso o error
+func (c *PreDiagnosticClient) GetCase(ctx context.Context, caseID string) (*CaseDTO, error) {
 	url := fmt.Sprintf("%s/case/%s", c.BaseURL, caseID)
 
-	resp, err := http.Get(url)
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.QueryTimeout)
+	defer cancel()
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("error en petición HTTP para caso %s: %w", caseID, err)
 	}
@@ -229,17 +375,12 @@ func (c *PreDiagnosticClient) GetCase(caseID string) (map[string]interface{}, er
 		return nil, fmt.Errorf("error leyendo respuesta para caso %s: %w", caseID, err)
 	}
 
-	// Verificar si el body está vacío
-	if len(body) == 0 {
-		return nil, fmt.Errorf("respuesta vacía del servidor para caso %s", caseID)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error parseando JSON para caso %s: %w", caseID, err)
+	var result CaseDTO
+	if err := decodificarRespuesta(body, &result, "caso "+caseID); err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return &result, nil
 }
 
 // GetDiagnostic obtiene diagnóstico médico de un caso (HU7)
@@ -248,11 +389,13 @@ func (c *PreDiagnosticClient) GetCase(caseID string) (map[string]interface{}, er
 // Parámetros:
 //   - caseID: ID del caso para obtener diagnóstico
 //
-// Retorna: map[string]interface{} con diagnóstico médico o error
-func (c *PreDiagnosticClient) GetDiagnostic(caseID string) (map[string]interface{}, error) {
+// Retorna: *DiagnosticDTO con diagnóstico médico o error
+func (c *PreDiagnosticClient) GetDiagnostic(ctx context.Context, caseID string) (*DiagnosticDTO, error) {
 	url := fmt.Sprintf("%s/diagnostic/%s", c.BaseURL, caseID)
 
-	resp, err := http.Get(url)
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.QueryTimeout)
+	defer cancel()
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("error en petición HTTP para diagnóstico %s: %w", caseID, err)
 	}
@@ -260,7 +403,7 @@ func (c *PreDiagnosticClient) GetDiagnostic(caseID string) (map[string]interface
 
 	// 404 es válido - no todos los casos tienen diagnóstico
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("diagnóstico no encontrado para caso %s", caseID)
+		return nil, fmt.Errorf("%w para caso %s", ErrDiagnosticoNoEncontrado, caseID)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -272,14 +415,132 @@ func (c *PreDiagnosticClient) GetDiagnostic(caseID string) (map[string]interface
 		return nil, fmt.Errorf("error leyendo respuesta para diagnóstico %s: %w", caseID, err)
 	}
 
-	if len(body) == 0 {
-		return nil, fmt.Errorf("respuesta vacía del servidor para diagnóstico %s", caseID)
+	var result DiagnosticDTO
+	if err := decodificarRespuesta(body, &result, "diagnóstico "+caseID); err != nil {
+		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error parseando JSON para diagnóstico %s: %w", caseID, err)
+	return &result, nil
+}
+
+// GetImage descarga una radiografía almacenada por el servicio prediagnostic
+// Llamada REST: GET /prediagnostic/image/{filename}
+func (c *PreDiagnosticClient) GetImage(ctx context.Context, filename string) ([]byte, error) {
+	url := fmt.Sprintf("%s/prediagnostic/image/%s", c.BaseURL, filename)
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.ImageTimeout)
+	defer cancel()
+	resp, err := c.get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("error en petición HTTP para imagen %s: %w", filename, err)
 	}
+	defer resp.Body.Close()
 
-	return result, nil
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta HTTP %d para imagen %s: %s", resp.StatusCode, filename, resp.Status)
+	}
+
+	imagen, err := io.ReadAll(io.LimitReader(resp.Body, maxImagenBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo imagen %s: %w", filename, err)
+	}
+	if len(imagen) > maxImagenBytes {
+		return nil, fmt.Errorf("la imagen %s supera el tamaño máximo", filename)
+	}
+	return imagen, nil
+}
+
+// AnonymizeCasesByUserID pide al servicio prediagnostic que desvincule del
+// paciente sus casos, conservando las radiografías y los diagnósticos sin
+// datos que lo identifiquen
+// Llamada REST: POST /prediagnostic/cases/{userID}/anonymize
+func (c *PreDiagnosticClient) AnonymizeCasesByUserID(ctx context.Context, userID string) error {
+	url := fmt.Sprintf("%s/prediagnostic/cases/%s/anonymize", c.BaseURL, userID)
+	return c.modificarCasosUsuario(ctx, http.MethodPost, url, userID)
+}
+
+// DeleteCasesByUserID pide al servicio prediagnostic que borre los casos del
+// paciente, con sus radiografías y diagnósticos
+// Llamada REST: DELETE /prediagnostic/cases/{userID}
+func (c *PreDiagnosticClient) DeleteCasesByUserID(ctx context.Context, userID string) error {
+	url := fmt.Sprintf("%s/prediagnostic/cases/%s", c.BaseURL, userID)
+	return c.modificarCasosUsuario(ctx, http.MethodDelete, url, userID)
+}
+
+// modificarCasosUsuario envía la petición sin body. Un 404 con
+// DetalleSinCasos significa que el usuario no tiene casos y no es un error.
+func (c *PreDiagnosticClient) modificarCasosUsuario(ctx context.Context, method, url, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.WriteTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("error en petición HTTP para casos del usuario %s: %w", userID, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err != nil {
+			return fmt.Errorf("error leyendo respuesta para casos del usuario %s: %w", userID, err)
+		}
+		if detalleError(body) == DetalleSinCasos {
+			return nil
+		}
+	}
+	return fmt.Errorf("respuesta HTTP %d para casos del usuario %s: %s", resp.StatusCode, userID, resp.Status)
+}
+
+// ProcessImage envía una radiografía al servicio prediagnostic para su procesamiento
+// Llamada REST: POST /prediagnostic/process (multipart con user_id e imagen)
+func (c *PreDiagnosticClient) ProcessImage(ctx context.Context, userID, filename string, imagen io.Reader) (*ProcessResponseDTO, error) {
+	url := fmt.Sprintf("%s/prediagnostic/process", c.BaseURL)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("user_id", userID)
+	part, err := writer.CreateFormFile("imagen", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, imagen); err != nil {
+		return nil, err
+	}
+	writer.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.UploadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Error asociado a prediagnostic: %s", resp.Status)
+	}
+
+	respuesta, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var result ProcessResponseDTO
+	err = decodificarRespuesta(respuesta, &result, "procesamiento de la imagen")
+	if err != nil && !errors.Is(err, errRespuestaVacia) {
+		return nil, err
+	}
+	return &result, nil
 }
@@ -0,0 +1,167 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Respuestas del servicio prediagnostic. Los campos que el servicio puede
+// omitir o enviar en null son punteros; quien los usa decide si le son
+// obligatorios (ver los mapeos de internal/services).
+
+// CaseSummaryDTO es un caso de GET /prediagnostic/cases y
+// GET /prediagnostic/cases/{user_id}
+type CaseSummaryDTO struct {
+	PrediagnosticoID string `json:"prediagnostico_id,omitempty"`
+	// ID lo traen los registros anteriores a prediagnostico_id
+	ID              string   `json:"id,omitempty"`
+	UserID          *TextID  `json:"user_id,omitempty"`
+	PacienteNombre  *string  `json:"paciente_nombre,omitempty"`
+	Fecha           *string  `json:"fecha,omitempty"`
+	Estado          *string  `json:"estado,omitempty"`
+	RadiografiaRuta *string  `json:"radiografia_ruta,omitempty"`
+	DoctorAsignado  *string  `json:"doctor_asignado,omitempty"`
+	Probabilidad    *float64 `json:"probabilidad,omitempty"`
+	DiagnosticoIA   *string  `json:"diagnostico_ia,omitempty"`
+}
+
+// CaseID retorna prediagnostico_id o, en su defecto, id
+func (c CaseSummaryDTO) CaseID() string {
+	if c.PrediagnosticoID != "" {
+		return c.PrediagnosticoID
+	}
+	return c.ID
+}
+
+// caseListDTO es la respuesta de GET /prediagnostic/cases/{user_id}
+type caseListDTO struct {
+	Cases []CaseSummaryDTO `json:"cases"`
+}
+
+// CaseDTO es la respuesta de GET /prediagnostic/case/{id} y GET /case/{id}
+type CaseDTO struct {
+	PrediagnosticoID   string              `json:"prediagnostico_id"`
+	UserID             TextID              `json:"user_id"`
+	Estado             *string             `json:"estado,omitempty"`
+	FechaSubida        *string             `json:"fecha_subida,omitempty"`
+	FechaProcesamiento *string             `json:"fecha_procesamiento,omitempty"`
+	RadiografiaRuta    *string             `json:"radiografia_ruta,omitempty"`
+	ResultadoModelo    *ResultadoModeloDTO `json:"resultado_modelo,omitempty"`
+}
+
+// ResultadoModeloDTO es el resultado del modelo de IA dentro de CaseDTO
+type ResultadoModeloDTO struct {
+	ProbabilidadNeumonia *float64 `json:"probabilidad_neumonia,omitempty"`
+	Etiqueta             *string  `json:"etiqueta,omitempty"`
+}
+
+// DiagnosticDTO es la respuesta de GET /diagnostic/{case_id}
+type DiagnosticDTO struct {
+	ID string `json:"id,omitempty"`
+	// MongoID es el _id de MongoDB, que algunas versiones envían en lugar de id
+	MongoID         string  `json:"_id,omitempty"`
+	CaseID          string  `json:"case_id"`
+	Validacion      string  `json:"validacion"`
+	Diagnostico     string  `json:"diagnostico"`
+	FechaValidacion string  `json:"fecha_validacion"`
+	DoctorNombre    *string `json:"doctor_nombre,omitempty"`
+}
+
+// DiagnosticID retorna id o, en su defecto, _id
+func (d DiagnosticDTO) DiagnosticID() string {
+	if d.ID != "" {
+		return d.ID
+	}
+	return d.MongoID
+}
+
+// createDiagnosticRequestDTO es el body de POST /prediagnostic/diagnostic/{id}
+type createDiagnosticRequestDTO struct {
+	PrediagnosticID string `json:"prediagnostic_id"`
+	Aprobacion      bool   `json:"aprobacion"`
+	Comentario      string `json:"comentario"`
+	FechaRevision   string `json:"fecha_revision"`
+}
+
+// CreateDiagnosticResponseDTO es la respuesta de POST /prediagnostic/diagnostic/{id}
+type CreateDiagnosticResponseDTO struct {
+	Success      *bool   `json:"success,omitempty"`
+	Message      *string `json:"message,omitempty"`
+	DiagnosticID *string `json:"diagnostic_id,omitempty"`
+}
+
+// ProcessResponseDTO es la respuesta de POST /prediagnostic/process. El
+// servicio puede responder sin body; entonces todos los campos quedan en nil.
+type ProcessResponseDTO struct {
+	PrediagnosticoID *string `json:"prediagnostico_id,omitempty"`
+	Estado           *string `json:"estado,omitempty"`
+	Message          *string `json:"message,omitempty"`
+}
+
+// errorResponseDTO es el body de error de FastAPI; detail es un texto o una
+// lista de errores de validación
+type errorResponseDTO struct {
+	Detail json.RawMessage `json:"detail"`
+}
+
+// TextID es un identificador que el servicio envía como texto o como número
+// entero; se guarda siempre como texto
+type TextID string
+
+func (id *TextID) UnmarshalJSON(data []byte) error {
+	var texto string
+	if err := json.Unmarshal(data, &texto); err == nil {
+		*id = TextID(texto)
+		return nil
+	}
+	var numero int64
+	if err := json.Unmarshal(data, &numero); err != nil {
+		return fmt.Errorf("se esperaba un id de texto o entero, se recibió %s", data)
+	}
+	*id = TextID(strconv.FormatInt(numero, 10))
+	return nil
+}
+
+func (id TextID) String() string { return string(id) }
+
+// errRespuestaVacia indica que el servicio respondió sin body o con null
+var errRespuestaVacia = errors.New("respuesta vacía del servidor")
+
+// decodificarRespuesta decodifica body en destino. Un tipo distinto al
+// esperado, un campo que el DTO no declara, un body vacío o null, o datos
+// después del JSON son errores: una respuesta con otra forma indica que el
+// servicio cambió su contrato y es mejor fallar que leer datos a medias. Si
+// el servicio agrega un campo, hay que agregarlo también al DTO.
+func decodificarRespuesta(body []byte, destino any, recurso string) error {
+	contenido := bytes.TrimSpace(body)
+	if len(contenido) == 0 || bytes.Equal(contenido, []byte("null")) {
+		return fmt.Errorf("%s: %w", recurso, errRespuestaVacia)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(contenido))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(destino); err != nil {
+		return fmt.Errorf("error parseando JSON de %s: %w", recurso, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("error parseando JSON de %s: datos adicionales después del JSON", recurso)
+	}
+	return nil
+}
+
+// detalleError extrae el detail de una respuesta de error de FastAPI
+func detalleError(body []byte) string {
+	var respuesta errorResponseDTO
+	if err := json.Unmarshal(body, &respuesta); err != nil || len(respuesta.Detail) == 0 {
+		return ""
+	}
+	var texto string
+	if err := json.Unmarshal(respuesta.Detail, &texto); err == nil {
+		return texto
+	}
+	return string(respuesta.Detail)
+}
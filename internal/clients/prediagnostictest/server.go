@@ -0,0 +1,530 @@
+// Package prediagnostictest implementa un servicio prediagnostic falso en
+// memoria con las mismas rutas y formas de respuesta que el servicio Python
+// ({"cases": [...]}, resultado_modelo, errores {"detail": ...}), para probar
+// BusinessLogic de punta a punta sin el servicio real.
+package prediagnostictest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/config"
+)
+
+// Caso es un caso guardado por el servicio falso. Estado usa los valores del
+// servicio ("pending", "completed", "validado", ...).
+type Caso struct {
+	ID                 string
+	UserID             string
+	PacienteNombre     string
+	Estado             string
+	FechaSubida        time.Time
+	FechaProcesamiento time.Time
+	// Radiografia es el nombre del archivo; se publica como
+	// "storage\radiografias\<nombre>", igual que el servicio
+	Radiografia    string
+	ProbNeumonia   float64
+	Etiqueta       string
+	DoctorAsignado string
+}
+
+// Diagnostico es el diagnóstico médico de un caso
+type Diagnostico struct {
+	ID              string
+	CaseID          string
+	Validacion      string
+	Diagnostico     string
+	FechaValidacion time.Time
+	DoctorNombre    string
+}
+
+type respuestaGuardada struct {
+	status int
+	body   []byte
+}
+
+// Service es el servicio falso. Es seguro para uso concurrente.
+type Service struct {
+	mux *http.ServeMux
+
+	mu             sync.Mutex
+	casos          map[string]*Caso
+	diagnosticos   map[string]*Diagnostico
+	imagenes       map[string][]byte
+	idempotencia   map[string]respuestaGuardada
+	siguienteID    int
+	fallo          int
+	peticiones     map[string]int
+	ahora          func() time.Time
+	etiquetaModelo func(imagen []byte) (float64, string)
+}
+
+func NewService() *Service {
+	s := &Service{
+		casos:        map[string]*Caso{},
+		diagnosticos: map[string]*Diagnostico{},
+		imagenes:     map[string][]byte{},
+		idempotencia: map[string]respuestaGuardada{},
+		peticiones:   map[string]int{},
+		ahora:        time.Now,
+		etiquetaModelo: func([]byte) (float64, string) {
+			return 0.12, "Normal"
+		},
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /prediagnostic/cases", s.listarCasos)
+	s.mux.HandleFunc("GET /prediagnostic/cases/{user_id}", s.listarCasosUsuario)
+	s.mux.HandleFunc("POST /prediagnostic/cases/{user_id}/anonymize", s.anonimizarCasos)
+	s.mux.HandleFunc("DELETE /prediagnostic/cases/{user_id}", s.eliminarCasos)
+	s.mux.HandleFunc("GET /prediagnostic/case/{id}", s.obtenerCaso)
+	s.mux.HandleFunc("GET /case/{id}", s.obtenerCaso)
+	s.mux.HandleFunc("GET /diagnostic/{case_id}", s.obtenerDiagnostico)
+	s.mux.HandleFunc("POST /prediagnostic/diagnostic/{id}", s.crearDiagnostico)
+	s.mux.HandleFunc("POST /prediagnostic/process", s.procesarImagen)
+	s.mux.HandleFunc("GET /prediagnostic/image/{filename}", s.obtenerImagen)
+	return s
+}
+
+// ServeHTTP atiende una petición; si hay un fallo simulado responde con él
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.peticiones[r.Method+" "+r.URL.Path]++
+	fallo := s.fallo
+	s.mu.Unlock()
+
+	if fallo != 0 {
+		responderError(w, fallo, "fallo simulado")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// AgregarCaso guarda o reemplaza un caso. Sin ID se le asigna uno.
+func (s *Service) AgregarCaso(c Caso) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.ID == "" {
+		c.ID = s.nuevoID("RAD")
+	}
+	if c.FechaSubida.IsZero() {
+		c.FechaSubida = s.ahora()
+	}
+	s.casos[c.ID] = &c
+	return c.ID
+}
+
+// AgregarDiagnostico guarda el diagnóstico de un caso y marca el caso como
+// validado
+func (s *Service) AgregarDiagnostico(d Diagnostico) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d.ID == "" {
+		d.ID = s.nuevoID("DIAG")
+	}
+	if d.FechaValidacion.IsZero() {
+		d.FechaValidacion = s.ahora()
+	}
+	s.diagnosticos[d.CaseID] = &d
+	if c, ok := s.casos[d.CaseID]; ok {
+		c.Estado = "validado"
+	}
+}
+
+// AgregarImagen guarda el contenido de una radiografía
+func (s *Service) AgregarImagen(nombre string, contenido []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.imagenes[nombre] = slices.Clone(contenido)
+}
+
+// Caso retorna una copia del caso guardado
+func (s *Service) Caso(id string) (Caso, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.casos[id]
+	if !ok {
+		return Caso{}, false
+	}
+	return *c, true
+}
+
+// Diagnostico retorna una copia del diagnóstico del caso
+func (s *Service) Diagnostico(caseID string) (Diagnostico, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.diagnosticos[caseID]
+	if !ok {
+		return Diagnostico{}, false
+	}
+	return *d, true
+}
+
+// Fallar hace que todas las peticiones respondan con status (por ejemplo
+// 503 para simular un reinicio del servicio) hasta llamar a Restablecer
+func (s *Service) Fallar(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallo = status
+}
+
+func (s *Service) Restablecer() {
+	s.Fallar(0)
+}
+
+// Peticiones cuenta las peticiones recibidas con ese método y ruta, por
+// ejemplo "GET /prediagnostic/cases"
+func (s *Service) Peticiones(metodoYRuta string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peticiones[metodoYRuta]
+}
+
+// ClasificarCon reemplaza el resultado que el "modelo" asigna a las
+// radiografías enviadas a /prediagnostic/process
+func (s *Service) ClasificarCon(f func(imagen []byte) (probNeumonia float64, etiqueta string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etiquetaModelo = f
+}
+
+func (s *Service) nuevoID(prefijo string) string {
+	s.siguienteID++
+	return fmt.Sprintf("%s-%06d", prefijo, s.siguienteID)
+}
+
+func (s *Service) listarCasos(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Este endpoint responde el arreglo directamente
+	responderJSON(w, http.StatusOK, s.resumenes(""))
+}
+
+func (s *Service) listarCasosUsuario(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	casos := s.resumenes(r.PathValue("user_id"))
+	if len(casos) == 0 {
+		responderError(w, http.StatusNotFound, clients.DetalleSinCasos)
+		return
+	}
+	responderJSON(w, http.StatusOK, map[string]interface{}{"cases": casos})
+}
+
+// resumenes arma el formato de los listados, los más recientes primero
+func (s *Service) resumenes(userID string) []clients.CaseSummaryDTO {
+	casos := make([]*Caso, 0, len(s.casos))
+	for _, c := range s.casos {
+		if userID == "" || c.UserID == userID {
+			casos = append(casos, c)
+		}
+	}
+	slices.SortFunc(casos, func(a, b *Caso) int {
+		if c := b.FechaSubida.Compare(a.FechaSubida); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	resumenes := make([]clients.CaseSummaryDTO, 0, len(casos))
+	for _, c := range casos {
+		resumen := clients.CaseSummaryDTO{
+			PrediagnosticoID: c.ID,
+			Fecha:            texto(c.FechaSubida.UTC().Format(time.RFC3339)),
+			Estado:           texto(c.Estado),
+			RadiografiaRuta:  rutaRadiografia(c.Radiografia),
+			PacienteNombre:   textoOpcional(c.PacienteNombre),
+			DoctorAsignado:   textoOpcional(c.DoctorAsignado),
+		}
+		if c.UserID != "" {
+			id := clients.TextID(c.UserID)
+			resumen.UserID = &id
+		}
+		if c.Etiqueta != "" {
+			resumen.Probabilidad = &c.ProbNeumonia
+			resumen.DiagnosticoIA = texto(c.Etiqueta)
+		}
+		resumenes = append(resumenes, resumen)
+	}
+	return resumenes
+}
+
+func (s *Service) obtenerCaso(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.casos[r.PathValue("id")]
+	if !ok {
+		responderError(w, http.StatusNotFound, "Caso no encontrado")
+		return
+	}
+
+	caso := clients.CaseDTO{
+		PrediagnosticoID: c.ID,
+		UserID:           clients.TextID(c.UserID),
+		Estado:           texto(c.Estado),
+		FechaSubida:      texto(c.FechaSubida.UTC().Format(time.RFC3339)),
+		RadiografiaRuta:  rutaRadiografia(c.Radiografia),
+	}
+	if c.Etiqueta != "" {
+		caso.ResultadoModelo = &clients.ResultadoModeloDTO{
+			ProbabilidadNeumonia: &c.ProbNeumonia,
+			Etiqueta:             texto(c.Etiqueta),
+		}
+	}
+	if !c.FechaProcesamiento.IsZero() {
+		caso.FechaProcesamiento = texto(c.FechaProcesamiento.UTC().Format(time.RFC3339))
+	}
+	responderJSON(w, http.StatusOK, caso)
+}
+
+func (s *Service) obtenerDiagnostico(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.diagnosticos[r.PathValue("case_id")]
+	if !ok {
+		responderError(w, http.StatusNotFound, "Diagnóstico no encontrado")
+		return
+	}
+	responderJSON(w, http.StatusOK, clients.DiagnosticDTO{
+		MongoID:         d.ID,
+		CaseID:          d.CaseID,
+		Validacion:      d.Validacion,
+		Diagnostico:     d.Diagnostico,
+		FechaValidacion: d.FechaValidacion.UTC().Format(time.RFC3339),
+		DoctorNombre:    textoOpcional(d.DoctorNombre),
+	})
+}
+
+// crearDiagnostico registra el diagnóstico. Una petición repetida con el
+// mismo Idempotency-Key recibe la respuesta de la primera.
+func (s *Service) crearDiagnostico(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clave := r.Header.Get("Idempotency-Key")
+	if guardada, ok := s.idempotencia[clave]; ok && clave != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(guardada.status)
+		w.Write(guardada.body)
+		return
+	}
+
+	status, respuesta := s.registrarDiagnostico(r)
+	body, _ := json.Marshal(respuesta)
+	if clave != "" {
+		s.idempotencia[clave] = respuestaGuardada{status: status, body: body}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (s *Service) registrarDiagnostico(r *http.Request) (int, interface{}) {
+	id := r.PathValue("id")
+	caso, ok := s.casos[id]
+	if !ok {
+		return http.StatusNotFound, map[string]interface{}{"detail": "Caso no encontrado"}
+	}
+
+	var datos struct {
+		Aprobacion *bool  `json:"aprobacion"`
+		Comentario string `json:"comentario"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || datos.Aprobacion == nil {
+		return http.StatusUnprocessableEntity, map[string]interface{}{
+			"detail": []map[string]interface{}{{"loc": []string{"body", "aprobacion"}, "msg": "field required"}},
+		}
+	}
+
+	validacion := "No"
+	if *datos.Aprobacion {
+		validacion = "Si"
+	}
+	d := &Diagnostico{
+		ID:              s.nuevoID("DIAG"),
+		CaseID:          id,
+		Validacion:      validacion,
+		Diagnostico:     datos.Comentario,
+		FechaValidacion: s.ahora(),
+	}
+	s.diagnosticos[id] = d
+	caso.Estado = "validado"
+
+	exito := true
+	return http.StatusCreated, clients.CreateDiagnosticResponseDTO{
+		Success:      &exito,
+		Message:      texto("Diagnostic created successfully"),
+		DiagnosticID: texto(d.ID),
+	}
+}
+
+// procesarImagen guarda la radiografía y crea un caso ya procesado con el
+// resultado de ClasificarCon
+func (s *Service) procesarImagen(w http.ResponseWriter, r *http.Request) {
+	userID := r.FormValue("user_id")
+	archivo, cabecera, err := r.FormFile("imagen")
+	if err != nil || userID == "" {
+		responderError(w, http.StatusUnprocessableEntity, "user_id e imagen son requeridos")
+		return
+	}
+	defer archivo.Close()
+	contenido, err := io.ReadAll(archivo)
+	if err != nil {
+		responderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nuevoID("RAD")
+	nombre := id + "_" + cabecera.Filename
+	prob, etiqueta := s.etiquetaModelo(contenido)
+	ahora := s.ahora()
+	s.imagenes[nombre] = contenido
+	s.casos[id] = &Caso{
+		ID:                 id,
+		UserID:             userID,
+		Estado:             "completed",
+		FechaSubida:        ahora,
+		FechaProcesamiento: ahora,
+		Radiografia:        nombre,
+		ProbNeumonia:       prob,
+		Etiqueta:           etiqueta,
+	}
+	responderJSON(w, http.StatusOK, clients.ProcessResponseDTO{
+		PrediagnosticoID: texto(id),
+		Estado:           texto("completed"),
+		Message:          texto("Imagen procesada"),
+	})
+}
+
+func (s *Service) obtenerImagen(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	imagen, ok := s.imagenes[r.PathValue("filename")]
+	s.mu.Unlock()
+
+	if !ok {
+		responderError(w, http.StatusNotFound, "Imagen no encontrada")
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Write(imagen)
+}
+
+func (s *Service) anonimizarCasos(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, c := range s.casos {
+		if c.UserID == r.PathValue("user_id") {
+			c.UserID = ""
+			c.PacienteNombre = ""
+			n++
+		}
+	}
+	s.responderModificados(w, n)
+}
+
+func (s *Service) eliminarCasos(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, c := range s.casos {
+		if c.UserID == r.PathValue("user_id") {
+			delete(s.casos, id)
+			delete(s.diagnosticos, id)
+			delete(s.imagenes, c.Radiografia)
+			n++
+		}
+	}
+	s.responderModificados(w, n)
+}
+
+func (s *Service) responderModificados(w http.ResponseWriter, n int) {
+	if n == 0 {
+		responderError(w, http.StatusNotFound, clients.DetalleSinCasos)
+		return
+	}
+	responderJSON(w, http.StatusOK, map[string]interface{}{"casos": n})
+}
+
+// Server es Service escuchando en una URL local de httptest
+type Server struct {
+	*httptest.Server
+	Service *Service
+}
+
+// NewServer inicia un Server; se debe cerrar con Close
+func NewServer() *Server {
+	service := NewService()
+	return &Server{Server: httptest.NewServer(service), Service: service}
+}
+
+// Config retorna una configuración de prediagnostic que apunta al servidor,
+// con timeouts y esperas entre reintentos cortos
+func (s *Server) Config() config.PrediagnosticConfig {
+	return config.PrediagnosticConfig{
+		URL:                 s.URL,
+		PublicURL:           s.URL,
+		QueryTimeout:        5 * time.Second,
+		WriteTimeout:        5 * time.Second,
+		UploadTimeout:       5 * time.Second,
+		ImageTimeout:        5 * time.Second,
+		MaxRetries:          2,
+		RetryBackoffBase:    time.Millisecond,
+		RetryBackoffMax:     10 * time.Millisecond,
+		BreakerFailures:     5,
+		BreakerOpenDuration: time.Second,
+	}
+}
+
+// PrediagnosticClient retorna un cliente real configurado con Config
+func (s *Server) PrediagnosticClient() *clients.PreDiagnosticClient {
+	return clients.NewPrediagnosticClient(s.Config())
+}
+
+func responderJSON(w http.ResponseWriter, status int, datos interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(datos)
+}
+
+// responderError responde como FastAPI: {"detail": mensaje}
+func responderError(w http.ResponseWriter, status int, mensaje string) {
+	responderJSON(w, status, map[string]interface{}{"detail": mensaje})
+}
+
+func rutaRadiografia(nombre string) *string {
+	if nombre == "" {
+		return nil
+	}
+	return texto(`storage\radiografias\` + nombre)
+}
+
+func texto(s string) *string { return &s }
+
+func textoOpcional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -1 +1,772 @@
 package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config agrupa toda la configuración del microservicio BusinessLogic.
+// Se construye a partir de valores por defecto, un archivo YAML opcional
+// y variables de entorno (en ese orden de prioridad creciente).
+type Config struct {
+	Server        ServerConfig        `yaml:"server"`
+	Database      DatabaseConfig      `yaml:"database"`
+	JWT           JWTConfig           `yaml:"jwt"`
+	Prediagnostic PrediagnosticConfig `yaml:"prediagnostic"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Mail          MailConfig          `yaml:"mail"`
+	Frontend      FrontendConfig      `yaml:"frontend"`
+	Privacy       PrivacyConfig       `yaml:"privacy"`
+}
+
+// ServerConfig contiene la configuración del servidor HTTP
+type ServerConfig struct {
+	Port string `yaml:"port"`
+	// TrustForwardedFor toma la IP del cliente de la última dirección de
+	// X-Forwarded-For, la que agrega el proxy; activarlo solo detrás de un
+	// único proxy de confianza
+	TrustForwardedFor bool `yaml:"trust_forwarded_for"`
+}
+
+// DatabaseConfig contiene los datos de conexión a PostgreSQL y los parámetros
+// del pool de conexiones. Si URL está definida tiene prioridad sobre los
+// campos individuales.
+type DatabaseConfig struct {
+	URL      string `yaml:"url"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	// AutoMigrate aplica las migraciones pendientes al arrancar el servidor
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+// JWTConfig contiene la configuración para firmar y validar tokens.
+// TokenTTL es la duración del access token (JWT); RefreshTokenTTL la del
+// refresh token opaco que permite renovarlo.
+type JWTConfig struct {
+	// Algorithm (RS256 o ES256) se usa al generar claves nuevas; las claves
+	// existentes firman con el algoritmo que corresponde a su tipo.
+	Algorithm string   `yaml:"algorithm"`
+	KeysDir   string   `yaml:"keys_dir"`
+	Issuer    string   `yaml:"issuer"`
+	Audience  []string `yaml:"audience"`
+
+	// KeyRotationInterval es cada cuánto se genera una clave nueva (0 desactiva
+	// la rotación automática y las claves se administran a mano en KeysDir).
+	// KeyReloadInterval es cada cuánto se relee KeysDir.
+	KeyRotationInterval time.Duration `yaml:"key_rotation_interval"`
+	KeyReloadInterval   time.Duration `yaml:"key_reload_interval"`
+
+	TokenTTL        time.Duration `yaml:"token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+
+	// RevocationCacheTTL es cuánto tiempo se cachea en proceso el resultado de
+	// consultar la lista de revocación (retraso máximo entre réplicas).
+	RevocationCacheTTL time.Duration `yaml:"revocation_cache_ttl"`
+}
+
+// PrediagnosticConfig contiene la configuración del servicio de prediagnóstico.
+// PublicURL es la URL con la que el navegador accede a las imágenes; si no se
+// define se usa URL. Los timeouts limitan cada llamada al servicio según la
+// operación; al vencer se cancela la petición.
+type PrediagnosticConfig struct {
+	URL       string `yaml:"url"`
+	PublicURL string `yaml:"public_url"`
+	// Consultas de casos, prediagnósticos y diagnósticos
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	// Registro de diagnósticos y anonimización o eliminación de casos
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// Envío de una radiografía a procesar
+	UploadTimeout time.Duration `yaml:"upload_timeout"`
+	// Descarga de una radiografía
+	ImageTimeout time.Duration `yaml:"image_timeout"`
+
+	// Las consultas (GET) que fallan por conexión o con un 5xx se reintentan
+	// hasta MaxRetries veces, esperando un tiempo aleatorio entre cero y
+	// RetryBackoffBase·2^intento, como máximo RetryBackoffMax
+	MaxRetries       int           `yaml:"max_retries"`
+	RetryBackoffBase time.Duration `yaml:"retry_backoff_base"`
+	RetryBackoffMax  time.Duration `yaml:"retry_backoff_max"`
+	// Después de BreakerFailures fallos seguidos se deja de llamar al servicio
+	// durante BreakerOpenDuration
+	BreakerFailures     int           `yaml:"breaker_failures"`
+	BreakerOpenDuration time.Duration `yaml:"breaker_open_duration"`
+}
+
+// AuthConfig contiene las políticas de los flujos de cuenta (recuperación de
+// contraseña, verificación de correo, etc.)
+type AuthConfig struct {
+	PasswordResetTTL     time.Duration `yaml:"password_reset_ttl"`
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl"`
+	// Tiempo mínimo entre dos correos de restablecimiento a una cuenta
+	PasswordResetInterval time.Duration `yaml:"password_reset_interval"`
+	// Tiempo mínimo entre dos reenvíos del correo de verificación a una cuenta
+	VerificationResendInterval time.Duration   `yaml:"verification_resend_interval"`
+	Lockout                    LockoutConfig   `yaml:"lockout"`
+	TwoFactor                  TwoFactorConfig `yaml:"two_factor"`
+	// Permissions asigna a cada rol sus permisos ("recurso:accion" o
+	// "recurso:accion:own|any"). Un rol definido en el archivo reemplaza sus
+	// permisos por defecto; los demás roles los conservan.
+	Permissions map[string][]string `yaml:"permissions"`
+}
+
+// TwoFactorConfig define el segundo factor TOTP
+type TwoFactorConfig struct {
+	// Issuer es el nombre de la cuenta que muestran las apps autenticadoras
+	Issuer string `yaml:"issuer"`
+	// RequiredRoles son los roles que no pueden iniciar sesión sin 2FA
+	RequiredRoles []string `yaml:"required_roles"`
+	// ChallengeTTL es cuánto dura el desafío entre la contraseña y el código
+	ChallengeTTL time.Duration `yaml:"challenge_ttl"`
+}
+
+// LockoutConfig define la protección contra fuerza bruta en el login.
+// Desde el segundo fallo seguido cada clave (cuenta o IP) espera BackoffBase,
+// duplicándose en cada fallo hasta BackoffMax; al llegar al máximo de fallos
+// la clave queda bloqueada LockoutDuration. Los fallos se olvidan tras
+// FailureWindow sin intentos fallidos.
+type LockoutConfig struct {
+	// Store: "postgres" (compartido entre réplicas) o "memory" (una sola instancia)
+	Store                  string        `yaml:"store"`
+	MaxFailedAttempts      int           `yaml:"max_failed_attempts"`
+	MaxFailedAttemptsPerIP int           `yaml:"max_failed_attempts_per_ip"`
+	BackoffBase            time.Duration `yaml:"backoff_base"`
+	BackoffMax             time.Duration `yaml:"backoff_max"`
+	LockoutDuration        time.Duration `yaml:"lockout_duration"`
+	FailureWindow          time.Duration `yaml:"failure_window"`
+}
+
+// MailConfig define cómo se envían los correos transaccionales.
+// Driver: "smtp", "log" (escribe el correo en el log) o "file" (un .eml por
+// correo en Dir), estos dos últimos pensados para desarrollo local.
+type MailConfig struct {
+	Driver string     `yaml:"driver"`
+	From   string     `yaml:"from"`
+	Dir    string     `yaml:"dir"`
+	SMTP   SMTPConfig `yaml:"smtp"`
+}
+
+// SMTPConfig contiene los datos del servidor SMTP (se usa STARTTLS si el servidor lo ofrece)
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// FrontendConfig contiene la URL pública del frontend, usada para armar los
+// enlaces que se envían por correo.
+type FrontendConfig struct {
+	URL string `yaml:"url"`
+}
+
+// PrivacyConfig contiene la configuración de los derechos de habeas data
+type PrivacyConfig struct {
+	// ExportTTL es el tiempo que un ZIP de datos personales queda disponible
+	// para descarga después de generarse
+	ExportTTL time.Duration `yaml:"export_ttl"`
+	// ExportTimeout limita la generación de una exportación; las que no
+	// terminan en ese tiempo se marcan como fallidas
+	ExportTimeout time.Duration `yaml:"export_timeout"`
+	// ExportMaxBytes limita el tamaño del ZIP; las exportaciones que lo
+	// superan se marcan como fallidas
+	ExportMaxBytes int `yaml:"export_max_bytes"`
+	// DeletionGracePeriod es el tiempo entre la solicitud de eliminación de la
+	// cuenta (o el retiro del consentimiento) y la anonimización, durante el
+	// cual el usuario puede cancelarla
+	DeletionGracePeriod time.Duration `yaml:"deletion_grace_period"`
+	// CaseRetention indica qué se hace con los casos del paciente en el
+	// servicio prediagnostic al anonimizar la cuenta: anonymize o delete
+	CaseRetention string `yaml:"case_retention"`
+}
+
+// Default retorna la configuración por defecto para desarrollo local.
+// No incluye credenciales: la contraseña de la base de datos siempre debe
+// venir del archivo o del entorno, y las claves JWT del directorio de claves.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port: "8080",
+		},
+		Database: DatabaseConfig{
+			Host:    "localhost",
+			Port:    5432,
+			User:    "postgres",
+			Name:    "blogic_db",
+			SSLMode: "disable",
+
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		JWT: JWTConfig{
+			Algorithm: "RS256",
+			KeysDir:   "keys",
+			Issuer:    "businesslogic",
+			Audience:  []string{"businesslogic"},
+
+			KeyRotationInterval: 30 * 24 * time.Hour,
+			KeyReloadInterval:   time.Minute,
+
+			TokenTTL:        15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+
+			RevocationCacheTTL: 30 * time.Second,
+		},
+		Prediagnostic: PrediagnosticConfig{
+			URL:           "http://localhost:8000",
+			QueryTimeout:  10 * time.Second,
+			WriteTimeout:  15 * time.Second,
+			UploadTimeout: 60 * time.Second,
+			ImageTimeout:  30 * time.Second,
+
+			MaxRetries:       2,
+			RetryBackoffBase: 100 * time.Millisecond,
+			RetryBackoffMax:  time.Second,
+
+			BreakerFailures:     5,
+			BreakerOpenDuration: 30 * time.Second,
+		},
+		Auth: AuthConfig{
+			PasswordResetTTL:           time.Hour,
+			PasswordResetInterval:      time.Minute,
+			EmailVerificationTTL:       48 * time.Hour,
+			VerificationResendInterval: time.Minute,
+			Lockout: LockoutConfig{
+				Store:                  "postgres",
+				MaxFailedAttempts:      5,
+				MaxFailedAttemptsPerIP: 20,
+				BackoffBase:            time.Second,
+				BackoffMax:             30 * time.Second,
+				LockoutDuration:        15 * time.Minute,
+				FailureWindow:          time.Hour,
+			},
+			TwoFactor: TwoFactorConfig{
+				Issuer:        "BusinessLogic",
+				RequiredRoles: []string{"doctor"},
+				ChallengeTTL:  5 * time.Minute,
+			},
+			Permissions: map[string][]string{
+				"paciente": {"case:read:own", "case:create", "prediagnostic:read:own"},
+				"doctor":   {"case:read:any", "prediagnostic:read:any", "diagnostic:create"},
+				"admin":    {"user:admin"},
+			},
+		},
+		Mail: MailConfig{
+			Driver: "log",
+			From:   "no-reply@localhost",
+			Dir:    "mail",
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+		Frontend: FrontendConfig{
+			URL: "http://localhost:3000",
+		},
+		Privacy: PrivacyConfig{
+			ExportTTL:           24 * time.Hour,
+			ExportTimeout:       10 * time.Minute,
+			ExportMaxBytes:      200 << 20,
+			DeletionGracePeriod: 30 * 24 * time.Hour,
+			CaseRetention:       "anonymize",
+		},
+	}
+}
+
+// Load construye la configuración. Si path está vacío se usa la variable
+// CONFIG_FILE; si tampoco existe, solo se aplican defaults y entorno.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.Prediagnostic.PublicURL == "" {
+		cfg.Prediagnostic.PublicURL = cfg.Prediagnostic.URL
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error leyendo archivo de configuración %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("error parseando archivo de configuración %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	setString(&c.Server.Port, "PORT")
+	if err := setBool(&c.Server.TrustForwardedFor, "TRUST_X_FORWARDED_FOR"); err != nil {
+		return err
+	}
+
+	setString(&c.Database.URL, "DATABASE_URL")
+	setString(&c.Database.Host, "DB_HOST")
+	setString(&c.Database.User, "DB_USER")
+	setString(&c.Database.Password, "DB_PASSWORD")
+	setString(&c.Database.Name, "DB_NAME")
+	setString(&c.Database.SSLMode, "DB_SSLMODE")
+	if err := setInt(&c.Database.Port, "DB_PORT"); err != nil {
+		return err
+	}
+	if err := setInt(&c.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS"); err != nil {
+		return err
+	}
+	if err := setInt(&c.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"); err != nil {
+		return err
+	}
+	if err := setBool(&c.Database.AutoMigrate, "DB_AUTO_MIGRATE"); err != nil {
+		return err
+	}
+
+	setString(&c.JWT.Algorithm, "JWT_ALGORITHM")
+	setString(&c.JWT.KeysDir, "JWT_KEYS_DIR")
+	setString(&c.JWT.Issuer, "JWT_ISSUER")
+	setStringList(&c.JWT.Audience, "JWT_AUDIENCE")
+	if err := setDuration(&c.JWT.KeyRotationInterval, "JWT_KEY_ROTATION_INTERVAL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.JWT.KeyReloadInterval, "JWT_KEY_RELOAD_INTERVAL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.JWT.TokenTTL, "JWT_TOKEN_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.JWT.RefreshTokenTTL, "JWT_REFRESH_TOKEN_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.JWT.RevocationCacheTTL, "JWT_REVOCATION_CACHE_TTL"); err != nil {
+		return err
+	}
+
+	setString(&c.Prediagnostic.URL, "PREDIAGNOSTIC_SERVICE_URL")
+	setString(&c.Prediagnostic.PublicURL, "PREDIAGNOSTIC_PUBLIC_URL")
+	if err := setDuration(&c.Prediagnostic.QueryTimeout, "PREDIAGNOSTIC_QUERY_TIMEOUT"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Prediagnostic.WriteTimeout, "PREDIAGNOSTIC_WRITE_TIMEOUT"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Prediagnostic.UploadTimeout, "PREDIAGNOSTIC_UPLOAD_TIMEOUT"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Prediagnostic.ImageTimeout, "PREDIAGNOSTIC_IMAGE_TIMEOUT"); err != nil {
+		return err
+	}
+	if err := setInt(&c.Prediagnostic.MaxRetries, "PREDIAGNOSTIC_MAX_RETRIES"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Prediagnostic.RetryBackoffBase, "PREDIAGNOSTIC_RETRY_BACKOFF_BASE"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Prediagnostic.RetryBackoffMax, "PREDIAGNOSTIC_RETRY_BACKOFF_MAX"); err != nil {
+		return err
+	}
+	if err := setInt(&c.Prediagnostic.BreakerFailures, "PREDIAGNOSTIC_BREAKER_FAILURES"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Prediagnostic.BreakerOpenDuration, "PREDIAGNOSTIC_BREAKER_OPEN_DURATION"); err != nil {
+		return err
+	}
+
+	if err := setDuration(&c.Auth.PasswordResetTTL, "PASSWORD_RESET_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Auth.PasswordResetInterval, "PASSWORD_RESET_INTERVAL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Auth.EmailVerificationTTL, "EMAIL_VERIFICATION_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Auth.VerificationResendInterval, "EMAIL_VERIFICATION_RESEND_INTERVAL"); err != nil {
+		return err
+	}
+	setString(&c.Auth.Lockout.Store, "LOGIN_ATTEMPTS_STORE")
+	if err := setInt(&c.Auth.Lockout.MaxFailedAttempts, "LOGIN_MAX_FAILED_ATTEMPTS"); err != nil {
+		return err
+	}
+	if err := setInt(&c.Auth.Lockout.MaxFailedAttemptsPerIP, "LOGIN_MAX_FAILED_ATTEMPTS_PER_IP"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Auth.Lockout.BackoffBase, "LOGIN_BACKOFF_BASE"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Auth.Lockout.BackoffMax, "LOGIN_BACKOFF_MAX"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Auth.Lockout.LockoutDuration, "LOGIN_LOCKOUT_DURATION"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Auth.Lockout.FailureWindow, "LOGIN_FAILURE_WINDOW"); err != nil {
+		return err
+	}
+	setString(&c.Auth.TwoFactor.Issuer, "TWO_FACTOR_ISSUER")
+	// Definida pero vacía desactiva la obligatoriedad, a diferencia de otras listas
+	if v, ok := os.LookupEnv("TWO_FACTOR_REQUIRED_ROLES"); ok && strings.TrimSpace(v) == "" {
+		c.Auth.TwoFactor.RequiredRoles = nil
+	} else {
+		setStringList(&c.Auth.TwoFactor.RequiredRoles, "TWO_FACTOR_REQUIRED_ROLES")
+	}
+	if err := setDuration(&c.Auth.TwoFactor.ChallengeTTL, "TWO_FACTOR_CHALLENGE_TTL"); err != nil {
+		return err
+	}
+
+	setString(&c.Mail.Driver, "MAIL_DRIVER")
+	setString(&c.Mail.From, "MAIL_FROM")
+	setString(&c.Mail.Dir, "MAIL_DIR")
+	setString(&c.Mail.SMTP.Host, "SMTP_HOST")
+	setString(&c.Mail.SMTP.Username, "SMTP_USERNAME")
+	setString(&c.Mail.SMTP.Password, "SMTP_PASSWORD")
+	if err := setInt(&c.Mail.SMTP.Port, "SMTP_PORT"); err != nil {
+		return err
+	}
+
+	setString(&c.Frontend.URL, "FRONTEND_URL")
+
+	if err := setDuration(&c.Privacy.ExportTTL, "DATA_EXPORT_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Privacy.ExportTimeout, "DATA_EXPORT_TIMEOUT"); err != nil {
+		return err
+	}
+	if err := setInt(&c.Privacy.ExportMaxBytes, "DATA_EXPORT_MAX_BYTES"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.Privacy.DeletionGracePeriod, "ACCOUNT_DELETION_GRACE_PERIOD"); err != nil {
+		return err
+	}
+	setString(&c.Privacy.CaseRetention, "CASE_RETENTION")
+
+	return nil
+}
+
+// Validate verifica que la configuración tenga todo lo necesario para arrancar
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port == "" {
+		errs = append(errs, errors.New("server.port es requerido"))
+	}
+
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.JWT.Algorithm != "RS256" && c.JWT.Algorithm != "ES256" {
+		errs = append(errs, fmt.Errorf("jwt.algorithm debe ser RS256 o ES256, no %q", c.JWT.Algorithm))
+	}
+	if c.JWT.KeysDir == "" {
+		errs = append(errs, errors.New("jwt.keys_dir es requerido (JWT_KEYS_DIR)"))
+	}
+	if c.JWT.Issuer == "" {
+		errs = append(errs, errors.New("jwt.issuer es requerido"))
+	}
+	if len(c.JWT.Audience) == 0 {
+		errs = append(errs, errors.New("jwt.audience requiere al menos un valor"))
+	}
+	if c.JWT.KeyRotationInterval < 0 || c.JWT.KeyReloadInterval < 0 {
+		errs = append(errs, errors.New("jwt: key_rotation_interval y key_reload_interval no pueden ser negativos"))
+	}
+	if c.JWT.KeyRotationInterval > 0 && c.JWT.KeyRotationInterval <= c.JWT.TokenTTL {
+		errs = append(errs, errors.New("jwt.key_rotation_interval debe ser mayor que jwt.token_ttl"))
+	}
+	if c.JWT.TokenTTL <= 0 {
+		errs = append(errs, errors.New("jwt.token_ttl debe ser mayor que cero"))
+	}
+	if c.JWT.RefreshTokenTTL <= c.JWT.TokenTTL {
+		errs = append(errs, errors.New("jwt.refresh_token_ttl debe ser mayor que jwt.token_ttl"))
+	}
+	if c.JWT.RevocationCacheTTL < 0 {
+		errs = append(errs, errors.New("jwt.revocation_cache_ttl no puede ser negativo"))
+	}
+
+	if err := validateURL("prediagnostic.url", c.Prediagnostic.URL); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateURL("prediagnostic.public_url", c.Prediagnostic.PublicURL); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Prediagnostic.QueryTimeout <= 0 || c.Prediagnostic.WriteTimeout <= 0 ||
+		c.Prediagnostic.UploadTimeout <= 0 || c.Prediagnostic.ImageTimeout <= 0 {
+		errs = append(errs, errors.New("los timeouts de prediagnostic deben ser mayores que cero"))
+	}
+	if c.Prediagnostic.MaxRetries < 0 {
+		errs = append(errs, errors.New("prediagnostic.max_retries no puede ser negativo"))
+	}
+	if c.Prediagnostic.RetryBackoffBase <= 0 || c.Prediagnostic.RetryBackoffMax < c.Prediagnostic.RetryBackoffBase {
+		errs = append(errs, errors.New("prediagnostic.retry_backoff_base debe ser mayor que cero y no mayor que prediagnostic.retry_backoff_max"))
+	}
+	if c.Prediagnostic.BreakerFailures <= 0 || c.Prediagnostic.BreakerOpenDuration <= 0 {
+		errs = append(errs, errors.New("prediagnostic.breaker_failures y prediagnostic.breaker_open_duration deben ser mayores que cero"))
+	}
+
+	if c.Auth.PasswordResetTTL <= 0 {
+		errs = append(errs, errors.New("auth.password_reset_ttl debe ser mayor que cero"))
+	}
+	if c.Auth.PasswordResetInterval < 0 {
+		errs = append(errs, errors.New("auth.password_reset_interval no puede ser negativo"))
+	}
+	if c.Auth.EmailVerificationTTL <= 0 {
+		errs = append(errs, errors.New("auth.email_verification_ttl debe ser mayor que cero"))
+	}
+	if c.Auth.VerificationResendInterval < 0 {
+		errs = append(errs, errors.New("auth.verification_resend_interval no puede ser negativo"))
+	}
+	if err := c.Auth.Lockout.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Auth.TwoFactor.Issuer == "" || strings.Contains(c.Auth.TwoFactor.Issuer, ":") {
+		errs = append(errs, errors.New("auth.two_factor.issuer es requerido y no puede contener ':'"))
+	}
+	if c.Auth.TwoFactor.ChallengeTTL <= 0 {
+		errs = append(errs, errors.New("auth.two_factor.challenge_ttl debe ser mayor que cero"))
+	}
+	if err := validatePermissions(c.Auth.Permissions); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.Mail.Driver {
+	case "smtp":
+		if c.Mail.SMTP.Host == "" || c.Mail.SMTP.Port <= 0 {
+			errs = append(errs, errors.New("mail.smtp: host y port son requeridos con driver smtp"))
+		}
+	case "file":
+		if c.Mail.Dir == "" {
+			errs = append(errs, errors.New("mail.dir es requerido con driver file"))
+		}
+	case "log":
+	default:
+		errs = append(errs, fmt.Errorf("mail.driver debe ser smtp, log o file, no %q", c.Mail.Driver))
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, errors.New("mail.from es requerido"))
+	}
+
+	if err := validateURL("frontend.url", c.Frontend.URL); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Privacy.ExportTTL <= 0 {
+		errs = append(errs, errors.New("privacy.export_ttl debe ser mayor que cero"))
+	}
+	if c.Privacy.ExportTimeout <= 0 {
+		errs = append(errs, errors.New("privacy.export_timeout debe ser mayor que cero"))
+	}
+	if c.Privacy.ExportMaxBytes <= 0 {
+		errs = append(errs, errors.New("privacy.export_max_bytes debe ser mayor que cero"))
+	}
+	if c.Privacy.DeletionGracePeriod < 0 {
+		errs = append(errs, errors.New("privacy.deletion_grace_period no puede ser negativo"))
+	}
+	if c.Privacy.CaseRetention != "anonymize" && c.Privacy.CaseRetention != "delete" {
+		errs = append(errs, fmt.Errorf("privacy.case_retention debe ser anonymize o delete, no %q", c.Privacy.CaseRetention))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("configuración inválida: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validatePermissions verifica el formato de auth.permissions: recurso:accion
+// con un alcance opcional own o any
+func validatePermissions(permisos map[string][]string) error {
+	var errs []error
+	for rol, lista := range permisos {
+		for _, permiso := range lista {
+			partes := strings.Split(permiso, ":")
+			valido := (len(partes) == 2 || len(partes) == 3) && !slices.Contains(partes, "")
+			if len(partes) == 3 && partes[2] != "own" && partes[2] != "any" {
+				valido = false
+			}
+			if !valido {
+				errs = append(errs, fmt.Errorf("auth.permissions.%s: permiso %q inválido, se espera recurso:accion[:own|any]", rol, permiso))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate verifica solo la sección de base de datos; la usan comandos
+// como migrate que no necesitan el resto de la configuración.
+func (d DatabaseConfig) Validate() error {
+	var errs []error
+
+	if d.URL == "" {
+		if d.Host == "" || d.User == "" || d.Name == "" {
+			errs = append(errs, errors.New("database: se requiere url o host, user y name"))
+		}
+		if d.Port <= 0 {
+			errs = append(errs, errors.New("database.port debe ser mayor que cero"))
+		}
+	}
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database: max_open_conns y max_idle_conns no pueden ser negativos"))
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns no puede superar max_open_conns"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// DSN retorna el connection string para lib/pq
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+
+	parts := []string{
+		"host=" + quoteDSN(d.Host),
+		"port=" + strconv.Itoa(d.Port),
+		"user=" + quoteDSN(d.User),
+		"dbname=" + quoteDSN(d.Name),
+	}
+	if d.Password != "" {
+		parts = append(parts, "password="+quoteDSN(d.Password))
+	}
+	if d.SSLMode != "" {
+		parts = append(parts, "sslmode="+quoteDSN(d.SSLMode))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSN escapa valores con espacios o comillas según el formato key=value de lib/pq
+func quoteDSN(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+// Validate revisa la política de bloqueo por intentos fallidos
+func (l LockoutConfig) Validate() error {
+	var errs []error
+	if l.Store != "postgres" && l.Store != "memory" {
+		errs = append(errs, fmt.Errorf("auth.lockout.store debe ser postgres o memory, no %q", l.Store))
+	}
+	if l.MaxFailedAttempts <= 0 || l.MaxFailedAttemptsPerIP <= 0 {
+		errs = append(errs, errors.New("auth.lockout: max_failed_attempts y max_failed_attempts_per_ip deben ser mayores que cero"))
+	}
+	if l.BackoffBase < 0 || l.BackoffMax < l.BackoffBase {
+		errs = append(errs, errors.New("auth.lockout: backoff_max debe ser mayor o igual que backoff_base"))
+	}
+	if l.LockoutDuration <= 0 || l.FailureWindow <= 0 {
+		errs = append(errs, errors.New("auth.lockout: lockout_duration y failure_window deben ser mayores que cero"))
+	}
+	return errors.Join(errs...)
+}
+
+func validateURL(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s es requerido", name)
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%s no es una URL válida: %q", name, value)
+	}
+	return nil
+}
+
+func setString(dst *string, key string) {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		*dst = v
+	}
+}
+
+// setStringList lee una lista separada por comas
+func setStringList(dst *[]string, key string) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return
+	}
+	var values []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	*dst = values
+}
+
+func setInt(dst *int, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s debe ser un entero: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func setDuration(dst *time.Duration, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s debe ser una duración (ej. 15m, 24h): %w", key, err)
+	}
+	*dst = d
+	return nil
+}
+
+func setBool(dst *bool, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s debe ser true o false: %w", key, err)
+	}
+	*dst = b
+	return nil
+}
@@ -0,0 +1,16 @@
+-- Tabla de usuarios usada por AuthService (registro, login y validación).
+-- IF NOT EXISTS permite adoptar bases de datos creadas antes de las migraciones.
+CREATE TABLE IF NOT EXISTS usuarios (
+    id                       SERIAL PRIMARY KEY,
+    nombre_completo          VARCHAR(150) NOT NULL,
+    edad                     INTEGER,
+    rol                      VARCHAR(20)  NOT NULL DEFAULT 'paciente',
+    identificacion           VARCHAR(50),
+    correo                   VARCHAR(255) NOT NULL,
+    contrasena               TEXT         NOT NULL,
+    acepta_tratamiento_datos BOOLEAN      NOT NULL DEFAULT FALSE,
+    fecha_creacion           TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS usuarios_correo_key ON usuarios (correo);
+CREATE UNIQUE INDEX IF NOT EXISTS usuarios_identificacion_key ON usuarios (identificacion);
@@ -0,0 +1,17 @@
+-- Refresh tokens opacos y rotativos emitidos por /auth y /auth/refresh.
+-- Solo se guarda el hash SHA-256 del token. Todos los tokens obtenidos por
+-- rotación a partir de un mismo login comparten familia_id, lo que permite
+-- revocar la sesión completa si se detecta la reutilización de un token.
+CREATE TABLE IF NOT EXISTS tokens_refresco (
+    id              BIGSERIAL PRIMARY KEY,
+    usuario_id      INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    familia_id      UUID        NOT NULL,
+    token_hash      CHAR(64)    NOT NULL UNIQUE,
+    expira_en       TIMESTAMPTZ NOT NULL,
+    creado_en       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    usado_en        TIMESTAMPTZ,
+    revocado_en     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS tokens_refresco_familia_idx ON tokens_refresco (familia_id);
+CREATE INDEX IF NOT EXISTS tokens_refresco_usuario_idx ON tokens_refresco (usuario_id);
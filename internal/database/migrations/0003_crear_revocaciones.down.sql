@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS revocaciones_usuario;
+DROP TABLE IF EXISTS tokens_revocados;
@@ -0,0 +1,18 @@
+-- Lista de access tokens (JWT) revocados antes de su expiración, por jti.
+-- Las filas pueden eliminarse una vez pasado expira_en.
+CREATE TABLE IF NOT EXISTS tokens_revocados (
+    jti         UUID PRIMARY KEY,
+    usuario_id  INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    expira_en   TIMESTAMPTZ NOT NULL,
+    revocado_en TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS tokens_revocados_expira_idx ON tokens_revocados (expira_en);
+
+-- Revocación masiva por usuario: todo token emitido antes de revocado_desde
+-- se considera inválido (cambio de contraseña, desactivación de cuenta, etc.).
+CREATE TABLE IF NOT EXISTS revocaciones_usuario (
+    usuario_id     INTEGER PRIMARY KEY REFERENCES usuarios (id) ON DELETE CASCADE,
+    revocado_desde TIMESTAMPTZ NOT NULL,
+    motivo         TEXT
+);
@@ -0,0 +1,13 @@
+-- Tokens de un solo uso enviados por correo (restablecimiento de contraseña, etc.).
+-- Solo se guarda el hash SHA-256; proposito distingue el flujo al que pertenecen.
+CREATE TABLE IF NOT EXISTS tokens_usuario (
+    id          BIGSERIAL PRIMARY KEY,
+    usuario_id  INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    proposito   VARCHAR(30) NOT NULL,
+    token_hash  CHAR(64)    NOT NULL UNIQUE,
+    expira_en   TIMESTAMPTZ NOT NULL,
+    creado_en   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    usado_en    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS tokens_usuario_usuario_idx ON tokens_usuario (usuario_id, proposito);
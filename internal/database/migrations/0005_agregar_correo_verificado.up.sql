@@ -0,0 +1,4 @@
+-- Estado de verificación del correo. Las cuentas existentes se dan por
+-- verificadas (DEFAULT TRUE al agregar la columna); las nuevas nacen sin verificar.
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS correo_verificado BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE usuarios ALTER COLUMN correo_verificado SET DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auditoria;
+DROP TABLE IF EXISTS intentos_login;
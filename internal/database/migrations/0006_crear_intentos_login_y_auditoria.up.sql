@@ -0,0 +1,24 @@
+-- Contadores de intentos fallidos de login. clave identifica el contador:
+-- "cuenta:<correo>" o "ip:<dirección>". Se comparten entre réplicas.
+CREATE TABLE IF NOT EXISTS intentos_login (
+    clave           VARCHAR(320) PRIMARY KEY,
+    fallos          INTEGER      NOT NULL,
+    ultimo_fallo    TIMESTAMPTZ  NOT NULL,
+    bloqueado_hasta TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS intentos_login_ultimo_fallo_idx ON intentos_login (ultimo_fallo);
+
+-- Registro de auditoría de eventos de seguridad (bloqueos, desbloqueos, etc.).
+-- usuario_id es NULL cuando el evento no corresponde a una cuenta existente.
+CREATE TABLE IF NOT EXISTS auditoria (
+    id         BIGSERIAL PRIMARY KEY,
+    usuario_id INTEGER     REFERENCES usuarios (id) ON DELETE SET NULL,
+    evento     VARCHAR(50) NOT NULL,
+    ip         VARCHAR(64),
+    detalle    JSONB       NOT NULL DEFAULT '{}',
+    creado_en  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS auditoria_usuario_idx ON auditoria (usuario_id, creado_en);
+CREATE INDEX IF NOT EXISTS auditoria_evento_idx ON auditoria (evento, creado_en);
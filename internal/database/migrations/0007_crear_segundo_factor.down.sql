@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS codigos_recuperacion;
+DROP TABLE IF EXISTS segundo_factor;
@@ -0,0 +1,18 @@
+-- Segundo factor TOTP (RFC 6238) por usuario. activado_en es NULL mientras el
+-- enrolamiento no se confirma con un código; ultimo_paso evita reusar un código.
+CREATE TABLE IF NOT EXISTS segundo_factor (
+    usuario_id  INTEGER PRIMARY KEY REFERENCES usuarios (id) ON DELETE CASCADE,
+    secreto     TEXT        NOT NULL,
+    activado_en TIMESTAMPTZ,
+    ultimo_paso BIGINT      NOT NULL DEFAULT 0,
+    creado_en   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Códigos de recuperación de un solo uso; solo se guarda su hash SHA-256.
+CREATE TABLE IF NOT EXISTS codigos_recuperacion (
+    id          BIGSERIAL PRIMARY KEY,
+    usuario_id  INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    codigo_hash CHAR(64)    NOT NULL,
+    usado_en    TIMESTAMPTZ,
+    UNIQUE (usuario_id, codigo_hash)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS usuarios_rol_estado_idx;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS estado;
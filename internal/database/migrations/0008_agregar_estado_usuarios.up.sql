@@ -0,0 +1,7 @@
+-- Estado de la cuenta: activo, inactivo (desactivada por un administrador) o
+-- pendiente_aprobacion (doctor registrado que un administrador aún no aprueba).
+-- Las cuentas existentes quedan activas; los doctores sin perfil_doctor
+-- pasan a pendiente_aprobacion en 0016.
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS estado VARCHAR(30) NOT NULL DEFAULT 'activo';
+
+CREATE INDEX IF NOT EXISTS usuarios_rol_estado_idx ON usuarios (rol, estado);
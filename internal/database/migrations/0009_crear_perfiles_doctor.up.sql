@@ -0,0 +1,14 @@
+-- Datos profesionales con los que un doctor solicita su cuenta. La solicitud
+-- se revisa con el estado de la cuenta en usuarios (pendiente_aprobacion,
+-- activo o rechazado); aquí queda quién y cuándo la revisó.
+CREATE TABLE IF NOT EXISTS perfiles_doctor (
+    usuario_id      INTEGER      PRIMARY KEY REFERENCES usuarios (id) ON DELETE CASCADE,
+    numero_licencia VARCHAR(50)  NOT NULL,
+    especialidad    VARCHAR(100) NOT NULL,
+    revisado_por    INTEGER      REFERENCES usuarios (id) ON DELETE SET NULL,
+    revisado_en     TIMESTAMPTZ,
+    motivo_rechazo  TEXT,
+    creado_en       TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS perfiles_doctor_numero_licencia_key ON perfiles_doctor (numero_licencia);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS usuario_roles;
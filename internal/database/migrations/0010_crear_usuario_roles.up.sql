@@ -0,0 +1,14 @@
+-- Roles de cada usuario: un usuario puede tener varios. usuarios.rol se
+-- mantiene como rol principal (el del registro o el último asignado con
+-- changeUserRole) y siempre figura también en esta tabla.
+CREATE TABLE IF NOT EXISTS usuario_roles (
+    usuario_id INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    rol        VARCHAR(20) NOT NULL,
+    PRIMARY KEY (usuario_id, rol)
+);
+
+CREATE INDEX IF NOT EXISTS usuario_roles_rol_idx ON usuario_roles (rol);
+
+INSERT INTO usuario_roles (usuario_id, rol)
+SELECT id, rol FROM usuarios
+ON CONFLICT DO NOTHING;
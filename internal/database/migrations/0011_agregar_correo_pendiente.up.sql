@@ -0,0 +1,3 @@
+-- Correo nuevo que el usuario pidió desde su perfil. Reemplaza a correo
+-- recién cuando el usuario abre el enlace enviado a esa dirección.
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS correo_pendiente VARCHAR(255);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS exportaciones_datos;
@@ -0,0 +1,15 @@
+-- Exportaciones de datos personales (habeas data) pedidas por los usuarios.
+-- El ZIP se guarda en archivo para que cualquier réplica pueda servirlo; se
+-- borra al expirar (estado expirada).
+CREATE TABLE IF NOT EXISTS exportaciones_datos (
+    id            UUID        PRIMARY KEY,
+    usuario_id    INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    estado        VARCHAR(20) NOT NULL DEFAULT 'pendiente',
+    archivo       BYTEA,
+    error         TEXT,
+    creado_en     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completado_en TIMESTAMPTZ,
+    expira_en     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS exportaciones_datos_usuario_idx ON exportaciones_datos (usuario_id, creado_en DESC);
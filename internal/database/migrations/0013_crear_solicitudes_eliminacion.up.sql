@@ -0,0 +1,22 @@
+-- Solicitudes de eliminación de cuenta o de retiro del consentimiento de
+-- tratamiento de datos. La cuenta queda bloqueada al pedirla y se anonimiza
+-- cuando vence ejecutar_en; mientras tanto el usuario puede cancelarla con el
+-- enlace enviado por correo. estado_anterior es el estado de la cuenta al que
+-- se vuelve si se cancela.
+CREATE TABLE IF NOT EXISTS solicitudes_eliminacion (
+    id              BIGSERIAL   PRIMARY KEY,
+    usuario_id      INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    motivo          VARCHAR(30) NOT NULL,
+    estado          VARCHAR(20) NOT NULL DEFAULT 'programada',
+    estado_anterior VARCHAR(30) NOT NULL,
+    ultimo_error    TEXT,
+    solicitada_en   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ejecutar_en     TIMESTAMPTZ NOT NULL,
+    finalizada_en   TIMESTAMPTZ
+);
+
+-- Un usuario tiene a lo sumo una solicitud programada
+CREATE UNIQUE INDEX IF NOT EXISTS solicitudes_eliminacion_programada_key
+    ON solicitudes_eliminacion (usuario_id) WHERE estado = 'programada';
+CREATE INDEX IF NOT EXISTS solicitudes_eliminacion_ejecutar_idx
+    ON solicitudes_eliminacion (ejecutar_en) WHERE estado = 'programada';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS consentimientos;
+DROP TABLE IF EXISTS politicas_privacidad;
@@ -0,0 +1,27 @@
+-- Versiones de la política de tratamiento de datos y registro de las
+-- aceptaciones de cada usuario. La versión vigente es la mayor; quien no la
+-- aceptó debe hacerlo antes de usar las operaciones de GraphQL.
+CREATE TABLE IF NOT EXISTS politicas_privacidad (
+    version       INTEGER     PRIMARY KEY,
+    contenido     TEXT        NOT NULL,
+    publicada_por INTEGER     REFERENCES usuarios (id) ON DELETE SET NULL,
+    publicada_en  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS consentimientos (
+    id          BIGSERIAL   PRIMARY KEY,
+    usuario_id  INTEGER     NOT NULL REFERENCES usuarios (id) ON DELETE CASCADE,
+    version     INTEGER     NOT NULL REFERENCES politicas_privacidad (version),
+    ip          VARCHAR(64),
+    aceptado_en TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (usuario_id, version)
+);
+
+-- La política aceptada hasta ahora con acepta_tratamiento_datos es la versión 1
+INSERT INTO politicas_privacidad (version, contenido)
+VALUES (1, 'Política de tratamiento de datos personales aceptada al registrarse.')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO consentimientos (usuario_id, version, aceptado_en)
+SELECT id, 1, fecha_creacion FROM usuarios WHERE acepta_tratamiento_datos
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+ALTER TABLE tokens_usuario DROP COLUMN IF EXISTS intentos_fallidos;
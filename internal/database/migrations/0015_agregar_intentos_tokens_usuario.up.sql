@@ -0,0 +1,3 @@
+-- Códigos incorrectos recibidos por un desafío de segundo factor; al llegar
+-- al máximo el desafío se consume y hay que volver a iniciar sesión.
+ALTER TABLE tokens_usuario ADD COLUMN IF NOT EXISTS intentos_fallidos INT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- No se puede distinguir a los doctores que cambió 0016 de las solicitudes
+-- pendientes normales; la migración no se revierte.
+SELECT 1;
@@ -0,0 +1,9 @@
+-- Los doctores que se registraron antes de la solicitud con licencia
+-- (0009) quedaron activos sin perfil_doctor, es decir, sin haber sido
+-- aprobados. Pasan a pendiente_aprobacion; rejectDoctor los rechaza y
+-- approveDoctor exige que antes tengan perfil de doctor.
+UPDATE usuarios u SET estado = 'pendiente_aprobacion'
+WHERE u.estado = 'activo'
+  AND (u.rol = 'doctor' OR EXISTS (
+        SELECT 1 FROM usuario_roles r WHERE r.usuario_id = u.id AND r.rol = 'doctor'))
+  AND NOT EXISTS (SELECT 1 FROM perfiles_doctor p WHERE p.usuario_id = u.id);
@@ -0,0 +1 @@
+ALTER TABLE consentimientos DROP COLUMN IF EXISTS retirado_en;
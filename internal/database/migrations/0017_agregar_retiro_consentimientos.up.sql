@@ -0,0 +1,4 @@
+-- withdrawConsent marca las aceptaciones del usuario como retiradas y
+-- cancelar la solicitud las restablece. Una aceptación retirada no cuenta
+-- como consentimiento vigente.
+ALTER TABLE consentimientos ADD COLUMN IF NOT EXISTS retirado_en TIMESTAMPTZ;
@@ -0,0 +1,151 @@
+// Package dataloader agrupa en un solo lote las cargas por clave que se piden
+// durante una misma petición, para evitar el problema N+1 al resolver campos
+// de GraphQL. Cada Loader guarda además los resultados por clave, por lo que
+// se debe crear uno nuevo por petición.
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc carga un lote de claves. Retorna los valores y los errores en el
+// mismo orden que keys; errs puede ser nil si ninguna clave falló.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// Loader reúne las claves pedidas durante Wait (o hasta MaxBatch) y las
+// carga con una sola llamada a la BatchFunc
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	cache map[K]*resultado[V]
+	lote  *lote[K, V]
+}
+
+type resultado[V any] struct {
+	listo chan struct{}
+	valor V
+	err   error
+}
+
+type lote[K comparable, V any] struct {
+	keys       []K
+	resultados []*resultado[V]
+	cerrado    bool
+}
+
+// New crea un Loader. wait es cuánto se espera a que lleguen más claves antes
+// de cargar el lote; maxBatch <= 0 no limita el tamaño del lote.
+func New[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[K]*resultado[V]),
+	}
+}
+
+// Load retorna el valor de la clave, cargándolo junto con las demás claves
+// pedidas en el mismo intervalo. El lote usa el contexto de la primera
+// llamada que lo abrió.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	r := l.encolar(ctx, key)
+	select {
+	case <-r.listo:
+		return r.valor, r.err
+	case <-ctx.Done():
+		var cero V
+		return cero, ctx.Err()
+	}
+}
+
+// LoadMany carga varias claves en el mismo lote
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, []error) {
+	pendientes := make([]*resultado[V], len(keys))
+	for i, key := range keys {
+		pendientes[i] = l.encolar(ctx, key)
+	}
+
+	valores := make([]V, len(keys))
+	var errs []error
+	for i, r := range pendientes {
+		select {
+		case <-r.listo:
+			valores[i] = r.valor
+			if r.err != nil {
+				if errs == nil {
+					errs = make([]error, len(keys))
+				}
+				errs[i] = r.err
+			}
+		case <-ctx.Done():
+			if errs == nil {
+				errs = make([]error, len(keys))
+			}
+			errs[i] = ctx.Err()
+		}
+	}
+	return valores, errs
+}
+
+func (l *Loader[K, V]) encolar(ctx context.Context, key K) *resultado[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.cache[key]; ok {
+		return r
+	}
+
+	r := &resultado[V]{listo: make(chan struct{})}
+	l.cache[key] = r
+
+	if l.lote == nil {
+		l.lote = &lote[K, V]{}
+		b := l.lote
+		time.AfterFunc(l.wait, func() { l.despachar(ctx, b) })
+	}
+	b := l.lote
+	b.keys = append(b.keys, key)
+	b.resultados = append(b.resultados, r)
+
+	if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+		l.lote = nil
+		b.cerrado = true
+		go l.ejecutar(ctx, b)
+	}
+	return r
+}
+
+// despachar carga el lote cuando vence la espera, salvo que ya se haya
+// cargado al llenarse
+func (l *Loader[K, V]) despachar(ctx context.Context, b *lote[K, V]) {
+	l.mu.Lock()
+	if b.cerrado {
+		l.mu.Unlock()
+		return
+	}
+	b.cerrado = true
+	if l.lote == b {
+		l.lote = nil
+	}
+	l.mu.Unlock()
+
+	l.ejecutar(ctx, b)
+}
+
+func (l *Loader[K, V]) ejecutar(ctx context.Context, b *lote[K, V]) {
+	valores, errs := l.fetch(ctx, b.keys)
+	for i, r := range b.resultados {
+		if i < len(valores) {
+			r.valor = valores[i]
+		}
+		if i < len(errs) {
+			r.err = errs[i]
+		}
+		close(r.listo)
+	}
+}
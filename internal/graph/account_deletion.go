@@ -0,0 +1,17 @@
+package graph
+
+import (
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+func toGraphAccountDeletion(d *models.AccountDeletion) *model.AccountDeletion {
+	return &model.AccountDeletion{
+		Motivo:         model.DeletionReason(strings.ToUpper(d.Motivo)),
+		SolicitadaEn:   d.SolicitadaEn.Format(time.RFC3339),
+		ProgramadaPara: d.EjecutarEn.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// idUsuarioAutenticado retorna el ID numérico del usuario de la petición
+// (en los resolvers de administración, el administrador que hace el cambio)
+func idUsuarioAutenticado(ctx context.Context) (int, error) {
+	return strconv.Atoi(usuarioAutenticado(ctx).UserID)
+}
+
+// parseUserID convierte el ID de GraphQL al ID numérico de la tabla usuarios
+func parseUserID(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("id de usuario inválido: %q", id)
+	}
+	return n, nil
+}
+
+// Los enums de GraphQL son los valores de la base de datos en mayúsculas
+func rolDesdeGraph(rol model.Role) string { return strings.ToLower(string(rol)) }
+
+func rolesAGraph(roles []string) []model.Role {
+	resultado := make([]model.Role, 0, len(roles))
+	for _, rol := range roles {
+		resultado = append(resultado, model.Role(strings.ToUpper(rol)))
+	}
+	return resultado
+}
+
+func estadoDesdeGraph(estado model.UserStatus) string { return strings.ToLower(string(estado)) }
+
+func toGraphUser(u *models.User) *model.User {
+	usuario := &model.User{
+		ID:               strconv.Itoa(u.ID),
+		NombreCompleto:   u.NombreCompleto,
+		Correo:           u.Correo,
+		Identificacion:   u.Identificacion,
+		Edad:             u.Edad,
+		Rol:              model.Role(strings.ToUpper(u.Rol)),
+		Roles:            rolesAGraph(u.RolesAsignados()),
+		Estado:           model.UserStatus(strings.ToUpper(u.Estado)),
+		CorreoVerificado: u.CorreoVerificado,
+		FechaCreacion:    u.FechaCreacion.Format(time.RFC3339),
+	}
+	if u.CorreoPendiente != "" {
+		usuario.CorreoPendiente = &u.CorreoPendiente
+	}
+	return usuario
+}
+
+func toGraphDoctorProfile(p *models.DoctorProfile) *model.DoctorProfile {
+	perfil := &model.DoctorProfile{
+		NumeroLicencia: p.NumeroLicencia,
+		Especialidad:   p.Especialidad,
+		FechaSolicitud: p.CreadoEn.Format(time.RFC3339),
+	}
+	if p.RevisadoPor != nil {
+		revisadoPor := strconv.Itoa(*p.RevisadoPor)
+		perfil.RevisadoPor = &revisadoPor
+	}
+	if p.RevisadoEn != nil {
+		revisadoEn := p.RevisadoEn.Format(time.RFC3339)
+		perfil.RevisadoEn = &revisadoEn
+	}
+	if p.MotivoRechazo != "" {
+		perfil.MotivoRechazo = &p.MotivoRechazo
+	}
+	return perfil
+}
@@ -0,0 +1,111 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// casoValidado indica si un doctor ya validó el caso; solo entonces existe
+// un diagnóstico médico que consultar
+func casoValidado(estado string) bool {
+	return strings.EqualFold(estado, "validado")
+}
+
+// cargarDiagnostico obtiene el diagnóstico del caso con el dataloader de la
+// petición. El diagnóstico es opcional: si el servicio prediagnostic falla se
+// registra y el campo queda en null, como el resto del caso.
+func cargarDiagnostico(ctx context.Context, caseID string) *model.Diagnostic {
+	diagnostic, err := services.LoadersFromContext(ctx).DiagnosticByCaseID.Load(ctx, caseID)
+	if err != nil {
+		log.Printf("Warning: no se pudo obtener diagnóstico para caso %s: %v", caseID, err)
+		return nil
+	}
+	return diagnostic
+}
+
+// filtroCasosDesdeGraph convierte el filtro de GraphQL al de CaseService
+func filtroCasosDesdeGraph(filter *model.CaseFilter) (services.CaseFilter, error) {
+	var filtro services.CaseFilter
+	if filter == nil {
+		return filtro, nil
+	}
+	valor := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return strings.TrimSpace(*s)
+	}
+	filtro.Estado = valor(filter.Estado)
+	filtro.Etiqueta = valor(filter.Etiqueta)
+	filtro.PacienteID = valor(filter.PacienteID)
+	filtro.DoctorAsignado = valor(filter.DoctorAsignado)
+	filtro.ProbMin = filter.ProbMin
+	filtro.ProbMax = filter.ProbMax
+
+	var err error
+	if filtro.FechaDesde, err = fechaFiltro(valor(filter.FechaDesde), false); err != nil {
+		return filtro, fmt.Errorf("fechaDesde inválida: %w", err)
+	}
+	if filtro.FechaHasta, err = fechaFiltro(valor(filter.FechaHasta), true); err != nil {
+		return filtro, fmt.Errorf("fechaHasta inválida: %w", err)
+	}
+	return filtro, nil
+}
+
+// fechaFiltro acepta AAAA-MM-DD o RFC3339. Una fecha sin hora usada como
+// límite superior incluye todo ese día.
+func fechaFiltro(valor string, hasta bool) (*time.Time, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	if dia, err := time.Parse(time.DateOnly, valor); err == nil {
+		if hasta {
+			dia = dia.AddDate(0, 0, 1)
+		}
+		return &dia, nil
+	}
+	fecha, err := time.Parse(time.RFC3339, valor)
+	if err != nil {
+		return nil, fmt.Errorf("use AAAA-MM-DD o RFC3339")
+	}
+	if hasta {
+		// RFC3339 es un instante exacto: también se incluye
+		fecha = fecha.Add(time.Nanosecond)
+	}
+	return &fecha, nil
+}
+
+func ordenCasosDesdeGraph(orderBy *model.CaseOrder) services.CaseOrder {
+	if orderBy == nil {
+		return services.CaseOrder{}
+	}
+	return services.CaseOrder{
+		Campo:       strings.ToLower(string(orderBy.Campo)),
+		Descendente: orderBy.Direccion == model.OrderDirectionDesc,
+	}
+}
+
+func toGraphCaseConnection(page *services.CasePage) *model.CaseConnection {
+	conexion := &model.CaseConnection{
+		Edges:      make([]*model.CaseEdge, 0, len(page.Casos)),
+		TotalCount: page.Total,
+		PageInfo: &model.PageInfo{
+			HasNextPage:     page.HayPaginaSiguiente,
+			HasPreviousPage: page.HayPaginaAnterior,
+		},
+	}
+	for i, caso := range page.Casos {
+		conexion.Edges = append(conexion.Edges, &model.CaseEdge{Cursor: page.Cursores[i], Node: caso})
+	}
+	if len(page.Cursores) > 0 {
+		conexion.PageInfo.StartCursor = &page.Cursores[0]
+		conexion.PageInfo.EndCursor = &page.Cursores[len(page.Cursores)-1]
+	}
+	return conexion
+}
@@ -0,0 +1,31 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+func toGraphPrivacyPolicy(p *models.PrivacyPolicy) *model.PrivacyPolicy {
+	return &model.PrivacyPolicy{
+		Version:     p.Version,
+		Contenido:   p.Contenido,
+		PublicadaEn: p.PublicadaEn.Format(time.RFC3339),
+	}
+}
+
+func toGraphConsent(c *models.Consent) *model.ConsentRecord {
+	consentimiento := &model.ConsentRecord{
+		Version:    c.Version,
+		AceptadoEn: c.AceptadoEn.Format(time.RFC3339),
+	}
+	if c.IP != "" {
+		consentimiento.IP = &c.IP
+	}
+	if c.RetiradoEn != nil {
+		retiradoEn := c.RetiradoEn.Format(time.RFC3339)
+		consentimiento.RetiradoEn = &retiradoEn
+	}
+	return consentimiento
+}
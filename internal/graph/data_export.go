@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// rutaDescargaExportacion es el endpoint REST que entrega el ZIP
+const rutaDescargaExportacion = "/data-export/download"
+
+func toGraphDataExport(e *models.DataExport) *model.DataExport {
+	exportacion := &model.DataExport{
+		ID:           e.ID,
+		Estado:       model.DataExportStatus(strings.ToUpper(e.Estado)),
+		SolicitadaEn: e.CreadoEn.Format(time.RFC3339),
+	}
+	if e.CompletadoEn != nil {
+		completadaEn := e.CompletadoEn.Format(time.RFC3339)
+		exportacion.CompletadaEn = &completadaEn
+	}
+	if e.ExpiraEn != nil {
+		expiraEn := e.ExpiraEn.Format(time.RFC3339)
+		exportacion.ExpiraEn = &expiraEn
+	}
+	if e.Estado == models.ExportacionCompletada {
+		urlDescarga := rutaDescargaExportacion + "?id=" + url.QueryEscape(e.ID)
+		exportacion.URLDescarga = &urlDescarga
+	}
+	if e.Error != "" {
+		exportacion.Error = &e.Error
+	}
+	return exportacion
+}
@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/unobeswarch/businesslogic/internal/graph/generated"
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// NewDirectives implementa las directivas @auth, @hasRole y @hasPermission del
+// esquema. Los claims del access token y el estado del consentimiento los deja
+// en el contexto el middleware handlers.AuthContextMiddleware, validando el
+// token una sola vez por petición.
+func NewDirectives(authSrv *services.AuthService, permissionSrv *services.PermissionService) generated.DirectiveRoot {
+	return generated.DirectiveRoot{
+		Auth: func(ctx context.Context, obj any, next graphql.Resolver, requireConsent *bool) (any, error) {
+			if _, err := services.ClaimsFromContext(ctx); err != nil {
+				return nil, errorNoAutenticado(ctx, err)
+			}
+			if requireConsent == nil || *requireConsent {
+				if err := comprobarConsentimiento(ctx); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx)
+		},
+		HasRole: func(ctx context.Context, obj any, next graphql.Resolver, roles []model.Role) (any, error) {
+			claims, err := services.ClaimsFromContext(ctx)
+			if err != nil {
+				return nil, errorNoAutenticado(ctx, err)
+			}
+			if err := comprobarConsentimiento(ctx); err != nil {
+				return nil, err
+			}
+
+			permitidos := make([]string, 0, len(roles))
+			for _, rol := range roles {
+				permitidos = append(permitidos, rolDesdeGraph(rol))
+			}
+			if err := authSrv.AutorizarRoles(ctx, claims, permitidos...); err != nil {
+				return nil, errorSinPermiso(ctx, err)
+			}
+			return next(ctx)
+		},
+		HasPermission: func(ctx context.Context, obj any, next graphql.Resolver, permission string) (any, error) {
+			if _, err := services.ClaimsFromContext(ctx); err != nil {
+				return nil, errorNoAutenticado(ctx, err)
+			}
+			if err := comprobarConsentimiento(ctx); err != nil {
+				return nil, err
+			}
+			if err := permissionSrv.TienePermiso(ctx, permission); err != nil {
+				return nil, errorSinPermiso(ctx, err)
+			}
+			return next(ctx)
+		},
+	}
+}
+
+func errorNoAutenticado(ctx context.Context, err error) error {
+	return &gqlerror.Error{
+		Path:       graphql.GetPath(ctx),
+		Message:    "acceso denegado: " + err.Error(),
+		Extensions: map[string]interface{}{"code": "UNAUTHENTICATED"},
+	}
+}
+
+// comprobarConsentimiento rechaza con CONSENT_REQUIRED, y la versión que se
+// debe aceptar, a quien no aceptó la política vigente
+func comprobarConsentimiento(ctx context.Context) error {
+	err := services.ConsentFromContext(ctx)
+	if err == nil {
+		return nil
+	}
+	var pendiente *services.ConsentimientoPendienteError
+	if !errors.As(err, &pendiente) {
+		return err
+	}
+	return &gqlerror.Error{
+		Path:    graphql.GetPath(ctx),
+		Message: "debes aceptar la versión vigente de la política de tratamiento de datos",
+		Extensions: map[string]interface{}{
+			"code":    services.ErrConsentimientoRequerido.Error(),
+			"version": pendiente.Version,
+		},
+	}
+}
+
+func errorSinPermiso(ctx context.Context, err error) error {
+	return &gqlerror.Error{
+		Path:       graphql.GetPath(ctx),
+		Message:    err.Error(),
+		Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+	}
+}
+
+// usuarioAutenticado retorna los claims del usuario de la petición. Solo se
+// usa en resolvers protegidos por @auth, @hasRole o @hasPermission, que
+// garantizan que existen.
+func usuarioAutenticado(ctx context.Context) *services.UserClaims {
+	claims, _ := services.ClaimsFromContext(ctx)
+	return claims
+}
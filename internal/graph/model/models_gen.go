@@ -2,6 +2,19 @@
 
 package model
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type AccountDeletion struct {
+	Motivo         DeletionReason `json:"motivo"`
+	SolicitadaEn   string         `json:"solicitadaEn"`
+	ProgramadaPara string         `json:"programadaPara"`
+}
+
 type Case struct {
 	ID             string            `json:"id"`
 	PacienteID     string            `json:"pacienteId"`
@@ -12,6 +25,14 @@ type Case struct {
 	URLRadiografia string            `json:"urlRadiografia"`
 	Resultados     *ResultadosModelo `json:"resultados,omitempty"`
 	DoctorAsignado *string           `json:"doctorAsignado,omitempty"`
+	Paciente       *User             `json:"paciente,omitempty"`
+	Diagnostic     *Diagnostic       `json:"diagnostic,omitempty"`
+}
+
+type CaseConnection struct {
+	Edges      []*CaseEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
 }
 
 type CaseDetail struct {
@@ -24,6 +45,44 @@ type CaseDetail struct {
 	Diagnostic    *Diagnostic    `json:"diagnostic,omitempty"`
 }
 
+type CaseEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Case  `json:"node"`
+}
+
+type CaseFilter struct {
+	Estado         *string  `json:"estado,omitempty"`
+	FechaDesde     *string  `json:"fechaDesde,omitempty"`
+	FechaHasta     *string  `json:"fechaHasta,omitempty"`
+	Etiqueta       *string  `json:"etiqueta,omitempty"`
+	ProbMin        *float64 `json:"probMin,omitempty"`
+	ProbMax        *float64 `json:"probMax,omitempty"`
+	PacienteID     *string  `json:"pacienteId,omitempty"`
+	DoctorAsignado *string  `json:"doctorAsignado,omitempty"`
+}
+
+type CaseOrder struct {
+	Campo     CaseOrderField `json:"campo"`
+	Direccion OrderDirection `json:"direccion"`
+}
+
+type ConsentRecord struct {
+	Version    int     `json:"version"`
+	AceptadoEn string  `json:"aceptadoEn"`
+	IP         *string `json:"ip,omitempty"`
+	RetiradoEn *string `json:"retiradoEn,omitempty"`
+}
+
+type DataExport struct {
+	ID           string           `json:"id"`
+	Estado       DataExportStatus `json:"estado"`
+	SolicitadaEn string           `json:"solicitadaEn"`
+	CompletadaEn *string          `json:"completadaEn,omitempty"`
+	ExpiraEn     *string          `json:"expiraEn,omitempty"`
+	URLDescarga  *string          `json:"urlDescarga,omitempty"`
+	Error        *string          `json:"error,omitempty"`
+}
+
 type Diagnostic struct {
 	ID               string  `json:"id"`
 	PrediagnosticoID string  `json:"prediagnosticoId"`
@@ -44,9 +103,25 @@ type DiagnosticResponse struct {
 	DiagnosticID *string `json:"diagnostic_id,omitempty"`
 }
 
+type DoctorProfile struct {
+	NumeroLicencia string  `json:"numeroLicencia"`
+	Especialidad   string  `json:"especialidad"`
+	FechaSolicitud string  `json:"fechaSolicitud"`
+	RevisadoPor    *string `json:"revisadoPor,omitempty"`
+	RevisadoEn     *string `json:"revisadoEn,omitempty"`
+	MotivoRechazo  *string `json:"motivoRechazo,omitempty"`
+}
+
 type Mutation struct {
 }
 
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
 type PreDiagnostic struct {
 	PrediagnosticID  string            `json:"prediagnostic_id"`
 	PacienteID       string            `json:"pacienteId"`
@@ -56,6 +131,19 @@ type PreDiagnostic struct {
 	FechaSubida      string            `json:"fechaSubida"`
 }
 
+type PrivacyPolicy struct {
+	Version     int    `json:"version"`
+	Contenido   string `json:"contenido"`
+	PublicadaEn string `json:"publicadaEn"`
+}
+
+type ProfileInput struct {
+	NombreCompleto *string `json:"nombreCompleto,omitempty"`
+	Edad           *int    `json:"edad,omitempty"`
+	Correo         *string `json:"correo,omitempty"`
+	Identificacion *string `json:"identificacion,omitempty"`
+}
+
 type Query struct {
 }
 
@@ -64,3 +152,377 @@ type ResultadosModelo struct {
 	Etiqueta           string  `json:"etiqueta"`
 	FechaProcesamiento string  `json:"fechaProcesamiento"`
 }
+
+type User struct {
+	ID               string         `json:"id"`
+	NombreCompleto   string         `json:"nombreCompleto"`
+	Correo           string         `json:"correo"`
+	Identificacion   string         `json:"identificacion"`
+	Edad             int            `json:"edad"`
+	Rol              Role           `json:"rol"`
+	Roles            []Role         `json:"roles"`
+	Estado           UserStatus     `json:"estado"`
+	CorreoVerificado bool           `json:"correoVerificado"`
+	CorreoPendiente  *string        `json:"correoPendiente,omitempty"`
+	FechaCreacion    string         `json:"fechaCreacion"`
+	DoctorProfile    *DoctorProfile `json:"doctorProfile,omitempty"`
+}
+
+type UserFilter struct {
+	Busqueda *string     `json:"busqueda,omitempty"`
+	Rol      *Role       `json:"rol,omitempty"`
+	Estado   *UserStatus `json:"estado,omitempty"`
+}
+
+type UserPage struct {
+	Usuarios []*User `json:"usuarios"`
+	Total    int     `json:"total"`
+}
+
+type CaseOrderField string
+
+const (
+	CaseOrderFieldFechaSubida  CaseOrderField = "FECHA_SUBIDA"
+	CaseOrderFieldProbNeumonia CaseOrderField = "PROB_NEUMONIA"
+	CaseOrderFieldEstado       CaseOrderField = "ESTADO"
+)
+
+var AllCaseOrderField = []CaseOrderField{
+	CaseOrderFieldFechaSubida,
+	CaseOrderFieldProbNeumonia,
+	CaseOrderFieldEstado,
+}
+
+func (e CaseOrderField) IsValid() bool {
+	switch e {
+	case CaseOrderFieldFechaSubida, CaseOrderFieldProbNeumonia, CaseOrderFieldEstado:
+		return true
+	}
+	return false
+}
+
+func (e CaseOrderField) String() string {
+	return string(e)
+}
+
+func (e *CaseOrderField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CaseOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CaseOrderField", str)
+	}
+	return nil
+}
+
+func (e CaseOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CaseOrderField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CaseOrderField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPendiente  DataExportStatus = "PENDIENTE"
+	DataExportStatusProcesando DataExportStatus = "PROCESANDO"
+	DataExportStatusCompletada DataExportStatus = "COMPLETADA"
+	DataExportStatusFallida    DataExportStatus = "FALLIDA"
+	DataExportStatusExpirada   DataExportStatus = "EXPIRADA"
+)
+
+var AllDataExportStatus = []DataExportStatus{
+	DataExportStatusPendiente,
+	DataExportStatusProcesando,
+	DataExportStatusCompletada,
+	DataExportStatusFallida,
+	DataExportStatusExpirada,
+}
+
+func (e DataExportStatus) IsValid() bool {
+	switch e {
+	case DataExportStatusPendiente, DataExportStatusProcesando, DataExportStatusCompletada, DataExportStatusFallida, DataExportStatusExpirada:
+		return true
+	}
+	return false
+}
+
+func (e DataExportStatus) String() string {
+	return string(e)
+}
+
+func (e *DataExportStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DataExportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DataExportStatus", str)
+	}
+	return nil
+}
+
+func (e DataExportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DataExportStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DataExportStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DeletionReason string
+
+const (
+	DeletionReasonEliminarCuenta        DeletionReason = "ELIMINAR_CUENTA"
+	DeletionReasonRetirarConsentimiento DeletionReason = "RETIRAR_CONSENTIMIENTO"
+)
+
+var AllDeletionReason = []DeletionReason{
+	DeletionReasonEliminarCuenta,
+	DeletionReasonRetirarConsentimiento,
+}
+
+func (e DeletionReason) IsValid() bool {
+	switch e {
+	case DeletionReasonEliminarCuenta, DeletionReasonRetirarConsentimiento:
+		return true
+	}
+	return false
+}
+
+func (e DeletionReason) String() string {
+	return string(e)
+}
+
+func (e *DeletionReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeletionReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeletionReason", str)
+	}
+	return nil
+}
+
+func (e DeletionReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DeletionReason) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DeletionReason) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OrderDirection string
+
+const (
+	OrderDirectionAsc  OrderDirection = "ASC"
+	OrderDirectionDesc OrderDirection = "DESC"
+)
+
+var AllOrderDirection = []OrderDirection{
+	OrderDirectionAsc,
+	OrderDirectionDesc,
+}
+
+func (e OrderDirection) IsValid() bool {
+	switch e {
+	case OrderDirectionAsc, OrderDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e OrderDirection) String() string {
+	return string(e)
+}
+
+func (e *OrderDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderDirection", str)
+	}
+	return nil
+}
+
+func (e OrderDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RolePaciente Role = "PACIENTE"
+	RoleDoctor   Role = "DOCTOR"
+	RoleAdmin    Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RolePaciente,
+	RoleDoctor,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RolePaciente, RoleDoctor, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type UserStatus string
+
+const (
+	UserStatusActivo               UserStatus = "ACTIVO"
+	UserStatusInactivo             UserStatus = "INACTIVO"
+	UserStatusPendienteAprobacion  UserStatus = "PENDIENTE_APROBACION"
+	UserStatusRechazado            UserStatus = "RECHAZADO"
+	UserStatusEliminacionPendiente UserStatus = "ELIMINACION_PENDIENTE"
+	UserStatusAnonimizado          UserStatus = "ANONIMIZADO"
+)
+
+var AllUserStatus = []UserStatus{
+	UserStatusActivo,
+	UserStatusInactivo,
+	UserStatusPendienteAprobacion,
+	UserStatusRechazado,
+	UserStatusEliminacionPendiente,
+	UserStatusAnonimizado,
+}
+
+func (e UserStatus) IsValid() bool {
+	switch e {
+	case UserStatusActivo, UserStatusInactivo, UserStatusPendienteAprobacion, UserStatusRechazado, UserStatusEliminacionPendiente, UserStatusAnonimizado:
+		return true
+	}
+	return false
+}
+
+func (e UserStatus) String() string {
+	return string(e)
+}
+
+func (e *UserStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserStatus", str)
+	}
+	return nil
+}
+
+func (e UserStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *UserStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e UserStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,341 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/unobeswarch/businesslogic/internal/clients/prediagnostictest"
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/graph"
+	"github.com/unobeswarch/businesslogic/internal/graph/generated"
+	"github.com/unobeswarch/businesslogic/internal/handlers"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// entorno es /query armado como en cmd/server, con repositorios en memoria y
+// el servicio prediagnostic falso. Las peticiones llevan los claims en el
+// contexto en lugar de un access token firmado.
+type entorno struct {
+	prediagnostic *prediagnostictest.Server
+	users         *repository.MemoryUserRepository
+	handler       http.Handler
+}
+
+func nuevoEntorno(t *testing.T) *entorno {
+	t.Helper()
+
+	prediagnostic := prediagnostictest.NewServer()
+	t.Cleanup(prediagnostic.Close)
+
+	users := repository.NewMemoryUserRepository()
+	client := prediagnostic.PrediagnosticClient()
+	prediagnosticService := services.NewPrediagnosticService(client)
+	caseService := services.NewCaseService(client, prediagnostic.Config(), users)
+	authService := services.NewAuthService(users, nil, nil, nil, nil, nil, config.JWTConfig{})
+	permissionService := services.NewPermissionService(users, config.Default().Auth.Permissions)
+
+	resolver := &graph.Resolver{
+		PrediagnosticSrv: prediagnosticService,
+		CaseSrv:          caseService,
+		AuthSrv:          authService,
+		DiagnosticSrv:    services.NewDiagnosticService(client),
+		PermissionSrv:    permissionService,
+	}
+	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{
+		Resolvers:  resolver,
+		Directives: graph.NewDirectives(authService, permissionService),
+	}))
+
+	return &entorno{
+		prediagnostic: prediagnostic,
+		users:         users,
+		handler:       handlers.DataLoaderMiddleware(users, caseService, prediagnosticService)(srv),
+	}
+}
+
+// crearUsuario guarda un usuario activo con rol y retorna sus claims
+func (e *entorno) crearUsuario(t *testing.T, rol, correo string) *services.UserClaims {
+	t.Helper()
+
+	usuario := &models.User{
+		NombreCompleto: "Usuario " + rol,
+		Rol:            rol,
+		Correo:         correo,
+		Identificacion: correo,
+		Estado:         models.EstadoActivo,
+	}
+	if err := e.users.Create(context.Background(), usuario); err != nil {
+		t.Fatalf("creando usuario: %v", err)
+	}
+	return &services.UserClaims{
+		UserID: strconv.Itoa(usuario.ID),
+		Email:  usuario.Correo,
+		Role:   rol,
+		Roles:  usuario.Roles,
+		Name:   usuario.NombreCompleto,
+	}
+}
+
+// consultar envía la operación a /query como el usuario de claims y
+// decodifica data en destino; falla el test si la respuesta trae errores
+func (e *entorno) consultar(t *testing.T, claims *services.UserClaims, query string, variables map[string]interface{}, destino interface{}) {
+	t.Helper()
+
+	respuesta := e.enviar(t, claims, query, variables)
+	if len(respuesta.Errors) > 0 {
+		t.Fatalf("la operación respondió con errores: %+v", respuesta.Errors)
+	}
+	if err := json.Unmarshal(respuesta.Data, destino); err != nil {
+		t.Fatalf("decodificando data %s: %v", respuesta.Data, err)
+	}
+}
+
+// errores envía la operación y retorna los mensajes de error de la respuesta
+func (e *entorno) errores(t *testing.T, claims *services.UserClaims, query string, variables map[string]interface{}) []string {
+	t.Helper()
+
+	var mensajes []string
+	for _, err := range e.enviar(t, claims, query, variables).Errors {
+		mensajes = append(mensajes, err.Message)
+	}
+	return mensajes
+}
+
+type respuestaGraphQL struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (e *entorno) enviar(t *testing.T, claims *services.UserClaims, query string, variables map[string]interface{}) respuestaGraphQL {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/query", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(services.ContextWithClaims(req.Context(), claims))
+
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+
+	var respuesta respuestaGraphQL
+	if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil {
+		t.Fatalf("respuesta HTTP %d no es JSON: %s", rec.Code, rec.Body.String())
+	}
+	return respuesta
+}
+
+func TestCasesDelPaciente(t *testing.T) {
+	e := nuevoEntorno(t)
+	paciente := e.crearUsuario(t, models.RolPaciente, "paciente@example.com")
+	otro := e.crearUsuario(t, models.RolPaciente, "otro@example.com")
+
+	ahora := time.Now()
+	procesado := e.prediagnostic.Service.AgregarCaso(prediagnostictest.Caso{
+		UserID: paciente.UserID, Estado: "completed", FechaSubida: ahora.Add(-time.Hour),
+		Radiografia: "procesado.jpg", ProbNeumonia: 0.87, Etiqueta: "Neumonía",
+	})
+	pendiente := e.prediagnostic.Service.AgregarCaso(prediagnostictest.Caso{
+		UserID: paciente.UserID, Estado: "pending", FechaSubida: ahora, Radiografia: "pendiente.jpg",
+	})
+	e.prediagnostic.Service.AgregarCaso(prediagnostictest.Caso{
+		UserID: otro.UserID, Estado: "pending", FechaSubida: ahora, Radiografia: "otro.jpg",
+	})
+
+	var data struct {
+		Cases struct {
+			TotalCount int
+			Edges      []struct {
+				Node struct {
+					ID         string
+					PacienteID string
+					Resultados *struct {
+						ProbNeumonia float64
+						Etiqueta     string
+					}
+				}
+			}
+			PageInfo struct {
+				HasNextPage bool
+			}
+		}
+	}
+	e.consultar(t, paciente, `query {
+		cases(first: 10) {
+			totalCount
+			edges { node { id pacienteId resultados { probNeumonia etiqueta } } }
+			pageInfo { hasNextPage }
+		}
+	}`, nil, &data)
+
+	if data.Cases.TotalCount != 2 || len(data.Cases.Edges) != 2 || data.Cases.PageInfo.HasNextPage {
+		t.Fatalf("se esperaban los 2 casos del paciente en una página, se obtuvo %+v", data.Cases)
+	}
+	// Orden por defecto: fecha de subida descendente
+	if data.Cases.Edges[0].Node.ID != pendiente || data.Cases.Edges[1].Node.ID != procesado {
+		t.Fatalf("orden inesperado: %+v", data.Cases.Edges)
+	}
+	for _, edge := range data.Cases.Edges {
+		if edge.Node.PacienteID != paciente.UserID {
+			t.Fatalf("el caso %s es del paciente %s", edge.Node.ID, edge.Node.PacienteID)
+		}
+	}
+	resultados := data.Cases.Edges[1].Node.Resultados
+	if resultados == nil || resultados.ProbNeumonia != 0.87 || resultados.Etiqueta != "Neumonía" {
+		t.Fatalf("resultados del caso procesado: %+v", resultados)
+	}
+}
+
+func TestCaseDetailConResultadoModelo(t *testing.T) {
+	e := nuevoEntorno(t)
+	paciente := e.crearUsuario(t, models.RolPaciente, "paciente@example.com")
+
+	subida := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	caso := e.prediagnostic.Service.AgregarCaso(prediagnostictest.Caso{
+		UserID: paciente.UserID, Estado: "completed", FechaSubida: subida, FechaProcesamiento: subida.Add(time.Minute),
+		Radiografia: "detalle.jpg", ProbNeumonia: 0.12, Etiqueta: "Normal",
+	})
+
+	var data struct {
+		CaseDetail struct {
+			ID            string
+			PreDiagnostic struct {
+				PacienteID       string
+				ResultadosModelo struct {
+					ProbNeumonia       float64
+					Etiqueta           string
+					FechaProcesamiento string
+				}
+			}
+			Diagnostic *struct{ ID string }
+		}
+	}
+	e.consultar(t, paciente, `query($id: ID!) {
+		caseDetail(id: $id) {
+			id
+			preDiagnostic { pacienteId resultadosModelo { probNeumonia etiqueta fechaProcesamiento } }
+			diagnostic { id }
+		}
+	}`, map[string]interface{}{"id": caso}, &data)
+
+	detalle := data.CaseDetail
+	if detalle.ID != caso || detalle.PreDiagnostic.PacienteID != paciente.UserID {
+		t.Fatalf("detalle inesperado: %+v", detalle)
+	}
+	resultados := detalle.PreDiagnostic.ResultadosModelo
+	if resultados.ProbNeumonia != 0.12 || resultados.Etiqueta != "Normal" || resultados.FechaProcesamiento == "" {
+		t.Fatalf("resultado_modelo no llegó completo: %+v", resultados)
+	}
+	if detalle.Diagnostic != nil {
+		t.Fatalf("el caso no tiene diagnóstico y se obtuvo %+v", detalle.Diagnostic)
+	}
+}
+
+func TestCreateDiagnostic(t *testing.T) {
+	e := nuevoEntorno(t)
+	paciente := e.crearUsuario(t, models.RolPaciente, "paciente@example.com")
+	doctor := e.crearUsuario(t, models.RolDoctor, "doctor@example.com")
+
+	caso := e.prediagnostic.Service.AgregarCaso(prediagnostictest.Caso{
+		UserID: paciente.UserID, Estado: "completed", FechaSubida: time.Now(),
+		Radiografia: "diagnostico.jpg", ProbNeumonia: 0.91, Etiqueta: "Neumonía",
+	})
+
+	const mutation = `mutation($id: ID!) {
+		createDiagnostic(id_prediagnostico: $id, input: {aprobacion: "Si", comentario: "Neumonía lobar derecha"}) {
+			success message diagnostic_id
+		}
+	}`
+	type respuesta struct {
+		CreateDiagnostic struct {
+			Success      bool
+			Message      string
+			DiagnosticID *string `json:"diagnostic_id"`
+		}
+	}
+
+	var primera respuesta
+	e.consultar(t, doctor, mutation, map[string]interface{}{"id": caso}, &primera)
+	if !primera.CreateDiagnostic.Success || primera.CreateDiagnostic.DiagnosticID == nil {
+		t.Fatalf("createDiagnostic falló: %+v", primera.CreateDiagnostic)
+	}
+
+	guardado, ok := e.prediagnostic.Service.Diagnostico(caso)
+	if !ok || guardado.ID != *primera.CreateDiagnostic.DiagnosticID ||
+		guardado.Validacion != "Si" || guardado.Diagnostico != "Neumonía lobar derecha" {
+		t.Fatalf("diagnóstico guardado en prediagnostic: %+v (existe: %v)", guardado, ok)
+	}
+
+	// Repetir la misma mutación reutiliza la clave de idempotencia y no crea
+	// otro diagnóstico
+	var segunda respuesta
+	e.consultar(t, doctor, mutation, map[string]interface{}{"id": caso}, &segunda)
+	if segunda.CreateDiagnostic.DiagnosticID == nil || *segunda.CreateDiagnostic.DiagnosticID != guardado.ID {
+		t.Fatalf("la repetición creó otro diagnóstico: %+v", segunda.CreateDiagnostic)
+	}
+
+	// El paciente ve el diagnóstico en el detalle del caso
+	var data struct {
+		CaseDetail struct {
+			Diagnostic *struct {
+				ID          string
+				Aprobacion  string
+				Comentarios string
+			}
+		}
+	}
+	e.consultar(t, paciente, `query($id: ID!) { caseDetail(id: $id) { diagnostic { id aprobacion comentarios } } }`,
+		map[string]interface{}{"id": caso}, &data)
+	diagnostico := data.CaseDetail.Diagnostic
+	if diagnostico == nil || diagnostico.ID != guardado.ID || diagnostico.Comentarios != "Neumonía lobar derecha" {
+		t.Fatalf("caseDetail no muestra el diagnóstico creado: %+v", diagnostico)
+	}
+}
+
+func TestCaseDetailSegunPermiso(t *testing.T) {
+	e := nuevoEntorno(t)
+	paciente := e.crearUsuario(t, models.RolPaciente, "paciente@example.com")
+	otro := e.crearUsuario(t, models.RolPaciente, "otro@example.com")
+	doctor := e.crearUsuario(t, models.RolDoctor, "doctor@example.com")
+
+	caso := e.prediagnostic.Service.AgregarCaso(prediagnostictest.Caso{
+		UserID: paciente.UserID, Estado: "completed", FechaSubida: time.Now(),
+		Radiografia: "permiso.jpg", ProbNeumonia: 0.4, Etiqueta: "Normal",
+	})
+	const query = `query($id: ID!) { caseDetail(id: $id) { id } }`
+	variables := map[string]interface{}{"id": caso}
+
+	casos := []struct {
+		nombre    string
+		claims    *services.UserClaims
+		permitido bool
+	}{
+		{"paciente dueño del caso", paciente, true},
+		{"doctor con case:read:any", doctor, true},
+		{"otro paciente", otro, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			errores := e.errores(t, c.claims, query, variables)
+			if c.permitido && len(errores) > 0 {
+				t.Fatalf("se esperaba acceso al caso, se obtuvo %v", errores)
+			}
+			if !c.permitido && len(errores) == 0 {
+				t.Fatal("se esperaba acceso denegado")
+			}
+		})
+	}
+}
@@ -8,8 +8,15 @@ import (
 )
 
 type Resolver struct {
-	PrediagnosticSrv *services.PreDiagnosticService
-	CaseSrv          *services.CaseService
-	AuthSrv          *services.AuthService
-	DiagnosticSrv    *services.DiagnosticService
+	PrediagnosticSrv   *services.PreDiagnosticService
+	CaseSrv            *services.CaseService
+	AuthSrv            *services.AuthService
+	DiagnosticSrv      *services.DiagnosticService
+	AdminSrv           *services.AdminService
+	DoctorSrv          *services.DoctorService
+	PermissionSrv      *services.PermissionService
+	ProfileSrv         *services.ProfileService
+	DataExportSrv      *services.DataExportService
+	AccountDeletionSrv *services.AccountDeletionService
+	ConsentSrv         *services.ConsentService
 }
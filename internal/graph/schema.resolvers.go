@@ -5,43 +5,55 @@ package graph
 // Code generated by github.com/99designs/gqlgen version v0.17.80
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/unobeswarch/businesslogic/internal/clients"
 	"github.com/unobeswarch/businesslogic/internal/graph/generated"
 	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"github.com/unobeswarch/businesslogic/internal/services"
 )
 
-// CreateDiagnostic is the resolver for the createDiagnostic field.
-func (r *mutationResolver) CreateDiagnostic(ctx context.Context, idPrediagnostico string, input model.DiagnosticInput) (*model.DiagnosticResponse, error) {
-	// Extraer token de autorización del contexto/headers
-	authHeader := ""
-	if authValue := ctx.Value("Authorization"); authValue != nil {
-		if authStr, ok := authValue.(string); ok {
-			authHeader = authStr
-		}
+// Paciente is the resolver for the paciente field.
+func (r *caseResolver) Paciente(ctx context.Context, obj *model.Case) (*model.User, error) {
+	usuario, err := services.LoadersFromContext(ctx).LoadUser(ctx, obj.PacienteID)
+	if err != nil || usuario == nil {
+		return nil, err
 	}
+	return toGraphUser(usuario), nil
+}
 
-	// Validar token y rol de doctor
-	userClaims, err := r.Resolver.AuthSrv.ValidateTokenAndRole(ctx, authHeader, "doctor")
-	if err != nil {
-		return &model.DiagnosticResponse{
-			Success: false,
-			Message: fmt.Sprintf("Acceso denegado: %v", err),
-		}, nil
+// Diagnostic is the resolver for the diagnostic field.
+func (r *caseResolver) Diagnostic(ctx context.Context, obj *model.Case) (*model.Diagnostic, error) {
+	if !casoValidado(obj.Estado) {
+		return nil, nil
+	}
+	return cargarDiagnostico(ctx, obj.ID), nil
+}
+
+// Diagnostic is the resolver for the diagnostic field.
+func (r *caseDetailResolver) Diagnostic(ctx context.Context, obj *model.CaseDetail) (*model.Diagnostic, error) {
+	if !casoValidado(obj.Estado) {
+		return nil, nil
 	}
+	return cargarDiagnostico(ctx, obj.ID), nil
+}
+
+// CreateDiagnostic is the resolver for the createDiagnostic field.
+func (r *mutationResolver) CreateDiagnostic(ctx context.Context, idPrediagnostico string, input model.DiagnosticInput) (*model.DiagnosticResponse, error) {
+	// @hasPermission(permission: "diagnostic:create") ya validó el token y el permiso
+	userClaims := usuarioAutenticado(ctx)
 
 	fmt.Printf("Doctor autorizado creando diagnóstico: %s (%s)\n", userClaims.Email, userClaims.UserID)
 
 	// Llamar al servicio de diagnóstico
-	result, err := r.Resolver.DiagnosticSrv.CreateDiagnostic(idPrediagnostico, input.Aprobacion, input.Comentario)
+	result, err := r.Resolver.DiagnosticSrv.CreateDiagnostic(ctx, idPrediagnostico, input.Aprobacion, input.Comentario)
 	if err != nil {
 		return &model.DiagnosticResponse{
 			Success: false,
@@ -58,82 +70,249 @@ func (r *mutationResolver) CreateDiagnostic(ctx context.Context, idPrediagnostic
 
 // UploadImage is the resolver for the uploadImage field.
 func (r *mutationResolver) UploadImage(ctx context.Context, imagen graphql.Upload) (bool, error) {
-	// Extraer token de autorización del contexto/headers
-	authHeader := ""
-	if authValue := ctx.Value("Authorization"); authValue != nil {
-		if authStr, ok := authValue.(string); ok {
-			authHeader = authStr
-		}
+	userClaims := usuarioAutenticado(ctx)
+
+	if err := r.Resolver.PrediagnosticSrv.UploadImage(ctx, userClaims.UserID, imagen.Filename, imagen.File); err != nil {
+		return false, err
 	}
 
-	userClaims, err := r.Resolver.AuthSrv.ValidateTokenAndRole(ctx, authHeader, "paciente")
+	return true, nil
+}
+
+// SetUserActive is the resolver for the setUserActive field.
+func (r *mutationResolver) SetUserActive(ctx context.Context, id string, activo bool) (*model.User, error) {
+	actorID, err := idUsuarioAutenticado(ctx)
 	if err != nil {
-		return false, fmt.Errorf("acceso denegado")
+		return nil, err
+	}
+	usuarioID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
 	}
 
-	if !strings.HasSuffix(strings.ToLower(imagen.Filename), ".jpg") {
-		return false, fmt.Errorf("solo se permiten archivos con extensión .jpg")
+	usuario, err := r.Resolver.AdminSrv.CambiarActivo(ctx, actorID, usuarioID, activo)
+	if err != nil {
+		return nil, err
 	}
+	return toGraphUser(usuario), nil
+}
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	_ = writer.WriteField("user_id", userClaims.UserID)
-	part, err := writer.CreateFormFile("imagen", imagen.Filename)
+// ChangeUserRole is the resolver for the changeUserRole field.
+func (r *mutationResolver) ChangeUserRole(ctx context.Context, id string, rol model.Role) (*model.User, error) {
+	actorID, err := idUsuarioAutenticado(ctx)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	_, err = io.Copy(part, imagen.File)
+	usuarioID, err := parseUserID(id)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	writer.Close()
+	usuario, err := r.Resolver.AdminSrv.CambiarRol(ctx, actorID, usuarioID, rolDesdeGraph(rol))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
 
-	req, err := http.NewRequest("POST", "http://localhost:8000/prediagnostic/process", body)
+// ApproveDoctor is the resolver for the approveDoctor field.
+func (r *mutationResolver) ApproveDoctor(ctx context.Context, id string) (*model.User, error) {
+	actorID, err := idUsuarioAutenticado(ctx)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	usuarioID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	usuario, err := r.Resolver.AdminSrv.AprobarDoctor(ctx, actorID, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+// RejectDoctor is the resolver for the rejectDoctor field.
+func (r *mutationResolver) RejectDoctor(ctx context.Context, id string, motivo string) (*model.User, error) {
+	actorID, err := idUsuarioAutenticado(ctx)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	usuarioID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
 	}
 
-	defer resp.Body.Close()
+	usuario, err := r.Resolver.AdminSrv.RechazarDoctor(ctx, actorID, usuarioID, motivo)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("Error asociado a prediagnostic: %s", resp.Status)
+// AddUserRole is the resolver for the addUserRole field.
+func (r *mutationResolver) AddUserRole(ctx context.Context, id string, rol model.Role) (*model.User, error) {
+	actorID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usuarioID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
 	}
 
+	usuario, err := r.Resolver.AdminSrv.AgregarRol(ctx, actorID, usuarioID, rolDesdeGraph(rol))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
+
+// RemoveUserRole is the resolver for the removeUserRole field.
+func (r *mutationResolver) RemoveUserRole(ctx context.Context, id string, rol model.Role) (*model.User, error) {
+	actorID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usuarioID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario, err := r.Resolver.AdminSrv.QuitarRol(ctx, actorID, usuarioID, rolDesdeGraph(rol))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
+
+// UpdateProfile is the resolver for the updateProfile field.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input model.ProfileInput) (*model.User, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario, err := r.Resolver.ProfileSrv.ActualizarPerfil(ctx, usuarioID, services.CambiosPerfil{
+		NombreCompleto: input.NombreCompleto,
+		Edad:           input.Edad,
+		Correo:         input.Correo,
+		Identificacion: input.Identificacion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
+
+// ChangePassword is the resolver for the changePassword field.
+func (r *mutationResolver) ChangePassword(ctx context.Context, contrasenaActual string, contrasenaNueva string) (bool, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.Resolver.ProfileSrv.CambiarContrasena(ctx, usuarioID, contrasenaActual, contrasenaNueva); err != nil {
+		return false, err
+	}
 	return true, nil
 }
 
+// ExportMyData is the resolver for the exportMyData field.
+func (r *mutationResolver) ExportMyData(ctx context.Context) (*model.DataExport, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exportacion, err := r.Resolver.DataExportSrv.SolicitarExportacion(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphDataExport(exportacion), nil
+}
+
+// DeleteMyAccount is the resolver for the deleteMyAccount field.
+func (r *mutationResolver) DeleteMyAccount(ctx context.Context, contrasena string) (*model.AccountDeletion, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	solicitud, err := r.Resolver.AccountDeletionSrv.SolicitarEliminacion(ctx, usuarioID, contrasena, models.MotivoEliminarCuenta)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphAccountDeletion(solicitud), nil
+}
+
+// WithdrawConsent is the resolver for the withdrawConsent field.
+func (r *mutationResolver) WithdrawConsent(ctx context.Context, contrasena string) (*model.AccountDeletion, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	solicitud, err := r.Resolver.AccountDeletionSrv.SolicitarEliminacion(ctx, usuarioID, contrasena, models.MotivoRetirarConsentimiento)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphAccountDeletion(solicitud), nil
+}
+
+// AcceptPrivacyPolicy is the resolver for the acceptPrivacyPolicy field.
+func (r *mutationResolver) AcceptPrivacyPolicy(ctx context.Context, version int) (*model.ConsentRecord, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consentimiento, err := r.Resolver.ConsentSrv.Aceptar(ctx, usuarioID, version, services.ClientIPFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphConsent(consentimiento), nil
+}
+
+// PublishPrivacyPolicy is the resolver for the publishPrivacyPolicy field.
+func (r *mutationResolver) PublishPrivacyPolicy(ctx context.Context, contenido string) (*model.PrivacyPolicy, error) {
+	actorID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	politica, err := r.Resolver.ConsentSrv.Publicar(ctx, actorID, contenido)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphPrivacyPolicy(politica), nil
+}
+
 // GetPreDiagnostic is the resolver for the getPreDiagnostic field.
 func (r *queryResolver) GetPreDiagnostic(ctx context.Context, id string) (*model.PreDiagnostic, error) {
-	fmt.Println("Buscando prediagnostic con ID:", id)
-	return r.Resolver.PrediagnosticSrv.GetPreDiagnosticByID(id)
+	preDiagnostic, err := services.LoadersFromContext(ctx).PreDiagnosticByID.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin prediagnostic:read:any solo se pueden ver los propios
+	recurso := &services.Recurso{PropietarioID: preDiagnostic.PacienteID}
+	if err := r.Resolver.PermissionSrv.Can(ctx, services.PermisoLeerPrediagnosticoPropio, recurso); err != nil {
+		return nil, fmt.Errorf("acceso denegado: el prediagnóstico no pertenece al usuario")
+	}
+	return preDiagnostic, nil
 }
 
 // GetCases is the resolver for the getCases field.
 func (r *queryResolver) GetCases(ctx context.Context) ([]*model.Case, error) {
-	// Extraer token de autorización del contexto/headers
-	authHeader := ""
-	if authValue := ctx.Value("Authorization"); authValue != nil {
-		if authStr, ok := authValue.(string); ok {
-			authHeader = authStr
-		}
-	}
+	userClaims := usuarioAutenticado(ctx)
 
-	// First try to validate as doctor
-	_, err := r.Resolver.AuthSrv.ValidateTokenAndRole(ctx, authHeader, "doctor")
-	if err == nil {
-		// User is a doctor - return all cases
-		cases, err := r.Resolver.CaseSrv.GetAllCases()
+	if r.Resolver.PermissionSrv.Can(ctx, services.PermisoLeerCualquierCaso, nil) == nil {
+		// case:read:any (doctor) - return all cases
+		cases, err := r.Resolver.CaseSrv.GetAllCases(ctx)
 		if err != nil {
-			if err.Error() == "no radiografias" {
+			if errors.Is(err, clients.ErrSinRadiografias) {
 				return []*model.Case{}, nil
 			}
 			return nil, fmt.Errorf("error obteniendo todos los casos: %w", err)
@@ -141,12 +320,7 @@ func (r *queryResolver) GetCases(ctx context.Context) ([]*model.Case, error) {
 		return cases, nil
 	}
 
-	// If not doctor, try to validate as patient
-	userClaims, err := r.Resolver.AuthSrv.ValidateTokenAndRole(ctx, authHeader, "paciente")
-	if err != nil {
-		return nil, fmt.Errorf("acceso denegado: %w", err)
-	}
-
+	// Otherwise @hasPermission guarantees case:read:own
 	userID := userClaims.UserID
 
 	// Validar que el usuario existe en la base relacional
@@ -159,9 +333,9 @@ func (r *queryResolver) GetCases(ctx context.Context) ([]*model.Case, error) {
 	}
 
 	// Consumir el endpoint del componente prediagnostic: GET /prediagnostic/cases/{user_id}
-	cases, err := r.Resolver.CaseSrv.GetCasesByUserID(userID)
+	cases, err := r.Resolver.CaseSrv.GetCasesByUserID(ctx, userID)
 	if err != nil {
-		if err.Error() == "no radiografias" {
+		if errors.Is(err, clients.ErrSinRadiografias) {
 			return nil, fmt.Errorf("usuario sin radiografias")
 		}
 		return nil, fmt.Errorf("error en conexión con prediagnostic: %w", err)
@@ -170,23 +344,41 @@ func (r *queryResolver) GetCases(ctx context.Context) ([]*model.Case, error) {
 	return cases, nil
 }
 
-// CaseDetail resolver - específico para HU7
-func (r *queryResolver) CaseDetail(ctx context.Context, id string) (*model.CaseDetail, error) {
-	// Extraer token de autorización del contexto/headers (same pattern as GetCases)
-	authHeader := ""
-	if authValue := ctx.Value("Authorization"); authValue != nil {
-		if authStr, ok := authValue.(string); ok {
-			authHeader = authStr
+// Cases is the resolver for the cases field.
+func (r *queryResolver) Cases(ctx context.Context, first *int, after *string, filter *model.CaseFilter, orderBy *model.CaseOrder) (*model.CaseConnection, error) {
+	filtro, err := filtroCasosDesdeGraph(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin case:read:any solo se listan los casos propios
+	if r.Resolver.PermissionSrv.Can(ctx, services.PermisoLeerCualquierCaso, nil) != nil {
+		if filtro.PacienteID == "" {
+			filtro.PacienteID = usuarioAutenticado(ctx).UserID
+		}
+		recurso := &services.Recurso{PropietarioID: filtro.PacienteID}
+		if err := r.Resolver.PermissionSrv.Can(ctx, services.PermisoLeerCasoPropio, recurso); err != nil {
+			return nil, fmt.Errorf("acceso denegado: solo puede consultar sus propios casos")
 		}
 	}
 
-	// Validar token y rol de paciente
-	userClaims, err := r.Resolver.AuthSrv.ValidateTokenAndRole(ctx, authHeader, "paciente")
+	cursor := ""
+	if after != nil {
+		cursor = *after
+	}
+
+	page, err := r.Resolver.CaseSrv.ListCases(ctx, filtro, ordenCasosDesdeGraph(orderBy), first, cursor)
 	if err != nil {
-		return nil, fmt.Errorf("acceso denegado: %w", err)
+		return nil, err
 	}
+	return toGraphCaseConnection(page), nil
+}
 
-	userID := userClaims.UserID
+// CaseDetail resolver - específico para HU7
+func (r *queryResolver) CaseDetail(ctx context.Context, id string) (*model.CaseDetail, error) {
+	// @hasPermission(permission: "case:read:own") ya validó el token y el
+	// permiso; el alcance se verifica al cargar el caso
+	userID := usuarioAutenticado(ctx).UserID
 
 	// Validar que el usuario existe en la base relacional
 	exists, err := r.Resolver.AuthSrv.UserExists(ctx, userID)
@@ -199,24 +391,167 @@ func (r *queryResolver) CaseDetail(ctx context.Context, id string) (*model.CaseD
 
 	// Llamar al CaseService para obtener detalles
 	// El service internamente:
-	// 1. Valida que el caso pertenezca al usuario
-	// 2. Llama REST APIs del servicio Python
-	// 3. Consolida datos de prediagnóstico + diagnóstico médico
-	caseDetail, err := r.Resolver.CaseSrv.GetCaseDetail(id, userID)
+	// 1. Llama REST APIs del servicio Python
+	// 2. Consolida datos de prediagnóstico + diagnóstico médico
+	caseDetail, err := r.Resolver.CaseSrv.GetCaseDetail(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo detalle del caso: %w", err)
 	}
 
+	// Sin case:read:any solo se pueden ver los propios
+	recurso := &services.Recurso{PropietarioID: caseDetail.PreDiagnostic.PacienteID}
+	if err := r.Resolver.PermissionSrv.Can(ctx, services.PermisoLeerCasoPropio, recurso); err != nil {
+		return nil, fmt.Errorf("acceso denegado: caso no pertenece al usuario")
+	}
+
 	return caseDetail, nil
 }
 
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, filter *model.UserFilter, limit *int, offset *int) (*model.UserPage, error) {
+	filtro := repository.UserFilter{}
+	if filter != nil {
+		if filter.Busqueda != nil {
+			filtro.Busqueda = strings.TrimSpace(*filter.Busqueda)
+		}
+		if filter.Rol != nil {
+			filtro.Rol = rolDesdeGraph(*filter.Rol)
+		}
+		if filter.Estado != nil {
+			filtro.Estado = estadoDesdeGraph(*filter.Estado)
+		}
+	}
+	if limit != nil {
+		filtro.Limit = *limit
+	}
+	if offset != nil {
+		filtro.Offset = *offset
+	}
+
+	usuarios, total, err := r.Resolver.AdminSrv.ListarUsuarios(ctx, filtro)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.UserPage{Usuarios: make([]*model.User, 0, len(usuarios)), Total: total}
+	for _, u := range usuarios {
+		page.Usuarios = append(page.Usuarios, toGraphUser(u))
+	}
+	return page, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error) {
+	usuarioID, err := parseUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario, err := r.Resolver.AdminSrv.ObtenerUsuario(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, services.ErrUsuarioNoEncontrado) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario, err := r.Resolver.ProfileSrv.ObtenerPerfil(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphUser(usuario), nil
+}
+
+// MyDataExport is the resolver for the myDataExport field.
+func (r *queryResolver) MyDataExport(ctx context.Context, id string) (*model.DataExport, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exportacion, err := r.Resolver.DataExportSrv.ObtenerExportacion(ctx, usuarioID, id)
+	if err != nil {
+		if errors.Is(err, services.ErrExportacionNoEncontrada) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toGraphDataExport(exportacion), nil
+}
+
+// PrivacyPolicy is the resolver for the privacyPolicy field.
+func (r *queryResolver) PrivacyPolicy(ctx context.Context) (*model.PrivacyPolicy, error) {
+	politica, err := r.Resolver.ConsentSrv.PoliticaVigente(ctx)
+	if err != nil {
+		if errors.Is(err, services.ErrSinPoliticaVigente) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toGraphPrivacyPolicy(politica), nil
+}
+
+// MyConsents is the resolver for the myConsents field.
+func (r *queryResolver) MyConsents(ctx context.Context) ([]*model.ConsentRecord, error) {
+	usuarioID, err := idUsuarioAutenticado(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	historial, err := r.Resolver.ConsentSrv.Historial(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	consentimientos := make([]*model.ConsentRecord, 0, len(historial))
+	for _, c := range historial {
+		consentimientos = append(consentimientos, toGraphConsent(c))
+	}
+	return consentimientos, nil
+}
+
+// DoctorProfile is the resolver for the doctorProfile field.
+func (r *userResolver) DoctorProfile(ctx context.Context, obj *model.User) (*model.DoctorProfile, error) {
+	if !slices.Contains(obj.Roles, model.RoleDoctor) {
+		return nil, nil
+	}
+	usuarioID, err := parseUserID(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	perfil, err := r.Resolver.DoctorSrv.ObtenerPerfil(ctx, usuarioID)
+	if err != nil || perfil == nil {
+		return nil, err
+	}
+	return toGraphDoctorProfile(perfil), nil
+}
+
+// Case returns generated.CaseResolver implementation.
+func (r *Resolver) Case() generated.CaseResolver { return &caseResolver{r} }
+
+// CaseDetail returns generated.CaseDetailResolver implementation.
+func (r *Resolver) CaseDetail() generated.CaseDetailResolver { return &caseDetailResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
-type (
-	mutationResolver struct{ *Resolver }
-	queryResolver    struct{ *Resolver }
-)
+// User returns generated.UserResolver implementation.
+func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
+
+type caseResolver struct{ *Resolver }
+type caseDetailResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// AccountDeletionHandler expone la cancelación de una eliminación de cuenta
+// con el enlace enviado por correo. La solicitud se hace por GraphQL
+// (deleteMyAccount y withdrawConsent).
+type AccountDeletionHandler struct {
+	accountDeletionService *services.AccountDeletionService
+}
+
+func NewAccountDeletionHandler(accountDeletionService *services.AccountDeletionService) *AccountDeletionHandler {
+	return &AccountDeletionHandler{accountDeletionService: accountDeletionService}
+}
+
+// HandlerCancelarEliminacion responde POST /account/deletion/cancel
+func (h *AccountDeletionHandler) HandlerCancelarEliminacion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || datos.Token == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "token es requerido",
+		})
+		return
+	}
+
+	if err := h.accountDeletionService.CancelarEliminacion(r.Context(), datos.Token); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		switch err {
+		case services.ErrTokenCancelacionInvalido:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INVALID_CANCELLATION_TOKEN",
+				"mensaje": "El enlace es inválido, expiró o la cuenta ya fue eliminada",
+			})
+		default:
+			fmt.Printf("Error específico durante cancelación de eliminación: %v\n", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INTERNAL_ERROR",
+				"mensaje": "Error interno del servidor",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "La eliminación de la cuenta fue cancelada; ya puedes iniciar sesión",
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// AuthContextMiddleware valida una sola vez por petición el header
+// Authorization y deja en el contexto los claims del access token (o el error
+// de validación). Las directivas @auth y @hasRole de GraphQL los leen con
+// services.ClaimsFromContext; una petición sin token sigue su curso para las
+// operaciones públicas.
+// Para un usuario autenticado también comprueba si aceptó la versión vigente
+// de la política de tratamiento de datos; las directivas rechazan con
+// CONSENT_REQUIRED las operaciones que lo exigen.
+func AuthContextMiddleware(authService *services.AuthService, consentService *services.ConsentService, trustForwardedFor bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := services.ContextWithClientIP(r.Context(), clientIP(r, trustForwardedFor))
+
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			claims, err := authService.Autenticar(ctx, authHeader)
+			if err != nil {
+				ctx = services.ContextWithAuthError(ctx, err)
+			} else if usuarioID, err := strconv.Atoi(claims.UserID); err != nil {
+				ctx = services.ContextWithAuthError(ctx, err)
+			} else if err := consentService.Comprobar(ctx, usuarioID); err != nil {
+				ctx = services.ContextWithConsentError(ctx, claims, err)
+			} else {
+				ctx = services.ContextWithClaims(ctx, claims)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
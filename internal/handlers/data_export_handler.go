@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// DataExportHandler entrega el ZIP de datos personales generado con la
+// mutación exportMyData de GraphQL
+type DataExportHandler struct {
+	authService       *services.AuthService
+	dataExportService *services.DataExportService
+}
+
+func NewDataExportHandler(authService *services.AuthService, dataExportService *services.DataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		authService:       authService,
+		dataExportService: dataExportService,
+	}
+}
+
+// HandlerDescargarExportacion responde GET /data-export/download?id=. Solo el
+// titular de la exportación puede descargarla, con su access token.
+func (h *DataExportHandler) HandlerDescargarExportacion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var usuarioID int
+	claims, err := h.authService.Autenticar(r.Context(), r.Header.Get("Authorization"))
+	if err == nil {
+		usuarioID, err = strconv.Atoi(claims.UserID)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "UNAUTHORIZED",
+			"mensaje": "Se requiere un access token válido",
+		})
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	archivo, err := h.dataExportService.Descargar(r.Context(), usuarioID, id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case errors.Is(err, services.ErrExportacionNoEncontrada):
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "EXPORT_NOT_FOUND",
+				"mensaje": "La exportación no existe",
+			})
+		case errors.Is(err, services.ErrExportacionNoDisponible):
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "EXPORT_NOT_READY",
+				"mensaje": "La exportación aún no termina, falló o ya expiró",
+			})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INTERNAL_ERROR",
+				"mensaje": "Error obteniendo la exportación",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="datos-personales-%s.zip"`, id))
+	w.Header().Set("Content-Length", strconv.Itoa(len(archivo)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(archivo)
+}
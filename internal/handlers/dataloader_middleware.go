@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// DataLoaderMiddleware deja en el contexto de cada petición un juego nuevo de
+// dataloaders (services.LoadersFromContext). Así los resolvers de campo de
+// una misma consulta agrupan sus cargas, y nada de lo cargado se comparte
+// entre peticiones ni entre usuarios.
+func DataLoaderMiddleware(users repository.UserRepository, caseService *services.CaseService, prediagnosticService *services.PreDiagnosticService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			loaders := services.NewLoaders(users, caseService, prediagnosticService)
+			next.ServeHTTP(w, r.WithContext(services.ContextWithLoaders(r.Context(), loaders)))
+		})
+	}
+}
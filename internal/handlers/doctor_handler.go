@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// DoctorHandler expone la solicitud de cuenta de doctor
+type DoctorHandler struct {
+	doctorService       *services.DoctorService
+	verificationService *services.VerificationService
+	consentService      *services.ConsentService
+	trustForwardedFor   bool
+}
+
+func NewDoctorHandler(doctorService *services.DoctorService, verificationService *services.VerificationService, consentService *services.ConsentService, trustForwardedFor bool) *DoctorHandler {
+	return &DoctorHandler{
+		doctorService:       doctorService,
+		verificationService: verificationService,
+		consentService:      consentService,
+		trustForwardedFor:   trustForwardedFor,
+	}
+}
+
+// HandlerRegistrarDoctor responde POST /register/doctor: los mismos datos de
+// /register más numero_licencia y especialidad. La cuenta queda pendiente de
+// aprobación por un administrador.
+func (h *DoctorHandler) HandlerRegistrarDoctor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		models.User
+		NumeroLicencia string `json:"numero_licencia"`
+		Especialidad   string `json:"especialidad"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "JSON inválido",
+		})
+		return
+	}
+
+	id, fecha, err := h.doctorService.RegistrarSolicitud(r.Context(), datos.User, models.DoctorProfile{
+		NumeroLicencia: datos.NumeroLicencia,
+		Especialidad:   datos.Especialidad,
+	})
+	if err != nil {
+		escribirErrorRegistro(w, err)
+		return
+	}
+
+	if err := h.consentService.AceptarVigente(r.Context(), id, clientIP(r, h.trustForwardedFor)); err != nil {
+		fmt.Printf("Error registrando el consentimiento del usuario %d: %v\n", id, err)
+	}
+
+	// Si el envío falla el usuario puede pedir otro enlace en /verify-email/resend
+	if err := h.verificationService.EnviarVerificacion(r.Context(), id); err != nil {
+		fmt.Printf("Error enviando verificación de correo al usuario %d: %v\n", id, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":                id,
+		"mensaje":           "Solicitud registrada. Verifica tu correo; un administrador revisará tu licencia antes de activar la cuenta",
+		"fecha_registro":    fecha,
+		"estado":            models.EstadoPendienteAprobacion,
+		"correo_verificado": false,
+	})
+}
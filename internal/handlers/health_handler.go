@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+)
+
+// timeoutPingBaseDatos limita la verificación de PostgreSQL en /health
+const timeoutPingBaseDatos = 2 * time.Second
+
+// HealthHandler expone el estado del servicio y sus dependencias para el
+// balanceador y el monitoreo
+type HealthHandler struct {
+	db            *sql.DB
+	prediagnostic *clients.PreDiagnosticClient
+}
+
+func NewHealthHandler(db *sql.DB, prediagnostic *clients.PreDiagnosticClient) *HealthHandler {
+	return &HealthHandler{db: db, prediagnostic: prediagnostic}
+}
+
+// HandlerHealth responde GET /health. Sin PostgreSQL el servicio no
+// funciona (503); con el circuito de prediagnostic abierto sigue atendiendo
+// login y cuentas, así que responde 200 con estado "degradado".
+func (h *HealthHandler) HandlerHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	estado := "ok"
+	status := http.StatusOK
+
+	baseDatos := map[string]interface{}{"estado": "ok"}
+	ctx, cancel := context.WithTimeout(r.Context(), timeoutPingBaseDatos)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		baseDatos = map[string]interface{}{"estado": "error", "error": err.Error()}
+		estado = "error"
+		status = http.StatusServiceUnavailable
+	}
+
+	circuito := h.prediagnostic.Estado().Circuito
+	prediagnostic := map[string]interface{}{
+		"estado":          "ok",
+		"circuito":        circuito.Estado,
+		"fallos_seguidos": circuito.FallosSeguidos,
+	}
+	if circuito.Estado != clients.CircuitoCerrado {
+		prediagnostic["estado"] = "degradado"
+		if estado == "ok" {
+			estado = "degradado"
+		}
+	}
+	if circuito.Estado == clients.CircuitoAbierto {
+		prediagnostic["abierto_hasta"] = circuito.AbiertoHasta.UTC().Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"estado": estado,
+		"componentes": map[string]interface{}{
+			"base_datos":    baseDatos,
+			"prediagnostic": prediagnostic,
+		},
+	})
+}
+
+// HandlerMetrics responde GET /metrics con los contadores del cliente de
+// prediagnostic en el formato de texto de Prometheus
+func (h *HealthHandler) HandlerMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	e := h.prediagnostic.Estado()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	contador := func(nombre, ayuda string, valor uint64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", nombre, ayuda, nombre, nombre, valor)
+	}
+	contador("prediagnostic_requests_total", "Peticiones enviadas al servicio prediagnostic, incluidos los reintentos.", e.Peticiones)
+	contador("prediagnostic_request_failures_total", "Peticiones a prediagnostic con error de conexión, timeout o respuesta 5xx.", e.Fallos)
+	contador("prediagnostic_retries_total", "Reintentos de consultas a prediagnostic.", e.Reintentos)
+	contador("prediagnostic_rejected_total", "Peticiones rechazadas sin enviarlas porque el circuito estaba abierto.", e.Rechazadas)
+	contador("prediagnostic_circuit_opens_total", "Veces que el circuito de prediagnostic pasó a abierto.", e.Circuito.Aperturas)
+
+	fmt.Fprint(w, "# HELP prediagnostic_circuit_state Estado actual del circuito de prediagnostic (1 en el estado vigente).\n# TYPE prediagnostic_circuit_state gauge\n")
+	for _, estado := range []string{clients.CircuitoCerrado, clients.CircuitoSemiabierto, clients.CircuitoAbierto} {
+		valor := 0
+		if e.Circuito.Estado == estado {
+			valor = 1
+		}
+		fmt.Fprintf(w, "prediagnostic_circuit_state{estado=%q} %d\n", estado, valor)
+	}
+	fmt.Fprintf(w, "# HELP prediagnostic_circuit_consecutive_failures Fallos seguidos registrados por el circuito de prediagnostic.\n# TYPE prediagnostic_circuit_consecutive_failures gauge\nprediagnostic_circuit_consecutive_failures %d\n", e.Circuito.FallosSeguidos)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// JWKSHandler publica las claves públicas con las que otros microservicios
+// (prediagnostic, BFF del frontend) verifican los tokens emitidos aquí.
+type JWKSHandler struct {
+	keys *services.KeyManager
+}
+
+func NewJWKSHandler(keys *services.KeyManager) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// jwk representa una clave pública en formato JSON Web Key (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// HandlerJWKS responde GET /.well-known/jwks.json
+func (h *JWKSHandler) HandlerJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	keys := []jwk{}
+	for _, key := range h.keys.PublishedKeys() {
+		k := jwk{Use: "sig", Alg: key.Algorithm, Kid: key.ID}
+		switch pub := key.Public().(type) {
+		case *rsa.PublicKey:
+			k.Kty = "RSA"
+			k.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			k.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			// Formato sin comprimir: 0x04 || X || Y
+			point, err := pub.Bytes()
+			if err != nil {
+				continue
+			}
+			size := (len(point) - 1) / 2
+			k.Kty = "EC"
+			k.Crv = pub.Curve.Params().Name
+			k.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+			k.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+		default:
+			continue
+		}
+		keys = append(keys, k)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Los verificadores pueden cachear el JWKS; las claves nuevas se publican antes de usarse
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(services.JWKSCacheMaxAge.Seconds())))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": keys,
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// LockoutHandler expone el desbloqueo de cuentas con el enlace enviado por correo
+type LockoutHandler struct {
+	lockoutService *services.LockoutService
+}
+
+func NewLockoutHandler(lockoutService *services.LockoutService) *LockoutHandler {
+	return &LockoutHandler{lockoutService: lockoutService}
+}
+
+// HandlerDesbloquearCuenta responde POST /auth/unlock
+func (h *LockoutHandler) HandlerDesbloquearCuenta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || datos.Token == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "token es requerido",
+		})
+		return
+	}
+
+	if err := h.lockoutService.DesbloquearConToken(r.Context(), datos.Token); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		switch err {
+		case services.ErrTokenDesbloqueoInvalido:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INVALID_UNLOCK_TOKEN",
+				"mensaje": "El enlace de desbloqueo es inválido, expiró o ya fue usado",
+			})
+		default:
+			fmt.Printf("Error específico durante desbloqueo de cuenta: %v\n", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INTERNAL_ERROR",
+				"mensaje": "Error interno del servidor",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "Cuenta desbloqueada exitosamente",
+	})
+}
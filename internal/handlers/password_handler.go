@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// PasswordHandler expone la recuperación de contraseña por correo
+type PasswordHandler struct {
+	passwordService *services.PasswordService
+}
+
+func NewPasswordHandler(passwordService *services.PasswordService) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService}
+}
+
+// HandlerOlvidoContrasena responde POST /password/forgot. La respuesta es la
+// misma exista o no la cuenta, para no revelar qué correos están registrados.
+func (h *PasswordHandler) HandlerOlvidoContrasena(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		Correo string `json:"correo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || strings.TrimSpace(datos.Correo) == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "correo es requerido",
+		})
+		return
+	}
+
+	if err := h.passwordService.SolicitarRestablecimiento(r.Context(), strings.TrimSpace(datos.Correo)); err != nil {
+		fmt.Printf("Error específico durante solicitud de restablecimiento: %v\n", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "INTERNAL_ERROR",
+			"mensaje": "Error interno del servidor",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "Si el correo está registrado recibirás un enlace para restablecer tu contraseña",
+	})
+}
+
+// HandlerRestablecerContrasena responde POST /password/reset
+func (h *PasswordHandler) HandlerRestablecerContrasena(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		Token      string `json:"token"`
+		Contrasena string `json:"contrasena"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || datos.Token == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "token y contrasena son requeridos",
+		})
+		return
+	}
+
+	err := h.passwordService.RestablecerContrasena(r.Context(), datos.Token, datos.Contrasena)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		switch err {
+		case services.ErrDatosEnviados:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "VALIDATION_ERROR",
+				"mensaje": "La contraseña debe tener al menos 8 caracteres",
+			})
+		case services.ErrTokenRestablecimientoInvalido:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INVALID_RESET_TOKEN",
+				"mensaje": "El enlace de restablecimiento es inválido, expiró o ya fue usado",
+			})
+		default:
+			fmt.Printf("Error específico durante restablecimiento de contraseña: %v\n", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INTERNAL_ERROR",
+				"mensaje": "Error interno del servidor",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "Contraseña restablecida exitosamente; inicia sesión nuevamente",
+	})
+}
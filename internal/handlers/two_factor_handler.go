@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// TwoFactorHandler expone el segundo paso del login y la administración del
+// segundo factor TOTP
+type TwoFactorHandler struct {
+	authService       *services.AuthService
+	twoFactorService  *services.TwoFactorService
+	trustForwardedFor bool
+}
+
+func NewTwoFactorHandler(authService *services.AuthService, twoFactorService *services.TwoFactorService, trustForwardedFor bool) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authService:       authService,
+		twoFactorService:  twoFactorService,
+		trustForwardedFor: trustForwardedFor,
+	}
+}
+
+// datosSegundoFactor es el body común de los endpoints /auth/2fa*
+type datosSegundoFactor struct {
+	ChallengeToken string `json:"challenge_token"`
+	Codigo         string `json:"codigo"`
+}
+
+// HandlerVerificarSegundoFactor responde POST /auth/2fa: canjea el
+// challenge_token de /auth más un código TOTP o de recuperación por la sesión
+func (h *TwoFactorHandler) HandlerVerificarSegundoFactor(w http.ResponseWriter, r *http.Request) {
+	datos, ok := leerDatosSegundoFactor(w, r)
+	if !ok {
+		return
+	}
+	if datos.ChallengeToken == "" || datos.Codigo == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "challenge_token y codigo son requeridos",
+		})
+		return
+	}
+
+	usuario, tokens, err := h.authService.CompletarSegundoFactor(r.Context(), datos.ChallengeToken, datos.Codigo, clientIP(r, h.trustForwardedFor))
+	if err != nil {
+		escribirErrorSegundoFactor(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sesionResponse(usuario, tokens))
+}
+
+// HandlerEnrolar responde POST /auth/2fa/enroll. Acepta un access token o, si
+// el rol exige 2FA y aún no se configuró, el challenge_token de /auth.
+func (h *TwoFactorHandler) HandlerEnrolar(w http.ResponseWriter, r *http.Request) {
+	datos, ok := leerDatosSegundoFactor(w, r)
+	if !ok {
+		return
+	}
+	usuarioID, ok := h.usuarioSolicitante(w, r, datos.ChallengeToken)
+	if !ok {
+		return
+	}
+
+	enrolamiento, err := h.twoFactorService.Enrolar(r.Context(), usuarioID)
+	if err != nil {
+		escribirErrorSegundoFactor(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"secreto":     enrolamiento.Secreto,
+		"otpauth_uri": enrolamiento.URI,
+		"mensaje":     "Escanea el código QR con tu app autenticadora y confirma con un código",
+	})
+}
+
+// HandlerConfirmar responde POST /auth/2fa/confirm: activa el segundo factor
+// con un código de la app y entrega los códigos de recuperación
+func (h *TwoFactorHandler) HandlerConfirmar(w http.ResponseWriter, r *http.Request) {
+	datos, ok := leerDatosSegundoFactor(w, r)
+	if !ok {
+		return
+	}
+	usuarioID, ok := h.usuarioSolicitante(w, r, datos.ChallengeToken)
+	if !ok {
+		return
+	}
+
+	codigos, err := h.twoFactorService.Confirmar(r.Context(), usuarioID, datos.Codigo)
+	if err != nil {
+		escribirErrorSegundoFactor(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"codigos_recuperacion": codigos,
+		"mensaje":              "Segundo factor activado. Guarda los códigos de recuperación: no se volverán a mostrar",
+	})
+}
+
+// HandlerDesactivar responde POST /auth/2fa/disable (requiere access token)
+func (h *TwoFactorHandler) HandlerDesactivar(w http.ResponseWriter, r *http.Request) {
+	datos, ok := leerDatosSegundoFactor(w, r)
+	if !ok {
+		return
+	}
+	usuarioID, ok := h.usuarioSolicitante(w, r, "")
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Desactivar(r.Context(), usuarioID, datos.Codigo); err != nil {
+		escribirErrorSegundoFactor(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "Segundo factor desactivado",
+	})
+}
+
+// HandlerRegenerarCodigos responde POST /auth/2fa/recovery-codes (requiere access token)
+func (h *TwoFactorHandler) HandlerRegenerarCodigos(w http.ResponseWriter, r *http.Request) {
+	datos, ok := leerDatosSegundoFactor(w, r)
+	if !ok {
+		return
+	}
+	usuarioID, ok := h.usuarioSolicitante(w, r, "")
+	if !ok {
+		return
+	}
+
+	codigos, err := h.twoFactorService.RegenerarCodigosRecuperacion(r.Context(), usuarioID, datos.Codigo)
+	if err != nil {
+		escribirErrorSegundoFactor(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"codigos_recuperacion": codigos,
+	})
+}
+
+// usuarioSolicitante identifica al usuario por el access token del header
+// Authorization o, si no viene, por el challenge_token. Si ninguno es válido
+// escribe 401 y retorna false.
+func (h *TwoFactorHandler) usuarioSolicitante(w http.ResponseWriter, r *http.Request, challengeToken string) (int, bool) {
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		claims, err := h.authService.ValidateJWT(r.Context(), strings.TrimPrefix(authHeader, "Bearer "))
+		if err == nil {
+			if id, err := strconv.Atoi(claims.UserID); err == nil {
+				return id, true
+			}
+		}
+	} else if challengeToken != "" {
+		usuario, _, err := h.twoFactorService.UsuarioDeDesafio(r.Context(), challengeToken)
+		if err == nil {
+			return usuario.ID, true
+		}
+		if err != services.ErrDesafioInvalido {
+			escribirErrorSegundoFactor(w, err)
+			return 0, false
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   "UNAUTHORIZED",
+		"mensaje": "Se requiere un access token o un challenge_token válido",
+	})
+	return 0, false
+}
+
+func leerDatosSegundoFactor(w http.ResponseWriter, r *http.Request) (datosSegundoFactor, bool) {
+	var datos datosSegundoFactor
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return datos, false
+	}
+
+	// El body puede venir vacío en /auth/2fa/enroll con access token
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil && !errors.Is(err, io.EOF) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "JSON inválido",
+		})
+		return datos, false
+	}
+	return datos, true
+}
+
+func escribirErrorSegundoFactor(w http.ResponseWriter, err error) {
+	var bloqueo *services.BloqueoError
+	if errors.As(err, &bloqueo) {
+		escribirErrorBloqueo(w, bloqueo)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch err {
+	case services.ErrCodigoSegundoFactorInvalido:
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "INVALID_TWO_FACTOR_CODE",
+			"mensaje": "El código es incorrecto o ya fue usado",
+		})
+	case services.ErrDesafioInvalido:
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "INVALID_CHALLENGE_TOKEN",
+			"mensaje": "El desafío expiró o ya fue usado; inicia sesión nuevamente",
+		})
+	case services.ErrSegundoFactorObligatorio:
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "TWO_FACTOR_REQUIRED",
+			"mensaje": "Tu rol requiere segundo factor: configúralo con /auth/2fa/enroll",
+		})
+	case services.ErrSegundoFactorYaActivo:
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "TWO_FACTOR_ALREADY_ENABLED",
+			"mensaje": "El segundo factor ya está activo",
+		})
+	case services.ErrSegundoFactorNoActivo:
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "TWO_FACTOR_NOT_ENABLED",
+			"mensaje": "No hay un segundo factor activo o pendiente de confirmar",
+		})
+	default:
+		fmt.Printf("Error específico en segundo factor: %v\n", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "INTERNAL_ERROR",
+			"mensaje": "Error interno del servidor",
+		})
+	}
+}
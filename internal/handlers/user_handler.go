@@ -2,15 +2,37 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/unobeswarch/businesslogic/internal/models"
 	"github.com/unobeswarch/businesslogic/internal/services"
 )
 
-func HandlerRegistrarUsuario(w http.ResponseWriter, r *http.Request) {
+// UserHandler agrupa los endpoints REST de registro, login y validación de tokens
+type UserHandler struct {
+	authService         *services.AuthService
+	verificationService *services.VerificationService
+	consentService      *services.ConsentService
+	trustForwardedFor   bool
+}
+
+func NewUserHandler(authService *services.AuthService, verificationService *services.VerificationService, consentService *services.ConsentService, trustForwardedFor bool) *UserHandler {
+	return &UserHandler{
+		authService:         authService,
+		verificationService: verificationService,
+		consentService:      consentService,
+		trustForwardedFor:   trustForwardedFor,
+	}
+}
+
+func (h *UserHandler) HandlerRegistrarUsuario(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -31,52 +53,81 @@ func HandlerRegistrarUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, fecha, err := services.RegistrarUsuario(usuario)
+	id, fecha, err := h.authService.RegistrarUsuario(r.Context(), usuario)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		switch err {
-		case services.ErrDatosEnviados:
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error":   "VALIDATION_ERROR",
-				"mensaje": "Datos de entrada inválidos",
-			})
-		case services.ErrUsuarioExistente:
-			w.WriteHeader(http.StatusConflict)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error":   "USER_ALREADY_EXISTS",
-				"mensaje": "Ya existe un usuario con este correo o identificación",
-			})
-		case services.ErrTratamientoDatos:
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error":   "BUSINESS_RULE_VIOLATION",
-				"mensaje": "Debe aceptar el tratamiento de datos personales",
-			})
-		default:
-			// Log del error específico para depuración
-			fmt.Printf("Error específico durante registro: %v\n", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error":         "INTERNAL_ERROR",
-				"mensaje":       "Error interno del servidor",
-				"codigo_error":  "REG_001",
-				"error_detalle": err.Error(),
-			})
-		}
+		escribirErrorRegistro(w, err)
 		return
 	}
 
+	// El registro exige aceptar la política; si no queda registrada, se le
+	// pedirá aceptarla en la primera operación de GraphQL
+	if err := h.consentService.AceptarVigente(r.Context(), id, clientIP(r, h.trustForwardedFor)); err != nil {
+		fmt.Printf("Error registrando el consentimiento del usuario %d: %v\n", id, err)
+	}
+
+	// Si el envío falla el usuario puede pedir otro enlace en /verify-email/resend
+	if err := h.verificationService.EnviarVerificacion(r.Context(), id); err != nil {
+		fmt.Printf("Error enviando verificación de correo al usuario %d: %v\n", id, err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":             id,
-		"mensaje":        "Usuario registrado exitosamente",
-		"fecha_registro": fecha,
+		"id":                id,
+		"mensaje":           "Usuario registrado exitosamente. Revisa tu correo para verificar la cuenta",
+		"fecha_registro":    fecha,
+		"correo_verificado": false,
 	})
 }
 
-func HandlerIniciarSesion(w http.ResponseWriter, r *http.Request) {
+// escribirErrorRegistro responde los errores de /register y /register/doctor
+func escribirErrorRegistro(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	switch err {
+	case services.ErrDatosEnviados:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "Datos de entrada inválidos",
+		})
+	case services.ErrUsuarioExistente:
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "USER_ALREADY_EXISTS",
+			"mensaje": "Ya existe un usuario con este correo o identificación",
+		})
+	case services.ErrTratamientoDatos:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "BUSINESS_RULE_VIOLATION",
+			"mensaje": "Debe aceptar el tratamiento de datos personales",
+		})
+	case services.ErrRolNoPermitido:
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "ROLE_NOT_ALLOWED",
+			"mensaje": "En /register solo se registran pacientes; los doctores usan /register/doctor",
+		})
+	case services.ErrLicenciaExistente:
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "LICENSE_ALREADY_REGISTERED",
+			"mensaje": "Ya existe una cuenta con este número de licencia",
+		})
+	default:
+		// Log del error específico para depuración
+		fmt.Printf("Error específico durante registro: %v\n", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":         "INTERNAL_ERROR",
+			"mensaje":       "Error interno del servidor",
+			"codigo_error":  "REG_001",
+			"error_detalle": err.Error(),
+		})
+	}
+}
+
+func (h *UserHandler) HandlerIniciarSesion(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -98,7 +149,59 @@ func HandlerIniciarSesion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	nombre, id, rol, token, err := services.IniciarSesion(datos["correo"].(string), datos["contrasena"].(string))
+	ip := clientIP(r, h.trustForwardedFor)
+	usuario, tokens, desafio, err := h.authService.IniciarSesion(r.Context(), datos["correo"].(string), datos["contrasena"].(string), ip)
+
+	var bloqueo *services.BloqueoError
+	if errors.As(err, &bloqueo) {
+		escribirErrorBloqueo(w, bloqueo)
+		return
+	}
+	if err == services.ErrCuentaDesactivada {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "ACCOUNT_DISABLED",
+			"mensaje": "La cuenta está desactivada",
+		})
+		return
+	}
+	if err == services.ErrCuentaPendienteAprobacion {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "ACCOUNT_PENDING_APPROVAL",
+			"mensaje": "La cuenta está pendiente de aprobación por un administrador",
+		})
+		return
+	}
+	if err == services.ErrCuentaEnEliminacion {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "ACCOUNT_DELETION_PENDING",
+			"mensaje": "La cuenta tiene una eliminación pendiente; usa el enlace enviado por correo para cancelarla",
+		})
+		return
+	}
+	if err == services.ErrSolicitudRechazada {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "DOCTOR_APPLICATION_REJECTED",
+			"mensaje": "Tu solicitud de cuenta de doctor fue rechazada",
+		})
+		return
+	}
+	if err == services.ErrCorreoNoVerificado {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "EMAIL_NOT_VERIFIED",
+			"mensaje": "Debes verificar tu correo antes de iniciar sesión",
+		})
+		return
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -108,18 +211,183 @@ func HandlerIniciarSesion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Segundo paso: el cliente debe enviar el código a /auth/2fa con challenge_token
+	if desafio != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"requiere_2fa":           true,
+			"challenge_token":        desafio.Token,
+			"expires_in":             desafio.ExpiresIn,
+			"enrolamiento_requerido": desafio.Enrolamiento,
+			"user_id":                usuario.ID,
+			"rol":                    usuario.Rol,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sesionResponse(usuario, tokens))
+}
+
+// escribirErrorBloqueo responde 429 con Retry-After cuando el login está bloqueado
+func escribirErrorBloqueo(w http.ResponseWriter, bloqueo *services.BloqueoError) {
+	segundos := int(math.Ceil(time.Until(bloqueo.Hasta).Seconds()))
+	mensaje := "Demasiados intentos fallidos, espera antes de volver a intentar"
+	if bloqueo.Err == services.ErrCuentaBloqueada {
+		mensaje = "La cuenta está bloqueada temporalmente por intentos fallidos; revisa tu correo para desbloquearla"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(segundos))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":           bloqueo.Err.Error(),
+		"mensaje":         mensaje,
+		"bloqueado_hasta": bloqueo.Hasta,
+	})
+}
+
+// HandlerRefrescarSesion rota el refresh token y entrega un nuevo par de tokens
+func (h *UserHandler) HandlerRefrescarSesion(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := leerRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	usuario, tokens, err := h.authService.RefrescarSesion(r.Context(), refreshToken)
+	if err != nil {
+		escribirErrorRefreshToken(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sesionResponse(usuario, tokens))
+}
+
+// HandlerCerrarSesion revoca el refresh token (y toda su familia) y, si se
+// envía en el header Authorization, también el access token actual
+func (h *UserHandler) HandlerCerrarSesion(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := leerRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.CerrarSesion(r.Context(), refreshToken); err != nil {
+		escribirErrorRefreshToken(w, err)
+		return
+	}
+
+	// Si además viene el access token, se revoca para que deje de servir de inmediato
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		claims, err := h.authService.ValidateJWT(r.Context(), strings.TrimPrefix(authHeader, "Bearer "))
+		if err == nil {
+			if err := h.authService.RevocarAccessToken(r.Context(), claims); err != nil {
+				fmt.Printf("Error revocando access token en logout: %v\n", err)
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"nombre":  nombre,
-		"token":   token,
-		"rol":     rol,
-		"user_id": id,
-		"correo":  datos["correo"].(string),
+		"mensaje": "Sesión cerrada exitosamente",
 	})
 }
 
-func HandlerValidacion(w http.ResponseWriter, r *http.Request) {
+// leerRefreshToken valida el método y extrae refresh_token del body JSON.
+// Si algo falla escribe la respuesta de error y retorna false.
+func leerRefreshToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return "", false
+	}
+
+	var datos struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || datos.RefreshToken == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "refresh_token es requerido",
+		})
+		return "", false
+	}
+
+	return datos.RefreshToken, true
+}
+
+func escribirErrorRefreshToken(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	switch err {
+	case services.ErrRefreshTokenInvalido:
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "INVALID_REFRESH_TOKEN",
+			"mensaje": "El refresh token es inválido, expiró o fue revocado",
+		})
+	case services.ErrRefreshTokenReutilizado:
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "REFRESH_TOKEN_REUSED",
+			"mensaje": "El refresh token ya había sido usado; la sesión fue revocada",
+		})
+	default:
+		fmt.Printf("Error específico durante refresh de sesión: %v\n", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "INTERNAL_ERROR",
+			"mensaje": "Error interno del servidor",
+		})
+	}
+}
+
+// clientIP retorna la IP del cliente. Con trustForwardedFor se usa la última
+// dirección de X-Forwarded-For, la que agregó el proxy de confianza: las
+// anteriores las envía el cliente y no sirven para limitar intentos por IP.
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if valores := r.Header.Values("X-Forwarded-For"); len(valores) > 0 {
+			ultimo := valores[len(valores)-1]
+			if i := strings.LastIndex(ultimo, ","); i >= 0 {
+				ultimo = ultimo[i+1:]
+			}
+			if ip := strings.TrimSpace(ultimo); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// sesionResponse arma el body de /auth y /auth/refresh.
+// "token" se mantiene por compatibilidad con el frontend y es el access token.
+func sesionResponse(usuario *models.User, tokens *services.TokenPair) map[string]interface{} {
+	return map[string]interface{}{
+		"nombre":        usuario.NombreCompleto,
+		"token":         tokens.AccessToken,
+		"token_type":    "Bearer",
+		"expires_in":    tokens.ExpiresIn,
+		"refresh_token": tokens.RefreshToken,
+		"rol":           usuario.Rol,
+		"roles":         usuario.RolesAsignados(),
+		"user_id":       usuario.ID,
+		"correo":        usuario.Correo,
+	}
+}
+
+func (h *UserHandler) HandlerValidacion(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -144,8 +412,7 @@ func HandlerValidacion(w http.ResponseWriter, r *http.Request) {
 	}
 	token := parts[1]
 
-	authService := services.NewAuthService()
-	claims, err := authService.ValidateJWT(token)
+	claims, err := h.authService.ValidateJWT(r.Context(), token)
 	if err != nil {
 		http.Error(w, `{"error": "token inválido"}`, http.StatusUnauthorized)
 		return
@@ -155,6 +422,7 @@ func HandlerValidacion(w http.ResponseWriter, r *http.Request) {
 		UserID: claims.UserID,
 		Email:  claims.Email,
 		Role:   claims.Role,
+		Roles:  claims.Roles,
 		Name:   claims.Name,
 	}
 
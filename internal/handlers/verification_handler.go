@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/unobeswarch/businesslogic/internal/services"
+)
+
+// VerificationHandler expone la verificación del correo y el reenvío del enlace
+type VerificationHandler struct {
+	verificationService *services.VerificationService
+}
+
+func NewVerificationHandler(verificationService *services.VerificationService) *VerificationHandler {
+	return &VerificationHandler{verificationService: verificationService}
+}
+
+// HandlerVerificarCorreo responde POST /verify-email
+func (h *VerificationHandler) HandlerVerificarCorreo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || datos.Token == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "token es requerido",
+		})
+		return
+	}
+
+	if err := h.verificationService.VerificarCorreo(r.Context(), datos.Token); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		switch err {
+		case services.ErrTokenVerificacionInvalido:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INVALID_VERIFICATION_TOKEN",
+				"mensaje": "El enlace de verificación es inválido, expiró o ya fue usado",
+			})
+		default:
+			fmt.Printf("Error específico durante verificación de correo: %v\n", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INTERNAL_ERROR",
+				"mensaje": "Error interno del servidor",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "Correo verificado exitosamente",
+	})
+}
+
+// HandlerConfirmarCambioCorreo responde POST /verify-email/change: confirma
+// el correo nuevo pedido desde el perfil (updateProfile en GraphQL)
+func (h *VerificationHandler) HandlerConfirmarCambioCorreo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || datos.Token == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "token es requerido",
+		})
+		return
+	}
+
+	if err := h.verificationService.ConfirmarCambioCorreo(r.Context(), datos.Token); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		switch err {
+		case services.ErrTokenVerificacionInvalido:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INVALID_VERIFICATION_TOKEN",
+				"mensaje": "El enlace de confirmación es inválido, expiró o ya fue usado",
+			})
+		case services.ErrCorreoEnUso:
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "EMAIL_ALREADY_REGISTERED",
+				"mensaje": "El correo ya está registrado en otra cuenta",
+			})
+		default:
+			fmt.Printf("Error específico durante cambio de correo: %v\n", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INTERNAL_ERROR",
+				"mensaje": "Error interno del servidor",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "Correo actualizado exitosamente",
+	})
+}
+
+// HandlerReenviarVerificacion responde POST /verify-email/resend. Responde
+// igual exista o no la cuenta; solo informa cuando hay que esperar para reenviar.
+func (h *VerificationHandler) HandlerReenviarVerificacion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Metodo no permitido",
+		})
+		return
+	}
+
+	var datos struct {
+		Correo string `json:"correo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&datos); err != nil || strings.TrimSpace(datos.Correo) == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "VALIDATION_ERROR",
+			"mensaje": "correo es requerido",
+		})
+		return
+	}
+
+	espera, err := h.verificationService.ReenviarVerificacion(r.Context(), strings.TrimSpace(datos.Correo))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		switch err {
+		case services.ErrReenvioMuyFrecuente:
+			segundos := int(math.Ceil(espera.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(segundos))
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":             "VERIFICATION_RESEND_THROTTLED",
+				"mensaje":           "Espera antes de solicitar otro correo de verificación",
+				"reintentar_en_seg": segundos,
+			})
+		default:
+			fmt.Printf("Error específico durante reenvío de verificación: %v\n", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "INTERNAL_ERROR",
+				"mensaje": "Error interno del servidor",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mensaje": "Si la cuenta existe y no está verificada recibirás un nuevo enlace de verificación",
+	})
+}
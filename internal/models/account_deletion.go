@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Motivos de una solicitud de eliminación
+const (
+	MotivoEliminarCuenta        = "eliminar_cuenta"
+	MotivoRetirarConsentimiento = "retirar_consentimiento"
+)
+
+// Estados de una solicitud de eliminación
+const (
+	EliminacionProgramada = "programada"
+	EliminacionCancelada  = "cancelada"
+	EliminacionEjecutada  = "ejecutada"
+)
+
+// AccountDeletion representa un registro de la tabla solicitudes_eliminacion.
+// EstadoAnterior es el estado de la cuenta antes de la solicitud, al que se
+// vuelve si el usuario la cancela.
+type AccountDeletion struct {
+	ID             int64
+	UsuarioID      int
+	Motivo         string
+	Estado         string
+	EstadoAnterior string
+	UltimoError    string
+	SolicitadaEn   time.Time
+	EjecutarEn     time.Time
+	FinalizadaEn   *time.Time
+}
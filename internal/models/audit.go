@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Eventos registrados en la tabla auditoria
+const (
+	EventoCuentaBloqueada        = "cuenta_bloqueada"
+	EventoIPBloqueada            = "ip_bloqueada"
+	EventoCuentaDesbloqueada     = "cuenta_desbloqueada"
+	EventoUsuarioActivado        = "usuario_activado"
+	EventoUsuarioDesactivado     = "usuario_desactivado"
+	EventoRolCambiado            = "rol_cambiado"
+	EventoRolAsignado            = "rol_asignado"
+	EventoRolRetirado            = "rol_retirado"
+	EventoDoctorAprobado         = "doctor_aprobado"
+	EventoDoctorRechazado        = "doctor_rechazado"
+	EventoExportacionPedida      = "exportacion_datos_pedida"
+	EventoExportacionDescargada  = "exportacion_datos_descargada"
+	EventoEliminacionSolicitada  = "eliminacion_cuenta_solicitada"
+	EventoConsentimientoRetirado = "consentimiento_retirado"
+	EventoEliminacionCancelada   = "eliminacion_cuenta_cancelada"
+	EventoCuentaAnonimizada      = "cuenta_anonimizada"
+	EventoPoliticaPublicada      = "politica_privacidad_publicada"
+)
+
+// AuditEvent representa un registro de la tabla auditoria
+type AuditEvent struct {
+	ID        int64
+	UsuarioID *int
+	Evento    string
+	IP        string
+	Detalle   map[string]interface{}
+	CreadoEn  time.Time
+}
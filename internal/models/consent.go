@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PrivacyPolicy representa una versión de la política de tratamiento de
+// datos (tabla politicas_privacidad). La vigente es la de mayor Version.
+type PrivacyPolicy struct {
+	Version      int
+	Contenido    string
+	PublicadaPor *int
+	PublicadaEn  time.Time
+}
+
+// Consent representa un registro de la tabla consentimientos: la aceptación
+// de una versión de la política por un usuario. RetiradoEn indica que el
+// usuario retiró el consentimiento; la aceptación deja de ser vigente.
+type Consent struct {
+	ID         int64
+	UsuarioID  int
+	Version    int
+	IP         string
+	AceptadoEn time.Time
+	RetiradoEn *time.Time
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Estados de una exportación de datos personales
+const (
+	ExportacionPendiente  = "pendiente"
+	ExportacionProcesando = "procesando"
+	ExportacionCompletada = "completada"
+	ExportacionFallida    = "fallida"
+	// ExportacionExpirada es una exportación completada cuyo ZIP ya se borró
+	ExportacionExpirada = "expirada"
+)
+
+// DataExport representa un registro de la tabla exportaciones_datos: el ZIP
+// con todos los datos personales de un usuario, generado en segundo plano.
+type DataExport struct {
+	ID           string
+	UsuarioID    int
+	Estado       string
+	Archivo      []byte
+	Error        string
+	CreadoEn     time.Time
+	CompletadoEn *time.Time
+	ExpiraEn     *time.Time
+}
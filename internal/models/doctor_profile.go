@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// DoctorProfile representa un registro de la tabla perfiles_doctor: los datos
+// de la solicitud de cuenta de un doctor y su revisión. RevisadoPor y
+// RevisadoEn son nil mientras la solicitud está pendiente.
+type DoctorProfile struct {
+	UsuarioID      int
+	NumeroLicencia string
+	Especialidad   string
+	RevisadoPor    *int
+	RevisadoEn     *time.Time
+	MotivoRechazo  string
+	CreadoEn       time.Time
+}
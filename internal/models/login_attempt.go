@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// LoginAttempt es el contador de intentos fallidos de login de una clave
+// (una cuenta o una IP), almacenado en la tabla intentos_login.
+type LoginAttempt struct {
+	Clave          string
+	Fallos         int
+	UltimoFallo    time.Time
+	BloqueadoHasta *time.Time
+}
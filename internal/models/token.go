@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// RefreshToken representa un registro de la tabla tokens_refresco.
+// El token en claro solo se entrega al cliente; aquí se guarda su hash.
+type RefreshToken struct {
+	ID         int64
+	UsuarioID  int
+	FamiliaID  string
+	TokenHash  string
+	ExpiraEn   time.Time
+	CreadoEn   time.Time
+	UsadoEn    *time.Time
+	RevocadoEn *time.Time
+}
+
+// Propósitos de los tokens de un solo uso enviados por correo
+const (
+	PropositoRestablecerContrasena = "restablecer_contrasena"
+	PropositoVerificarCorreo       = "verificar_correo"
+	PropositoCambiarCorreo         = "cambiar_correo"
+	PropositoDesbloquearCuenta     = "desbloquear_cuenta"
+	PropositoDesafioSegundoFactor  = "desafio_2fa"
+	PropositoCancelarEliminacion   = "cancelar_eliminacion"
+)
+
+// UserToken representa un registro de la tabla tokens_usuario: un token de un
+// solo uso, con expiración, asociado a un flujo (Proposito) de un usuario.
+type UserToken struct {
+	ID        int64
+	UsuarioID int
+	Proposito string
+	TokenHash string
+	ExpiraEn  time.Time
+	CreadoEn  time.Time
+	UsadoEn   *time.Time
+	// IntentosFallidos cuenta los códigos incorrectos de un desafío de segundo factor
+	IntentosFallidos int
+}
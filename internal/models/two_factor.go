@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TwoFactor representa un registro de la tabla segundo_factor: el secreto
+// TOTP del usuario. ActivadoEn es nil mientras el enrolamiento está pendiente.
+type TwoFactor struct {
+	UsuarioID  int
+	Secreto    string // base32, sin relleno
+	ActivadoEn *time.Time
+	UltimoPaso int64 // último paso de 30 s aceptado, para impedir reusar un código
+	CreadoEn   time.Time
+}
@@ -1,11 +1,80 @@
 package models
 
+import (
+	"slices"
+	"time"
+)
+
+// Roles de usuario
+const (
+	RolPaciente = "paciente"
+	RolDoctor   = "doctor"
+	RolAdmin    = "admin"
+)
+
+// Estados de la cuenta de un usuario
+const (
+	EstadoActivo   = "activo"
+	EstadoInactivo = "inactivo"
+	// EstadoPendienteAprobacion es el de un doctor registrado que un
+	// administrador aún no aprueba; no puede iniciar sesión
+	EstadoPendienteAprobacion = "pendiente_aprobacion"
+	// EstadoRechazado es el de un doctor cuya solicitud fue rechazada
+	EstadoRechazado = "rechazado"
+	// EstadoEliminacionPendiente es el de una cuenta cuyo titular pidió
+	// eliminarla o retiró el consentimiento; se anonimiza al terminar el
+	// periodo de gracia y mientras tanto no puede iniciar sesión
+	EstadoEliminacionPendiente = "eliminacion_pendiente"
+	// EstadoAnonimizado es el de una cuenta cuyos datos personales ya se
+	// borraron
+	EstadoAnonimizado = "anonimizado"
+)
+
+// User representa un registro de la tabla usuarios.
+// Contrasena solo se usa para recibir la contraseña en texto plano al
+// registrarse; lo que se persiste es ContrasenaHash (bcrypt).
+// CorreoVerificado, Estado y Roles no se reciben por JSON: solo cambian al
+// confirmar el correo o por acción de un administrador. CorreoPendiente es el
+// correo nuevo pedido desde el perfil, que aún no se confirma.
+// Rol es el rol principal; Roles son todos los roles del usuario (ver
+// RolesAsignados). AceptaTratamientoDatos es la casilla del formulario de
+// registro; si el consentimiento sigue vigente lo dice la tabla
+// consentimientos.
 type User struct {
-	NombreCompleto         string `json:"nombre_completo"`
-	Edad                   int    `json:"edad"`
-	Rol                    string `json:"rol"`
-	Identificacion         string `json:"identificacion"`
-	Correo                 string `json:"correo"`
-	Contrasena             string `json:"contrasena"`
-	AceptaTratamientoDatos bool   `json:"acepta_tratamiento_datos"`
+	ID                     int       `json:"id,omitempty"`
+	NombreCompleto         string    `json:"nombre_completo"`
+	Edad                   int       `json:"edad"`
+	Rol                    string    `json:"rol"`
+	Identificacion         string    `json:"identificacion"`
+	Correo                 string    `json:"correo"`
+	Contrasena             string    `json:"contrasena"`
+	ContrasenaHash         string    `json:"-"`
+	AceptaTratamientoDatos bool      `json:"acepta_tratamiento_datos"`
+	CorreoVerificado       bool      `json:"-"`
+	CorreoPendiente        string    `json:"-"`
+	Estado                 string    `json:"-"`
+	Roles                  []string  `json:"-"`
+	FechaCreacion          time.Time `json:"-"`
+}
+
+// RolValido indica si rol es uno de los roles conocidos
+func RolValido(rol string) bool {
+	return rol == RolPaciente || rol == RolDoctor || rol == RolAdmin
+}
+
+// RolesAsignados retorna todos los roles del usuario, empezando por el
+// principal y sin repetidos
+func (u *User) RolesAsignados() []string {
+	roles := []string{u.Rol}
+	for _, rol := range u.Roles {
+		if !slices.Contains(roles, rol) {
+			roles = append(roles, rol)
+		}
+	}
+	return roles
+}
+
+// TieneRol indica si rol es el principal o alguno de los adicionales del usuario
+func (u *User) TieneRol(rol string) bool {
+	return u.Rol == rol || slices.Contains(u.Roles, rol)
 }
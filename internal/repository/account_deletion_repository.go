@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// AccountDeletionRepository define el acceso a la tabla solicitudes_eliminacion
+type AccountDeletionRepository interface {
+	// Create inserta la solicitud y completa su ID y SolicitadaEn; retorna
+	// ErrDuplicate si el usuario ya tiene una solicitud programada
+	Create(ctx context.Context, d *models.AccountDeletion) error
+	// FindScheduledForUser retorna la solicitud programada del usuario
+	FindScheduledForUser(ctx context.Context, usuarioID int) (*models.AccountDeletion, error)
+	// ListDue retorna hasta limit solicitudes programadas cuyo ejecutar_en ya pasó
+	ListDue(ctx context.Context, ahora time.Time, limit int) ([]*models.AccountDeletion, error)
+	// Update guarda estado, último error y fecha de finalización de la solicitud
+	Update(ctx context.Context, d *models.AccountDeletion) error
+}
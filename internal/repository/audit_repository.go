@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// AuditRepository registra eventos de seguridad en la tabla auditoria
+type AuditRepository interface {
+	// Record inserta el evento y completa su ID y CreadoEn
+	Record(ctx context.Context, e *models.AuditEvent) error
+}
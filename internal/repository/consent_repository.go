@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// ConsentRepository define el acceso a las tablas politicas_privacidad y
+// consentimientos
+type ConsentRepository interface {
+	// CurrentPolicy retorna la política vigente (la de mayor versión)
+	CurrentPolicy(ctx context.Context) (*models.PrivacyPolicy, error)
+	// PublishPolicy inserta la política con la versión siguiente a la vigente
+	// y completa su Version y PublicadaEn
+	PublishPolicy(ctx context.Context, p *models.PrivacyPolicy) error
+	// CurrentVersionForUser retorna la versión vigente y si el usuario la
+	// aceptó sin retirarla; ErrNotFound si no hay ninguna política publicada
+	CurrentVersionForUser(ctx context.Context, usuarioID int) (version int, aceptada bool, err error)
+	// RecordAcceptance inserta la aceptación y completa su ID y AceptadoEn;
+	// ErrDuplicate si el usuario ya había aceptado esa versión
+	RecordAcceptance(ctx context.Context, c *models.Consent) error
+	// ListForUser retorna las aceptaciones del usuario, la más reciente primero
+	ListForUser(ctx context.Context, usuarioID int) ([]*models.Consent, error)
+	// Withdraw marca como retiradas las aceptaciones vigentes del usuario
+	Withdraw(ctx context.Context, usuarioID int) error
+	// Restore deshace Withdraw: las aceptaciones retiradas vuelven a ser
+	// vigentes
+	Restore(ctx context.Context, usuarioID int) error
+	// AnonymizeForUser borra la IP de las aceptaciones del usuario; las
+	// versiones aceptadas y sus fechas se conservan
+	AnonymizeForUser(ctx context.Context, usuarioID int) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// DataExportRepository define el acceso a la tabla exportaciones_datos.
+// Get y FindLatestForUser no cargan el ZIP; se obtiene con GetFile.
+type DataExportRepository interface {
+	// Create inserta la exportación y completa su CreadoEn
+	Create(ctx context.Context, e *models.DataExport) error
+	Get(ctx context.Context, id string) (*models.DataExport, error)
+	GetFile(ctx context.Context, id string) ([]byte, error)
+	// FindLatestForUser retorna la última exportación pedida por el usuario
+	FindLatestForUser(ctx context.Context, usuarioID int) (*models.DataExport, error)
+	// Update guarda estado, archivo, error y fechas de la exportación
+	Update(ctx context.Context, e *models.DataExport) error
+	// Expire borra el ZIP de las exportaciones completadas que expiraron antes
+	// de ahora y marca como fallidas las pendientes creadas antes de
+	// abandonadasAntes (p. ej. por un reinicio durante la generación)
+	Expire(ctx context.Context, ahora, abandonadasAntes time.Time) error
+	// DeleteForUser borra todas las exportaciones del usuario
+	DeleteForUser(ctx context.Context, usuarioID int) error
+}
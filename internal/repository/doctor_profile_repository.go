@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// ErrDuplicate se retorna cuando un valor único ya está registrado
+var ErrDuplicate = errors.New("registro duplicado")
+
+// DoctorProfileRepository define el acceso a la tabla perfiles_doctor
+type DoctorProfileRepository interface {
+	// CreateWithUser inserta el usuario y su perfil de doctor en una sola
+	// operación; ErrDuplicate si el número de licencia ya está registrado
+	CreateWithUser(ctx context.Context, u *models.User, p *models.DoctorProfile) error
+	// Get retorna el perfil del usuario; ErrNotFound si no es o no fue doctor
+	Get(ctx context.Context, usuarioID int) (*models.DoctorProfile, error)
+	LicenseExists(ctx context.Context, numeroLicencia string) (bool, error)
+	// Review registra quién revisó la solicitud; motivoRechazo vacío si se aprobó
+	Review(ctx context.Context, usuarioID int, revisadoPor *int, motivoRechazo string) error
+	// DeleteForUser borra el perfil del usuario, si lo tiene
+	DeleteForUser(ctx context.Context, usuarioID int) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// LoginAttemptRepository almacena los contadores de intentos fallidos de
+// login. La implementación en memoria sirve para una sola instancia; con
+// varias réplicas se usa PostgreSQL para que compartan los contadores.
+type LoginAttemptRepository interface {
+	// Get retorna el contador de la clave; ErrNotFound si no tiene fallos
+	Get(ctx context.Context, clave string) (*models.LoginAttempt, error)
+	// RecordFailure suma un fallo de forma atómica y retorna el total. Si el
+	// último fallo es anterior a reiniciarAntes el contador vuelve a empezar.
+	RecordFailure(ctx context.Context, clave string, ahora, reiniciarAntes time.Time) (int, error)
+	// Block bloquea la clave hasta la fecha indicada
+	Block(ctx context.Context, clave string, hasta time.Time) error
+	// Reset elimina el contador (login exitoso o desbloqueo)
+	Reset(ctx context.Context, clave string) error
+	// PurgeExpired elimina los contadores sin fallos desde antes y sin bloqueo vigente
+	PurgeExpired(ctx context.Context, antes time.Time) error
+}
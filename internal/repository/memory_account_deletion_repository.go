@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryAccountDeletionRepository implementa AccountDeletionRepository en memoria
+type MemoryAccountDeletionRepository struct {
+	mu          sync.Mutex
+	solicitudes map[int64]models.AccountDeletion
+	nextID      int64
+}
+
+func NewMemoryAccountDeletionRepository() *MemoryAccountDeletionRepository {
+	return &MemoryAccountDeletionRepository{solicitudes: make(map[int64]models.AccountDeletion)}
+}
+
+func (r *MemoryAccountDeletionRepository) Create(ctx context.Context, d *models.AccountDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existente := range r.solicitudes {
+		if existente.UsuarioID == d.UsuarioID && existente.Estado == models.EliminacionProgramada &&
+			d.Estado == models.EliminacionProgramada {
+			return ErrDuplicate
+		}
+	}
+	r.nextID++
+	d.ID = r.nextID
+	d.SolicitadaEn = time.Now()
+	r.solicitudes[d.ID] = *d
+	return nil
+}
+
+func (r *MemoryAccountDeletionRepository) FindScheduledForUser(ctx context.Context, usuarioID int) (*models.AccountDeletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.solicitudes {
+		if d.UsuarioID == usuarioID && d.Estado == models.EliminacionProgramada {
+			found := d
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryAccountDeletionRepository) ListDue(ctx context.Context, ahora time.Time, limit int) ([]*models.AccountDeletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var solicitudes []*models.AccountDeletion
+	for _, d := range r.solicitudes {
+		if d.Estado == models.EliminacionProgramada && !d.EjecutarEn.After(ahora) {
+			found := d
+			solicitudes = append(solicitudes, &found)
+		}
+	}
+	sort.Slice(solicitudes, func(i, j int) bool { return solicitudes[i].EjecutarEn.Before(solicitudes[j].EjecutarEn) })
+	if len(solicitudes) > limit {
+		solicitudes = solicitudes[:limit]
+	}
+	return solicitudes, nil
+}
+
+func (r *MemoryAccountDeletionRepository) Update(ctx context.Context, d *models.AccountDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.solicitudes[d.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Estado = d.Estado
+	existing.UltimoError = d.UltimoError
+	existing.FinalizadaEn = d.FinalizadaEn
+	r.solicitudes[d.ID] = existing
+	return nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryAuditRepository implementa AuditRepository en memoria
+type MemoryAuditRepository struct {
+	mu     sync.Mutex
+	events []models.AuditEvent
+}
+
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+func (r *MemoryAuditRepository) Record(ctx context.Context, e *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.ID = int64(len(r.events) + 1)
+	e.CreadoEn = time.Now()
+	r.events = append(r.events, *e)
+	return nil
+}
+
+// Events retorna una copia de los eventos registrados
+func (r *MemoryAuditRepository) Events() []models.AuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]models.AuditEvent(nil), r.events...)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryConsentRepository implementa ConsentRepository en memoria
+type MemoryConsentRepository struct {
+	mu              sync.Mutex
+	politicas       []models.PrivacyPolicy
+	consentimientos []models.Consent
+	nextID          int64
+}
+
+func NewMemoryConsentRepository() *MemoryConsentRepository {
+	return &MemoryConsentRepository{}
+}
+
+func (r *MemoryConsentRepository) CurrentPolicy(ctx context.Context) (*models.PrivacyPolicy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.politicas) == 0 {
+		return nil, ErrNotFound
+	}
+	p := r.politicas[len(r.politicas)-1]
+	return &p, nil
+}
+
+func (r *MemoryConsentRepository) PublishPolicy(ctx context.Context, p *models.PrivacyPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.Version = len(r.politicas) + 1
+	p.PublicadaEn = time.Now()
+	r.politicas = append(r.politicas, *p)
+	return nil
+}
+
+func (r *MemoryConsentRepository) CurrentVersionForUser(ctx context.Context, usuarioID int) (int, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.politicas) == 0 {
+		return 0, false, ErrNotFound
+	}
+	version := len(r.politicas)
+	for _, c := range r.consentimientos {
+		if c.UsuarioID == usuarioID && c.Version == version && c.RetiradoEn == nil {
+			return version, true, nil
+		}
+	}
+	return version, false, nil
+}
+
+func (r *MemoryConsentRepository) RecordAcceptance(ctx context.Context, c *models.Consent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existente := range r.consentimientos {
+		if existente.UsuarioID == c.UsuarioID && existente.Version == c.Version {
+			return ErrDuplicate
+		}
+	}
+	r.nextID++
+	c.ID = r.nextID
+	c.AceptadoEn = time.Now()
+	r.consentimientos = append(r.consentimientos, *c)
+	return nil
+}
+
+func (r *MemoryConsentRepository) ListForUser(ctx context.Context, usuarioID int) ([]*models.Consent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var consentimientos []*models.Consent
+	for i := len(r.consentimientos) - 1; i >= 0; i-- {
+		if c := r.consentimientos[i]; c.UsuarioID == usuarioID {
+			consentimientos = append(consentimientos, &c)
+		}
+	}
+	return consentimientos, nil
+}
+
+func (r *MemoryConsentRepository) Withdraw(ctx context.Context, usuarioID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ahora := time.Now()
+	for i := range r.consentimientos {
+		if c := &r.consentimientos[i]; c.UsuarioID == usuarioID && c.RetiradoEn == nil {
+			retiradoEn := ahora
+			c.RetiradoEn = &retiradoEn
+		}
+	}
+	return nil
+}
+
+func (r *MemoryConsentRepository) Restore(ctx context.Context, usuarioID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.consentimientos {
+		if r.consentimientos[i].UsuarioID == usuarioID {
+			r.consentimientos[i].RetiradoEn = nil
+		}
+	}
+	return nil
+}
+
+func (r *MemoryConsentRepository) AnonymizeForUser(ctx context.Context, usuarioID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.consentimientos {
+		if r.consentimientos[i].UsuarioID == usuarioID {
+			r.consentimientos[i].IP = ""
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryDataExportRepository implementa DataExportRepository en memoria
+type MemoryDataExportRepository struct {
+	mu      sync.Mutex
+	exports map[string]models.DataExport
+}
+
+func NewMemoryDataExportRepository() *MemoryDataExportRepository {
+	return &MemoryDataExportRepository{exports: make(map[string]models.DataExport)}
+}
+
+func (r *MemoryDataExportRepository) Create(ctx context.Context, e *models.DataExport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.CreadoEn = time.Now()
+	r.exports[e.ID] = *e
+	return nil
+}
+
+func (r *MemoryDataExportRepository) Get(ctx context.Context, id string) (*models.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.exports[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	e.Archivo = nil
+	return &e, nil
+}
+
+func (r *MemoryDataExportRepository) GetFile(ctx context.Context, id string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.exports[id]
+	if !ok || e.Archivo == nil {
+		return nil, ErrNotFound
+	}
+	return e.Archivo, nil
+}
+
+func (r *MemoryDataExportRepository) FindLatestForUser(ctx context.Context, usuarioID int) (*models.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ultima *models.DataExport
+	for _, e := range r.exports {
+		if e.UsuarioID == usuarioID && (ultima == nil || e.CreadoEn.After(ultima.CreadoEn)) {
+			found := e
+			ultima = &found
+		}
+	}
+	if ultima == nil {
+		return nil, ErrNotFound
+	}
+	ultima.Archivo = nil
+	return ultima, nil
+}
+
+func (r *MemoryDataExportRepository) Update(ctx context.Context, e *models.DataExport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.exports[e.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := *e
+	updated.CreadoEn = existing.CreadoEn
+	r.exports[e.ID] = updated
+	return nil
+}
+
+func (r *MemoryDataExportRepository) Expire(ctx context.Context, ahora, abandonadasAntes time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, e := range r.exports {
+		switch {
+		case e.Estado == models.ExportacionCompletada && e.ExpiraEn != nil && e.ExpiraEn.Before(ahora):
+			e.Estado = models.ExportacionExpirada
+			e.Archivo = nil
+		case (e.Estado == models.ExportacionPendiente || e.Estado == models.ExportacionProcesando) &&
+			e.CreadoEn.Before(abandonadasAntes):
+			e.Estado = models.ExportacionFallida
+			e.Error = "la generación no terminó a tiempo"
+		default:
+			continue
+		}
+		r.exports[id] = e
+	}
+	return nil
+}
+
+func (r *MemoryDataExportRepository) DeleteForUser(ctx context.Context, usuarioID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, e := range r.exports {
+		if e.UsuarioID == usuarioID {
+			delete(r.exports, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryDoctorProfileRepository implementa DoctorProfileRepository en memoria.
+// Crea los usuarios en el MemoryUserRepository recibido.
+type MemoryDoctorProfileRepository struct {
+	mu       sync.Mutex
+	users    *MemoryUserRepository
+	perfiles map[int]models.DoctorProfile
+}
+
+func NewMemoryDoctorProfileRepository(users *MemoryUserRepository) *MemoryDoctorProfileRepository {
+	return &MemoryDoctorProfileRepository{
+		users:    users,
+		perfiles: make(map[int]models.DoctorProfile),
+	}
+}
+
+func (r *MemoryDoctorProfileRepository) CreateWithUser(ctx context.Context, u *models.User, p *models.DoctorProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existente := range r.perfiles {
+		if existente.NumeroLicencia == p.NumeroLicencia {
+			return ErrDuplicate
+		}
+	}
+	if err := r.users.Create(ctx, u); err != nil {
+		return err
+	}
+	p.UsuarioID = u.ID
+	p.CreadoEn = time.Now()
+	r.perfiles[u.ID] = *p
+	return nil
+}
+
+func (r *MemoryDoctorProfileRepository) Get(ctx context.Context, usuarioID int) (*models.DoctorProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.perfiles[usuarioID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *MemoryDoctorProfileRepository) LicenseExists(ctx context.Context, numeroLicencia string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.perfiles {
+		if p.NumeroLicencia == numeroLicencia {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryDoctorProfileRepository) Review(ctx context.Context, usuarioID int, revisadoPor *int, motivoRechazo string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.perfiles[usuarioID]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now()
+	p.RevisadoPor = revisadoPor
+	p.RevisadoEn = &now
+	p.MotivoRechazo = motivoRechazo
+	r.perfiles[usuarioID] = p
+	return nil
+}
+
+func (r *MemoryDoctorProfileRepository) DeleteForUser(ctx context.Context, usuarioID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.perfiles, usuarioID)
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryLoginAttemptRepository implementa LoginAttemptRepository en memoria.
+// Solo es correcto con una única instancia del servicio.
+type MemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+func NewMemoryLoginAttemptRepository() *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{
+		attempts: make(map[string]models.LoginAttempt),
+	}
+}
+
+func (r *MemoryLoginAttemptRepository) Get(ctx context.Context, clave string) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[clave]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &a, nil
+}
+
+func (r *MemoryLoginAttemptRepository) RecordFailure(ctx context.Context, clave string, ahora, reiniciarAntes time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[clave]
+	if !ok || a.UltimoFallo.Before(reiniciarAntes) {
+		a = models.LoginAttempt{Clave: clave, BloqueadoHasta: a.BloqueadoHasta}
+	}
+	a.Fallos++
+	a.UltimoFallo = ahora
+	r.attempts[clave] = a
+	return a.Fallos, nil
+}
+
+func (r *MemoryLoginAttemptRepository) Block(ctx context.Context, clave string, hasta time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[clave]
+	if !ok {
+		return ErrNotFound
+	}
+	a.BloqueadoHasta = &hasta
+	r.attempts[clave] = a
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) Reset(ctx context.Context, clave string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, clave)
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) PurgeExpired(ctx context.Context, antes time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for clave, a := range r.attempts {
+		if a.UltimoFallo.Before(antes) && (a.BloqueadoHasta == nil || a.BloqueadoHasta.Before(now)) {
+			delete(r.attempts, clave)
+		}
+	}
+	return nil
+}
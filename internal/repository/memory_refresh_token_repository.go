@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryRefreshTokenRepository implementa RefreshTokenRepository en memoria
+type MemoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]models.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepository() *MemoryRefreshTokenRepository {
+	return &MemoryRefreshTokenRepository{
+		nextID: 1,
+		tokens: make(map[int64]models.RefreshToken),
+	}
+}
+
+func (r *MemoryRefreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t.ID = r.nextID
+	t.CreadoEn = time.Now()
+	r.nextID++
+	r.tokens[t.ID] = *t
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			found := t
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRefreshTokenRepository) MarkUsed(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if t.UsadoEn != nil || t.RevocadoEn != nil {
+		return ErrAlreadyUsed
+	}
+	now := time.Now()
+	t.UsadoEn = &now
+	r.tokens[id] = t
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familiaID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, t := range r.tokens {
+		if t.FamiliaID == familiaID && t.RevocadoEn == nil {
+			t.RevocadoEn = &now
+			r.tokens[id] = t
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) RevokeAllForUser(ctx context.Context, usuarioID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, t := range r.tokens {
+		if t.UsuarioID == usuarioID && t.RevocadoEn == nil {
+			t.RevocadoEn = &now
+			r.tokens[id] = t
+		}
+	}
+	return nil
+}
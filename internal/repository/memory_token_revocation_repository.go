@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryTokenRevocationRepository implementa TokenRevocationRepository en memoria
+type MemoryTokenRevocationRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time // jti -> expiración
+	users  map[int]time.Time    // usuario -> revocado desde
+}
+
+func NewMemoryTokenRevocationRepository() *MemoryTokenRevocationRepository {
+	return &MemoryTokenRevocationRepository{
+		tokens: make(map[string]time.Time),
+		users:  make(map[int]time.Time),
+	}
+}
+
+func (r *MemoryTokenRevocationRepository) RevokeToken(ctx context.Context, jti string, usuarioID int, expiraEn time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[jti] = expiraEn
+	return nil
+}
+
+func (r *MemoryTokenRevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.tokens[jti]
+	return ok, nil
+}
+
+func (r *MemoryTokenRevocationRepository) RevokeUserTokens(ctx context.Context, usuarioID int, desde time.Time, motivo string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if actual, ok := r.users[usuarioID]; !ok || desde.After(actual) {
+		r.users[usuarioID] = desde
+	}
+	return nil
+}
+
+func (r *MemoryTokenRevocationRepository) UserRevokedSince(ctx context.Context, usuarioID int) (*time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	desde, ok := r.users[usuarioID]
+	if !ok {
+		return nil, nil
+	}
+	return &desde, nil
+}
+
+func (r *MemoryTokenRevocationRepository) PurgeExpired(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for jti, exp := range r.tokens {
+		if exp.Before(now) {
+			delete(r.tokens, jti)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryTwoFactorRepository implementa TwoFactorRepository en memoria
+type MemoryTwoFactorRepository struct {
+	mu       sync.Mutex
+	factors  map[int]models.TwoFactor
+	recovery map[int]map[string]bool // usuario -> hash -> usado
+}
+
+func NewMemoryTwoFactorRepository() *MemoryTwoFactorRepository {
+	return &MemoryTwoFactorRepository{
+		factors:  make(map[int]models.TwoFactor),
+		recovery: make(map[int]map[string]bool),
+	}
+}
+
+func (r *MemoryTwoFactorRepository) Get(ctx context.Context, usuarioID int) (*models.TwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.factors[usuarioID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &f, nil
+}
+
+func (r *MemoryTwoFactorRepository) SavePending(ctx context.Context, usuarioID int, secreto string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factors[usuarioID] = models.TwoFactor{UsuarioID: usuarioID, Secreto: secreto, CreadoEn: time.Now()}
+	return nil
+}
+
+func (r *MemoryTwoFactorRepository) Activate(ctx context.Context, usuarioID int, paso int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.factors[usuarioID]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now()
+	f.ActivadoEn = &now
+	f.UltimoPaso = paso
+	r.factors[usuarioID] = f
+	return nil
+}
+
+func (r *MemoryTwoFactorRepository) UseStep(ctx context.Context, usuarioID int, paso int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.factors[usuarioID]
+	if !ok {
+		return ErrNotFound
+	}
+	if paso <= f.UltimoPaso {
+		return ErrAlreadyUsed
+	}
+	f.UltimoPaso = paso
+	r.factors[usuarioID] = f
+	return nil
+}
+
+func (r *MemoryTwoFactorRepository) Delete(ctx context.Context, usuarioID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.factors, usuarioID)
+	delete(r.recovery, usuarioID)
+	return nil
+}
+
+func (r *MemoryTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, usuarioID int, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	codes := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		codes[h] = false
+	}
+	r.recovery[usuarioID] = codes
+	return nil
+}
+
+func (r *MemoryTwoFactorRepository) UseRecoveryCode(ctx context.Context, usuarioID int, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usado, ok := r.recovery[usuarioID][hash]
+	if !ok || usado {
+		return ErrNotFound
+	}
+	r.recovery[usuarioID][hash] = true
+	return nil
+}
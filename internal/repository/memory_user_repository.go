@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryUserRepository implementa UserRepository en memoria.
+// Pensado para pruebas y desarrollo local sin PostgreSQL.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	users  map[int]models.User
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		nextID: 1,
+		users:  make(map[int]models.User),
+	}
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u.ID = r.nextID
+	u.FechaCreacion = time.Now()
+	r.nextID++
+
+	u.Roles = u.RolesAsignados()
+	stored := *u
+	stored.Contrasena = ""
+	stored.Roles = slices.Clone(u.Roles)
+	r.users[u.ID] = stored
+	return nil
+}
+
+func (r *MemoryUserRepository) FindByEmail(ctx context.Context, correo string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Correo == correo {
+			return copiarUsuario(u), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id int) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copiarUsuario(u), nil
+}
+
+func (r *MemoryUserRepository) FindByIDs(ctx context.Context, ids []int) (map[int]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usuarios := make(map[int]*models.User, len(ids))
+	for _, id := range ids {
+		if u, ok := r.users[id]; ok {
+			usuarios[id] = copiarUsuario(u)
+		}
+	}
+	return usuarios, nil
+}
+
+func (r *MemoryUserRepository) FindByIdentificacion(ctx context.Context, identificacion string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Identificacion == identificacion {
+			return copiarUsuario(u), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepository) Exists(ctx context.Context, correo, identificacion string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Correo == correo || u.Identificacion == identificacion {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryUserRepository) Update(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[u.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	u.Roles = u.RolesAsignados()
+	updated := *u
+	updated.Contrasena = ""
+	updated.Roles = slices.Clone(u.Roles)
+	updated.FechaCreacion = existing.FechaCreacion
+	r.users[u.ID] = updated
+	return nil
+}
+
+func (r *MemoryUserRepository) List(ctx context.Context, filtro UserFilter) ([]*models.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	busqueda := strings.ToLower(filtro.Busqueda)
+	var encontrados []*models.User
+	for _, u := range r.users {
+		if filtro.Rol != "" && !u.TieneRol(filtro.Rol) {
+			continue
+		}
+		if filtro.Estado != "" && u.Estado != filtro.Estado {
+			continue
+		}
+		if busqueda != "" &&
+			!strings.Contains(strings.ToLower(u.NombreCompleto), busqueda) &&
+			!strings.Contains(strings.ToLower(u.Correo), busqueda) &&
+			!strings.Contains(strings.ToLower(u.Identificacion), busqueda) {
+			continue
+		}
+		encontrados = append(encontrados, copiarUsuario(u))
+	}
+	sort.Slice(encontrados, func(i, j int) bool { return encontrados[i].ID < encontrados[j].ID })
+
+	total := len(encontrados)
+	inicio := min(filtro.Offset, total)
+	fin := min(inicio+filtro.Limit, total)
+	return encontrados[inicio:fin], total, nil
+}
+
+// copiarUsuario evita que quien recibe el usuario modifique los roles guardados
+func copiarUsuario(u models.User) *models.User {
+	u.Roles = slices.Clone(u.Roles)
+	return &u
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// MemoryUserTokenRepository implementa UserTokenRepository en memoria
+type MemoryUserTokenRepository struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]models.UserToken
+}
+
+func NewMemoryUserTokenRepository() *MemoryUserTokenRepository {
+	return &MemoryUserTokenRepository{
+		nextID: 1,
+		tokens: make(map[int64]models.UserToken),
+	}
+}
+
+func (r *MemoryUserTokenRepository) Create(ctx context.Context, t *models.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t.ID = r.nextID
+	t.CreadoEn = time.Now()
+	r.nextID++
+	r.tokens[t.ID] = *t
+	return nil
+}
+
+func (r *MemoryUserTokenRepository) FindByHash(ctx context.Context, proposito, tokenHash string) (*models.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.Proposito == proposito && t.TokenHash == tokenHash {
+			found := t
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserTokenRepository) FindLatestForUser(ctx context.Context, usuarioID int, proposito string) (*models.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var latest *models.UserToken
+	for _, t := range r.tokens {
+		if t.UsuarioID == usuarioID && t.Proposito == proposito && (latest == nil || t.ID > latest.ID) {
+			found := t
+			latest = &found
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	return latest, nil
+}
+
+func (r *MemoryUserTokenRepository) MarkUsed(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if t.UsadoEn != nil {
+		return ErrAlreadyUsed
+	}
+	now := time.Now()
+	t.UsadoEn = &now
+	r.tokens[id] = t
+	return nil
+}
+
+func (r *MemoryUserTokenRepository) RegisterFailedAttempt(ctx context.Context, id int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	t.IntentosFallidos++
+	r.tokens[id] = t
+	return t.IntentosFallidos, nil
+}
+
+func (r *MemoryUserTokenRepository) InvalidateForUser(ctx context.Context, usuarioID int, proposito string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, t := range r.tokens {
+		if t.UsuarioID == usuarioID && t.Proposito == proposito && t.UsadoEn == nil {
+			t.UsadoEn = &now
+			r.tokens[id] = t
+		}
+	}
+	return nil
+}
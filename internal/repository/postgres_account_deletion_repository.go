@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresAccountDeletionRepository implementa AccountDeletionRepository sobre PostgreSQL
+type PostgresAccountDeletionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAccountDeletionRepository(db *sql.DB) *PostgresAccountDeletionRepository {
+	return &PostgresAccountDeletionRepository{db: db}
+}
+
+const accountDeletionColumns = `id, usuario_id, motivo, estado, estado_anterior, COALESCE(ultimo_error, ''),
+	solicitada_en, ejecutar_en, finalizada_en`
+
+func (r *PostgresAccountDeletionRepository) Create(ctx context.Context, d *models.AccountDeletion) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO solicitudes_eliminacion (usuario_id, motivo, estado, estado_anterior, ejecutar_en)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, solicitada_en
+	`, d.UsuarioID, d.Motivo, d.Estado, d.EstadoAnterior, d.EjecutarEn).Scan(&d.ID, &d.SolicitadaEn)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresAccountDeletionRepository) FindScheduledForUser(ctx context.Context, usuarioID int) (*models.AccountDeletion, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+accountDeletionColumns+` FROM solicitudes_eliminacion WHERE usuario_id=$1 AND estado=$2`,
+		usuarioID, models.EliminacionProgramada)
+	d, err := scanAccountDeletion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return d, err
+}
+
+func (r *PostgresAccountDeletionRepository) ListDue(ctx context.Context, ahora time.Time, limit int) ([]*models.AccountDeletion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+accountDeletionColumns+`
+		FROM solicitudes_eliminacion
+		WHERE estado=$1 AND ejecutar_en <= $2
+		ORDER BY ejecutar_en
+		LIMIT $3
+	`, models.EliminacionProgramada, ahora, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var solicitudes []*models.AccountDeletion
+	for rows.Next() {
+		d, err := scanAccountDeletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		solicitudes = append(solicitudes, d)
+	}
+	return solicitudes, rows.Err()
+}
+
+func (r *PostgresAccountDeletionRepository) Update(ctx context.Context, d *models.AccountDeletion) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE solicitudes_eliminacion
+		SET estado=$1, ultimo_error=NULLIF($2, ''), finalizada_en=$3
+		WHERE id=$4
+	`, d.Estado, d.UltimoError, d.FinalizadaEn, d.ID)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func scanAccountDeletion(row rowScanner) (*models.AccountDeletion, error) {
+	var d models.AccountDeletion
+	err := row.Scan(&d.ID, &d.UsuarioID, &d.Motivo, &d.Estado, &d.EstadoAnterior, &d.UltimoError,
+		&d.SolicitadaEn, &d.EjecutarEn, &d.FinalizadaEn)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
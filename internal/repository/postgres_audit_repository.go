@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresAuditRepository implementa AuditRepository sobre PostgreSQL
+type PostgresAuditRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAuditRepository(db *sql.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+func (r *PostgresAuditRepository) Record(ctx context.Context, e *models.AuditEvent) error {
+	detalle := e.Detalle
+	if detalle == nil {
+		detalle = map[string]interface{}{}
+	}
+	detalleJSON, err := json.Marshal(detalle)
+	if err != nil {
+		return err
+	}
+
+	var ip sql.NullString
+	if e.IP != "" {
+		ip = sql.NullString{String: e.IP, Valid: true}
+	}
+
+	query := `
+		INSERT INTO auditoria (usuario_id, evento, ip, detalle)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, creado_en
+	`
+	return r.db.QueryRowContext(ctx, query, e.UsuarioID, e.Evento, ip, detalleJSON).
+		Scan(&e.ID, &e.CreadoEn)
+}
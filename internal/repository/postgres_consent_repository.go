@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresConsentRepository implementa ConsentRepository sobre PostgreSQL
+type PostgresConsentRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresConsentRepository(db *sql.DB) *PostgresConsentRepository {
+	return &PostgresConsentRepository{db: db}
+}
+
+func (r *PostgresConsentRepository) CurrentPolicy(ctx context.Context) (*models.PrivacyPolicy, error) {
+	var p models.PrivacyPolicy
+	var publicadaPor sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT version, contenido, publicada_por, publicada_en
+		FROM politicas_privacidad
+		ORDER BY version DESC
+		LIMIT 1
+	`).Scan(&p.Version, &p.Contenido, &publicadaPor, &p.PublicadaEn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if publicadaPor.Valid {
+		id := int(publicadaPor.Int64)
+		p.PublicadaPor = &id
+	}
+	return &p, nil
+}
+
+func (r *PostgresConsentRepository) PublishPolicy(ctx context.Context, p *models.PrivacyPolicy) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO politicas_privacidad (version, contenido, publicada_por)
+		SELECT COALESCE(MAX(version), 0) + 1, $1, $2 FROM politicas_privacidad
+		RETURNING version, publicada_en
+	`, p.Contenido, p.PublicadaPor).Scan(&p.Version, &p.PublicadaEn)
+}
+
+func (r *PostgresConsentRepository) CurrentVersionForUser(ctx context.Context, usuarioID int) (int, bool, error) {
+	var version int
+	var aceptada bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.version,
+			EXISTS (SELECT 1 FROM consentimientos c
+				WHERE c.usuario_id = $1 AND c.version = p.version AND c.retirado_en IS NULL)
+		FROM politicas_privacidad p
+		ORDER BY p.version DESC
+		LIMIT 1
+	`, usuarioID).Scan(&version, &aceptada)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, ErrNotFound
+		}
+		return 0, false, err
+	}
+	return version, aceptada, nil
+}
+
+func (r *PostgresConsentRepository) RecordAcceptance(ctx context.Context, c *models.Consent) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO consentimientos (usuario_id, version, ip)
+		VALUES ($1, $2, NULLIF($3, ''))
+		RETURNING id, aceptado_en
+	`, c.UsuarioID, c.Version, c.IP).Scan(&c.ID, &c.AceptadoEn)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresConsentRepository) ListForUser(ctx context.Context, usuarioID int) ([]*models.Consent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, usuario_id, version, COALESCE(ip, ''), aceptado_en, retirado_en
+		FROM consentimientos
+		WHERE usuario_id = $1
+		ORDER BY aceptado_en DESC, version DESC
+	`, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var consentimientos []*models.Consent
+	for rows.Next() {
+		var c models.Consent
+		if err := rows.Scan(&c.ID, &c.UsuarioID, &c.Version, &c.IP, &c.AceptadoEn, &c.RetiradoEn); err != nil {
+			return nil, err
+		}
+		consentimientos = append(consentimientos, &c)
+	}
+	return consentimientos, rows.Err()
+}
+
+func (r *PostgresConsentRepository) Withdraw(ctx context.Context, usuarioID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE consentimientos SET retirado_en = NOW()
+		WHERE usuario_id = $1 AND retirado_en IS NULL
+	`, usuarioID)
+	return err
+}
+
+func (r *PostgresConsentRepository) Restore(ctx context.Context, usuarioID int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE consentimientos SET retirado_en = NULL WHERE usuario_id = $1`, usuarioID)
+	return err
+}
+
+func (r *PostgresConsentRepository) AnonymizeForUser(ctx context.Context, usuarioID int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE consentimientos SET ip = NULL WHERE usuario_id = $1`, usuarioID)
+	return err
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresDataExportRepository implementa DataExportRepository sobre PostgreSQL
+type PostgresDataExportRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDataExportRepository(db *sql.DB) *PostgresDataExportRepository {
+	return &PostgresDataExportRepository{db: db}
+}
+
+const dataExportColumns = `id, usuario_id, estado, COALESCE(error, ''), creado_en, completado_en, expira_en`
+
+func (r *PostgresDataExportRepository) Create(ctx context.Context, e *models.DataExport) error {
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO exportaciones_datos (id, usuario_id, estado) VALUES ($1, $2, $3) RETURNING creado_en`,
+		e.ID, e.UsuarioID, e.Estado,
+	).Scan(&e.CreadoEn)
+}
+
+func (r *PostgresDataExportRepository) Get(ctx context.Context, id string) (*models.DataExport, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+dataExportColumns+` FROM exportaciones_datos WHERE id=$1`, id)
+	return scanDataExport(row)
+}
+
+func (r *PostgresDataExportRepository) GetFile(ctx context.Context, id string) ([]byte, error) {
+	var archivo []byte
+	err := r.db.QueryRowContext(ctx,
+		`SELECT archivo FROM exportaciones_datos WHERE id=$1 AND archivo IS NOT NULL`, id,
+	).Scan(&archivo)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return archivo, err
+}
+
+func (r *PostgresDataExportRepository) FindLatestForUser(ctx context.Context, usuarioID int) (*models.DataExport, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+dataExportColumns+` FROM exportaciones_datos WHERE usuario_id=$1 ORDER BY creado_en DESC LIMIT 1`,
+		usuarioID)
+	return scanDataExport(row)
+}
+
+func (r *PostgresDataExportRepository) Update(ctx context.Context, e *models.DataExport) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE exportaciones_datos
+		SET estado=$1, archivo=$2, error=NULLIF($3, ''), completado_en=$4, expira_en=$5
+		WHERE id=$6
+	`, e.Estado, e.Archivo, e.Error, e.CompletadoEn, e.ExpiraEn, e.ID)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *PostgresDataExportRepository) Expire(ctx context.Context, ahora, abandonadasAntes time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE exportaciones_datos SET estado=$1, archivo=NULL
+		WHERE estado=$2 AND expira_en < $3
+	`, models.ExportacionExpirada, models.ExportacionCompletada, ahora)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE exportaciones_datos SET estado=$1, error=$2
+		WHERE estado IN ($3, $4) AND creado_en < $5
+	`, models.ExportacionFallida, "la generación no terminó a tiempo",
+		models.ExportacionPendiente, models.ExportacionProcesando, abandonadasAntes)
+	return err
+}
+
+func (r *PostgresDataExportRepository) DeleteForUser(ctx context.Context, usuarioID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM exportaciones_datos WHERE usuario_id=$1`, usuarioID)
+	return err
+}
+
+func scanDataExport(row rowScanner) (*models.DataExport, error) {
+	var e models.DataExport
+	err := row.Scan(&e.ID, &e.UsuarioID, &e.Estado, &e.Error, &e.CreadoEn, &e.CompletadoEn, &e.ExpiraEn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresDoctorProfileRepository implementa DoctorProfileRepository sobre PostgreSQL
+type PostgresDoctorProfileRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDoctorProfileRepository(db *sql.DB) *PostgresDoctorProfileRepository {
+	return &PostgresDoctorProfileRepository{db: db}
+}
+
+func (r *PostgresDoctorProfileRepository) CreateWithUser(ctx context.Context, u *models.User, p *models.DoctorProfile) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertUser(ctx, tx, u); err != nil {
+		return err
+	}
+	p.UsuarioID = u.ID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO perfiles_doctor (usuario_id, numero_licencia, especialidad)
+		VALUES ($1, $2, $3)
+		RETURNING creado_en
+	`, p.UsuarioID, p.NumeroLicencia, p.Especialidad).Scan(&p.CreadoEn)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDuplicate
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresDoctorProfileRepository) Get(ctx context.Context, usuarioID int) (*models.DoctorProfile, error) {
+	p := models.DoctorProfile{UsuarioID: usuarioID}
+	var revisadoPor sql.NullInt64
+	var motivo sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT numero_licencia, especialidad, revisado_por, revisado_en, motivo_rechazo, creado_en
+		FROM perfiles_doctor WHERE usuario_id=$1
+	`, usuarioID).Scan(&p.NumeroLicencia, &p.Especialidad, &revisadoPor, &p.RevisadoEn, &motivo, &p.CreadoEn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if revisadoPor.Valid {
+		id := int(revisadoPor.Int64)
+		p.RevisadoPor = &id
+	}
+	p.MotivoRechazo = motivo.String
+	return &p, nil
+}
+
+func (r *PostgresDoctorProfileRepository) LicenseExists(ctx context.Context, numeroLicencia string) (bool, error) {
+	var existe bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM perfiles_doctor WHERE numero_licencia=$1)`, numeroLicencia,
+	).Scan(&existe)
+	return existe, err
+}
+
+func (r *PostgresDoctorProfileRepository) Review(ctx context.Context, usuarioID int, revisadoPor *int, motivoRechazo string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE perfiles_doctor SET revisado_por=$2, revisado_en=NOW(), motivo_rechazo=NULLIF($3, '')
+		WHERE usuario_id=$1
+	`, usuarioID, revisadoPor, motivoRechazo)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *PostgresDoctorProfileRepository) DeleteForUser(ctx context.Context, usuarioID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM perfiles_doctor WHERE usuario_id=$1`, usuarioID)
+	return err
+}
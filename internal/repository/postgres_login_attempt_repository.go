@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresLoginAttemptRepository implementa LoginAttemptRepository sobre
+// PostgreSQL, compartiendo los contadores entre réplicas.
+type PostgresLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresLoginAttemptRepository(db *sql.DB) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{db: db}
+}
+
+func (r *PostgresLoginAttemptRepository) Get(ctx context.Context, clave string) (*models.LoginAttempt, error) {
+	a := models.LoginAttempt{Clave: clave}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT fallos, ultimo_fallo, bloqueado_hasta FROM intentos_login WHERE clave=$1`, clave,
+	).Scan(&a.Fallos, &a.UltimoFallo, &a.BloqueadoHasta)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *PostgresLoginAttemptRepository) RecordFailure(ctx context.Context, clave string, ahora, reiniciarAntes time.Time) (int, error) {
+	// El upsert hace el incremento atómico aunque varias réplicas fallen a la vez
+	query := `
+		INSERT INTO intentos_login (clave, fallos, ultimo_fallo)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (clave) DO UPDATE SET
+			fallos = CASE WHEN intentos_login.ultimo_fallo < $3 THEN 1 ELSE intentos_login.fallos + 1 END,
+			ultimo_fallo = EXCLUDED.ultimo_fallo
+		RETURNING fallos
+	`
+
+	var fallos int
+	err := r.db.QueryRowContext(ctx, query, clave, ahora, reiniciarAntes).Scan(&fallos)
+	return fallos, err
+}
+
+func (r *PostgresLoginAttemptRepository) Block(ctx context.Context, clave string, hasta time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE intentos_login SET bloqueado_hasta=$2 WHERE clave=$1`, clave, hasta)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *PostgresLoginAttemptRepository) Reset(ctx context.Context, clave string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM intentos_login WHERE clave=$1`, clave)
+	return err
+}
+
+func (r *PostgresLoginAttemptRepository) PurgeExpired(ctx context.Context, antes time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM intentos_login
+		WHERE ultimo_fallo < $1 AND (bloqueado_hasta IS NULL OR bloqueado_hasta < NOW())
+	`, antes)
+	return err
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresRefreshTokenRepository implementa RefreshTokenRepository sobre PostgreSQL
+type PostgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRefreshTokenRepository(db *sql.DB) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{db: db}
+}
+
+func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+	query := `
+		INSERT INTO tokens_refresco (usuario_id, familia_id, token_hash, expira_en)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, creado_en
+	`
+	return r.db.QueryRowContext(ctx, query, t.UsuarioID, t.FamiliaID, t.TokenHash, t.ExpiraEn).
+		Scan(&t.ID, &t.CreadoEn)
+}
+
+func (r *PostgresRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, usuario_id, familia_id, token_hash, expira_en, creado_en, usado_en, revocado_en
+		FROM tokens_refresco WHERE token_hash=$1
+	`
+
+	var t models.RefreshToken
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&t.ID, &t.UsuarioID, &t.FamiliaID, &t.TokenHash, &t.ExpiraEn, &t.CreadoEn, &t.UsadoEn, &t.RevocadoEn,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PostgresRefreshTokenRepository) MarkUsed(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE tokens_refresco SET usado_en=NOW() WHERE id=$1 AND usado_en IS NULL AND revocado_en IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAlreadyUsed
+	}
+	return nil
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeFamily(ctx context.Context, familiaID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tokens_refresco SET revocado_en=NOW() WHERE familia_id=$1 AND revocado_en IS NULL`, familiaID)
+	return err
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeAllForUser(ctx context.Context, usuarioID int) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tokens_refresco SET revocado_en=NOW() WHERE usuario_id=$1 AND revocado_en IS NULL`, usuarioID)
+	return err
+}
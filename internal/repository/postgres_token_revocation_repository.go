@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PostgresTokenRevocationRepository implementa TokenRevocationRepository sobre PostgreSQL
+type PostgresTokenRevocationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTokenRevocationRepository(db *sql.DB) *PostgresTokenRevocationRepository {
+	return &PostgresTokenRevocationRepository{db: db}
+}
+
+func (r *PostgresTokenRevocationRepository) RevokeToken(ctx context.Context, jti string, usuarioID int, expiraEn time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO tokens_revocados (jti, usuario_id, expira_en)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, usuarioID, expiraEn)
+	return err
+}
+
+func (r *PostgresTokenRevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM tokens_revocados WHERE jti=$1)`, jti,
+	).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *PostgresTokenRevocationRepository) RevokeUserTokens(ctx context.Context, usuarioID int, desde time.Time, motivo string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO revocaciones_usuario (usuario_id, revocado_desde, motivo)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (usuario_id) DO UPDATE
+		SET revocado_desde = GREATEST(revocaciones_usuario.revocado_desde, EXCLUDED.revocado_desde),
+		    motivo = EXCLUDED.motivo
+	`, usuarioID, desde, motivo)
+	return err
+}
+
+func (r *PostgresTokenRevocationRepository) UserRevokedSince(ctx context.Context, usuarioID int) (*time.Time, error) {
+	var desde time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT revocado_desde FROM revocaciones_usuario WHERE usuario_id=$1`, usuarioID,
+	).Scan(&desde)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &desde, nil
+}
+
+func (r *PostgresTokenRevocationRepository) PurgeExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tokens_revocados WHERE expira_en < NOW()`)
+	return err
+}
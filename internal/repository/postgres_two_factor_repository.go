@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresTwoFactorRepository implementa TwoFactorRepository sobre PostgreSQL
+type PostgresTwoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTwoFactorRepository(db *sql.DB) *PostgresTwoFactorRepository {
+	return &PostgresTwoFactorRepository{db: db}
+}
+
+func (r *PostgresTwoFactorRepository) Get(ctx context.Context, usuarioID int) (*models.TwoFactor, error) {
+	f := models.TwoFactor{UsuarioID: usuarioID}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT secreto, activado_en, ultimo_paso, creado_en FROM segundo_factor WHERE usuario_id=$1`, usuarioID,
+	).Scan(&f.Secreto, &f.ActivadoEn, &f.UltimoPaso, &f.CreadoEn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *PostgresTwoFactorRepository) SavePending(ctx context.Context, usuarioID int, secreto string) error {
+	query := `
+		INSERT INTO segundo_factor (usuario_id, secreto)
+		VALUES ($1, $2)
+		ON CONFLICT (usuario_id) DO UPDATE SET
+			secreto = EXCLUDED.secreto, activado_en = NULL, ultimo_paso = 0, creado_en = NOW()
+	`
+	_, err := r.db.ExecContext(ctx, query, usuarioID, secreto)
+	return err
+}
+
+func (r *PostgresTwoFactorRepository) Activate(ctx context.Context, usuarioID int, paso int64) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE segundo_factor SET activado_en=NOW(), ultimo_paso=$2 WHERE usuario_id=$1`, usuarioID, paso)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (r *PostgresTwoFactorRepository) UseStep(ctx context.Context, usuarioID int, paso int64) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE segundo_factor SET ultimo_paso=$2 WHERE usuario_id=$1 AND ultimo_paso < $2`, usuarioID, paso)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAlreadyUsed
+	}
+	return nil
+}
+
+func (r *PostgresTwoFactorRepository) Delete(ctx context.Context, usuarioID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM codigos_recuperacion WHERE usuario_id=$1`, usuarioID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM segundo_factor WHERE usuario_id=$1`, usuarioID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, usuarioID int, hashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM codigos_recuperacion WHERE usuario_id=$1`, usuarioID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO codigos_recuperacion (usuario_id, codigo_hash) VALUES ($1, $2)`, usuarioID, h); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresTwoFactorRepository) UseRecoveryCode(ctx context.Context, usuarioID int, hash string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE codigos_recuperacion SET usado_en=NOW() WHERE usuario_id=$1 AND codigo_hash=$2 AND usado_en IS NULL`,
+		usuarioID, hash)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
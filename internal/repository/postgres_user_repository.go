@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresUserRepository implementa UserRepository sobre un *sql.DB compartido
+type PostgresUserRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+const userColumns = `id, nombre_completo, edad, rol, identificacion, correo, contrasena, acepta_tratamiento_datos, correo_verificado, estado, fecha_creacion,
+	COALESCE(correo_pendiente, ''), ARRAY(SELECT ur.rol FROM usuario_roles ur WHERE ur.usuario_id = usuarios.id ORDER BY ur.rol)`
+
+func (r *PostgresUserRepository) Create(ctx context.Context, u *models.User) error {
+	return insertUser(ctx, r.db, u)
+}
+
+// queryRower permite insertar usuarios con *sql.DB o dentro de una *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertUser crea el usuario y sus roles en una sola sentencia
+func insertUser(ctx context.Context, q queryRower, u *models.User) error {
+	query := `
+		WITH nuevo AS (
+			INSERT INTO usuarios
+			(nombre_completo, edad, rol, identificacion, correo, contrasena, acepta_tratamiento_datos, correo_verificado, estado)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, fecha_creacion
+		), roles AS (
+			INSERT INTO usuario_roles (usuario_id, rol)
+			SELECT nuevo.id, unnest($10::text[]) FROM nuevo
+		)
+		SELECT id, fecha_creacion FROM nuevo
+	`
+	u.Roles = u.RolesAsignados()
+
+	return q.QueryRowContext(ctx, query,
+		u.NombreCompleto,
+		u.Edad,
+		u.Rol,
+		u.Identificacion,
+		u.Correo,
+		u.ContrasenaHash,
+		u.AceptaTratamientoDatos,
+		u.CorreoVerificado,
+		u.Estado,
+		pq.Array(u.Roles),
+	).Scan(&u.ID, &u.FechaCreacion)
+}
+
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, correo string) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM usuarios WHERE correo=$1`, correo)
+	return scanUser(row)
+}
+
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id int) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM usuarios WHERE id=$1`, id)
+	return scanUser(row)
+}
+
+func (r *PostgresUserRepository) FindByIDs(ctx context.Context, ids []int) (map[int]*models.User, error) {
+	usuarios := make(map[int]*models.User, len(ids))
+	if len(ids) == 0 {
+		return usuarios, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM usuarios WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		usuarios[u.ID] = u
+	}
+	return usuarios, rows.Err()
+}
+
+func (r *PostgresUserRepository) FindByIdentificacion(ctx context.Context, identificacion string) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM usuarios WHERE identificacion=$1`, identificacion)
+	return scanUser(row)
+}
+
+func (r *PostgresUserRepository) Exists(ctx context.Context, correo, identificacion string) (bool, error) {
+	var existe bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM usuarios WHERE correo=$1 OR identificacion=$2)`,
+		correo, identificacion,
+	).Scan(&existe)
+	return existe, err
+}
+
+// Update guarda los datos del usuario y reemplaza sus roles por u.RolesAsignados()
+func (r *PostgresUserRepository) Update(ctx context.Context, u *models.User) error {
+	query := `
+		WITH actualizado AS (
+			UPDATE usuarios
+			SET nombre_completo=$1, edad=$2, rol=$3, identificacion=$4, correo=$5, contrasena=$6, acepta_tratamiento_datos=$7,
+				correo_verificado=$8, estado=$9, correo_pendiente=NULLIF($12, '')
+			WHERE id=$10
+			RETURNING id
+		), retirados AS (
+			DELETE FROM usuario_roles
+			WHERE usuario_id IN (SELECT id FROM actualizado) AND rol <> ALL($11::text[])
+		), asignados AS (
+			INSERT INTO usuario_roles (usuario_id, rol)
+			SELECT actualizado.id, unnest($11::text[]) FROM actualizado
+			ON CONFLICT DO NOTHING
+		)
+		SELECT COUNT(*) FROM actualizado
+	`
+
+	u.Roles = u.RolesAsignados()
+	var actualizados int
+	err := r.db.QueryRowContext(ctx, query,
+		u.NombreCompleto,
+		u.Edad,
+		u.Rol,
+		u.Identificacion,
+		u.Correo,
+		u.ContrasenaHash,
+		u.AceptaTratamientoDatos,
+		u.CorreoVerificado,
+		u.Estado,
+		u.ID,
+		pq.Array(u.Roles),
+		u.CorreoPendiente,
+	).Scan(&actualizados)
+	if err != nil {
+		return err
+	}
+	if actualizados == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) List(ctx context.Context, filtro UserFilter) ([]*models.User, int, error) {
+	var condiciones []string
+	var args []any
+	if filtro.Busqueda != "" {
+		args = append(args, "%"+escaparLike(filtro.Busqueda)+"%")
+		n := len(args)
+		condiciones = append(condiciones, fmt.Sprintf(
+			"(nombre_completo ILIKE $%d OR correo ILIKE $%d OR identificacion ILIKE $%d)", n, n, n))
+	}
+	if filtro.Rol != "" {
+		args = append(args, filtro.Rol)
+		condiciones = append(condiciones, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM usuario_roles ur WHERE ur.usuario_id = usuarios.id AND ur.rol=$%d)", len(args)))
+	}
+	if filtro.Estado != "" {
+		args = append(args, filtro.Estado)
+		condiciones = append(condiciones, fmt.Sprintf("estado=$%d", len(args)))
+	}
+	where := ""
+	if len(condiciones) > 0 {
+		where = " WHERE " + strings.Join(condiciones, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM usuarios`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filtro.Limit, filtro.Offset)
+	query := fmt.Sprintf(`SELECT `+userColumns+` FROM usuarios%s ORDER BY id LIMIT $%d OFFSET $%d`,
+		where, len(args)-1, len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var usuarios []*models.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		usuarios = append(usuarios, u)
+	}
+	return usuarios, total, rows.Err()
+}
+
+// escaparLike escapa los comodines de LIKE para buscar el texto literal
+func escaparLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// rowScanner permite reutilizar scanUser con *sql.Row y *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var u models.User
+	err := row.Scan(
+		&u.ID,
+		&u.NombreCompleto,
+		&u.Edad,
+		&u.Rol,
+		&u.Identificacion,
+		&u.Correo,
+		&u.ContrasenaHash,
+		&u.AceptaTratamientoDatos,
+		&u.CorreoVerificado,
+		&u.Estado,
+		&u.FechaCreacion,
+		&u.CorreoPendiente,
+		pq.Array(&u.Roles),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func expectOneRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
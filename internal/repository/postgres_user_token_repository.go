@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// PostgresUserTokenRepository implementa UserTokenRepository sobre PostgreSQL
+type PostgresUserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUserTokenRepository(db *sql.DB) *PostgresUserTokenRepository {
+	return &PostgresUserTokenRepository{db: db}
+}
+
+func (r *PostgresUserTokenRepository) Create(ctx context.Context, t *models.UserToken) error {
+	query := `
+		INSERT INTO tokens_usuario (usuario_id, proposito, token_hash, expira_en)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, creado_en
+	`
+	return r.db.QueryRowContext(ctx, query, t.UsuarioID, t.Proposito, t.TokenHash, t.ExpiraEn).
+		Scan(&t.ID, &t.CreadoEn)
+}
+
+const userTokenColumns = `id, usuario_id, proposito, token_hash, expira_en, creado_en, usado_en, intentos_fallidos`
+
+func (r *PostgresUserTokenRepository) FindByHash(ctx context.Context, proposito, tokenHash string) (*models.UserToken, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+userTokenColumns+` FROM tokens_usuario WHERE proposito=$1 AND token_hash=$2`,
+		proposito, tokenHash)
+	return scanUserToken(row)
+}
+
+func (r *PostgresUserTokenRepository) FindLatestForUser(ctx context.Context, usuarioID int, proposito string) (*models.UserToken, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+userTokenColumns+` FROM tokens_usuario WHERE usuario_id=$1 AND proposito=$2 ORDER BY creado_en DESC, id DESC LIMIT 1`,
+		usuarioID, proposito)
+	return scanUserToken(row)
+}
+
+func scanUserToken(row rowScanner) (*models.UserToken, error) {
+	var t models.UserToken
+	err := row.Scan(&t.ID, &t.UsuarioID, &t.Proposito, &t.TokenHash, &t.ExpiraEn, &t.CreadoEn, &t.UsadoEn, &t.IntentosFallidos)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PostgresUserTokenRepository) MarkUsed(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE tokens_usuario SET usado_en=NOW() WHERE id=$1 AND usado_en IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAlreadyUsed
+	}
+	return nil
+}
+
+func (r *PostgresUserTokenRepository) RegisterFailedAttempt(ctx context.Context, id int64) (int, error) {
+	var intentos int
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE tokens_usuario SET intentos_fallidos=intentos_fallidos+1 WHERE id=$1 RETURNING intentos_fallidos`, id).
+		Scan(&intentos)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return intentos, err
+}
+
+func (r *PostgresUserTokenRepository) InvalidateForUser(ctx context.Context, usuarioID int, proposito string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tokens_usuario SET usado_en=NOW() WHERE usuario_id=$1 AND proposito=$2 AND usado_en IS NULL`,
+		usuarioID, proposito)
+	return err
+}
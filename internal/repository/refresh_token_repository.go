@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// ErrAlreadyUsed se retorna cuando se intenta consumir un token de un solo uso
+// que ya fue consumido (o revocado) por otra petición.
+var ErrAlreadyUsed = errors.New("token ya utilizado")
+
+// RefreshTokenRepository define el acceso a la tabla tokens_refresco
+type RefreshTokenRepository interface {
+	// Create inserta el token y completa su ID y CreadoEn
+	Create(ctx context.Context, t *models.RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// MarkUsed marca el token como consumido de forma atómica; si ya estaba
+	// usado o revocado retorna ErrAlreadyUsed.
+	MarkUsed(ctx context.Context, id int64) error
+	// RevokeFamily revoca todos los tokens vigentes de una familia (sesión)
+	RevokeFamily(ctx context.Context, familiaID string) error
+	// RevokeAllForUser revoca todas las sesiones del usuario
+	RevokeAllForUser(ctx context.Context, usuarioID int) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// TokenRevocationRepository define el almacenamiento de la lista de
+// revocación de access tokens (por jti) y de las revocaciones por usuario.
+type TokenRevocationRepository interface {
+	RevokeToken(ctx context.Context, jti string, usuarioID int, expiraEn time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeUserTokens invalida todos los tokens del usuario emitidos antes de `desde`
+	RevokeUserTokens(ctx context.Context, usuarioID int, desde time.Time, motivo string) error
+	// UserRevokedSince retorna el instante de la última revocación masiva del
+	// usuario, o nil si nunca se revocaron sus tokens.
+	UserRevokedSince(ctx context.Context, usuarioID int) (*time.Time, error)
+	// PurgeExpired elimina las entradas cuyo token ya expiró
+	PurgeExpired(ctx context.Context) error
+}
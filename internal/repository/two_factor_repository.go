@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// TwoFactorRepository define el acceso a las tablas segundo_factor y
+// codigos_recuperacion
+type TwoFactorRepository interface {
+	// Get retorna el segundo factor del usuario; ErrNotFound si nunca se enroló
+	Get(ctx context.Context, usuarioID int) (*models.TwoFactor, error)
+	// SavePending guarda un secreto nuevo sin activar, reemplazando un
+	// enrolamiento pendiente anterior
+	SavePending(ctx context.Context, usuarioID int, secreto string) error
+	// Activate confirma el enrolamiento registrando el paso del código usado
+	Activate(ctx context.Context, usuarioID int, paso int64) error
+	// UseStep registra de forma atómica el paso de un código aceptado; si no es
+	// posterior al último usado retorna ErrAlreadyUsed
+	UseStep(ctx context.Context, usuarioID int, paso int64) error
+	// Delete desactiva el segundo factor y borra los códigos de recuperación
+	Delete(ctx context.Context, usuarioID int) error
+	// ReplaceRecoveryCodes reemplaza todos los códigos de recuperación del usuario
+	ReplaceRecoveryCodes(ctx context.Context, usuarioID int, hashes []string) error
+	// UseRecoveryCode consume un código sin usar; ErrNotFound si no existe o ya se usó
+	UseRecoveryCode(ctx context.Context, usuarioID int, hash string) error
+}
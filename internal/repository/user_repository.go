@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// ErrNotFound se retorna cuando el registro buscado no existe
+var ErrNotFound = errors.New("registro no encontrado")
+
+// UserRepository define el acceso a la tabla usuarios.
+// AuthService depende de esta interfaz para poder usar PostgreSQL en
+// producción y la implementación en memoria en pruebas.
+type UserRepository interface {
+	// Create inserta el usuario y completa su ID y FechaCreacion
+	Create(ctx context.Context, u *models.User) error
+	FindByEmail(ctx context.Context, correo string) (*models.User, error)
+	FindByID(ctx context.Context, id int) (*models.User, error)
+	// FindByIDs busca varios usuarios en una sola consulta. Los IDs que no
+	// existen no aparecen en el mapa resultante.
+	FindByIDs(ctx context.Context, ids []int) (map[int]*models.User, error)
+	FindByIdentificacion(ctx context.Context, identificacion string) (*models.User, error)
+	// Exists indica si ya hay un usuario con el correo o la identificación dados
+	Exists(ctx context.Context, correo, identificacion string) (bool, error)
+	// Update actualiza los datos editables del usuario identificado por u.ID
+	Update(ctx context.Context, u *models.User) error
+	// List retorna una página de usuarios que cumplen el filtro, ordenados por
+	// ID, y el total de usuarios que lo cumplen
+	List(ctx context.Context, filtro UserFilter) ([]*models.User, int, error)
+}
+
+// UserFilter filtra el listado de usuarios. Los campos vacíos no filtran.
+type UserFilter struct {
+	// Busqueda se compara, sin distinguir mayúsculas, con el nombre, el correo
+	// y la identificación
+	Busqueda string
+	Rol      string
+	Estado   string
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+)
+
+// UserTokenRepository define el acceso a la tabla tokens_usuario
+type UserTokenRepository interface {
+	// Create inserta el token y completa su ID y CreadoEn
+	Create(ctx context.Context, t *models.UserToken) error
+	FindByHash(ctx context.Context, proposito, tokenHash string) (*models.UserToken, error)
+	// FindLatestForUser retorna el último token emitido al usuario para ese
+	// propósito (usado o no); ErrNotFound si nunca se emitió uno
+	FindLatestForUser(ctx context.Context, usuarioID int, proposito string) (*models.UserToken, error)
+	// MarkUsed consume el token de forma atómica; si ya estaba usado retorna ErrAlreadyUsed
+	MarkUsed(ctx context.Context, id int64) error
+	// RegisterFailedAttempt suma un intento fallido al token de forma atómica
+	// y retorna el total
+	RegisterFailedAttempt(ctx context.Context, id int64) (int, error)
+	// InvalidateForUser consume todos los tokens pendientes del usuario para ese propósito
+	InvalidateForUser(ctx context.Context, usuarioID int, proposito string) error
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrEliminacionYaSolicitada  = errors.New("ACCOUNT_DELETION_ALREADY_SCHEDULED")
+	ErrTokenCancelacionInvalido = errors.New("INVALID_CANCELLATION_TOKEN")
+)
+
+const (
+	// intervaloEliminaciones es cada cuánto Run busca solicitudes vencidas
+	intervaloEliminaciones = 10 * time.Minute
+	// loteEliminaciones limita las cuentas anonimizadas en cada pasada
+	loteEliminaciones = 50
+)
+
+// AccountDeletionService implementa la eliminación de la cuenta y el retiro
+// del consentimiento de tratamiento de datos. Ambos bloquean el inicio de
+// sesión de inmediato y programan la anonimización para cuando termine el
+// periodo de gracia; entonces se anonimizan o borran los casos en el servicio
+// prediagnostic y se reemplazan los datos personales de la fila de usuarios.
+// Cada paso queda en la auditoría.
+type AccountDeletionService struct {
+	users               repository.UserRepository
+	deletions           repository.AccountDeletionRepository
+	tokens              repository.UserTokenRepository
+	twoFactors          repository.TwoFactorRepository
+	exports             repository.DataExportRepository
+	doctors             repository.DoctorProfileRepository
+	consents            repository.ConsentRepository
+	audit               repository.AuditRepository
+	authService         *AuthService
+	mail                clients.MailSender
+	prediagnosticClient clients.PrediagnosticAPI
+	gracePeriod         time.Duration
+	caseRetention       string
+	frontendURL         string
+}
+
+func NewAccountDeletionService(users repository.UserRepository, deletions repository.AccountDeletionRepository,
+	tokens repository.UserTokenRepository, twoFactors repository.TwoFactorRepository, exports repository.DataExportRepository,
+	doctors repository.DoctorProfileRepository, consents repository.ConsentRepository, audit repository.AuditRepository, authService *AuthService, mail clients.MailSender,
+	prediagnosticClient clients.PrediagnosticAPI, privacyCfg config.PrivacyConfig, frontendURL string) *AccountDeletionService {
+	return &AccountDeletionService{
+		users:               users,
+		deletions:           deletions,
+		tokens:              tokens,
+		twoFactors:          twoFactors,
+		exports:             exports,
+		doctors:             doctors,
+		consents:            consents,
+		audit:               audit,
+		authService:         authService,
+		mail:                mail,
+		prediagnosticClient: prediagnosticClient,
+		gracePeriod:         privacyCfg.DeletionGracePeriod,
+		caseRetention:       privacyCfg.CaseRetention,
+		frontendURL:         frontendURL,
+	}
+}
+
+// SolicitarEliminacion verifica la contraseña del usuario, bloquea su cuenta
+// y programa la anonimización. motivo es MotivoEliminarCuenta o
+// MotivoRetirarConsentimiento; este último además marca como retiradas las
+// aceptaciones del usuario en el registro de consentimientos. Se cierran todas las sesiones del usuario y se le envía un
+// enlace para cancelar la solicitud durante el periodo de gracia.
+func (s *AccountDeletionService) SolicitarEliminacion(ctx context.Context, usuarioID int, contrasena, motivo string) (*models.AccountDeletion, error) {
+	usuario, err := s.users.FindByID(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUsuarioNoEncontrado
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(usuario.ContrasenaHash), []byte(contrasena)); err != nil {
+		return nil, ErrContrasenaIncorrecta
+	}
+	if usuario.Estado == models.EstadoEliminacionPendiente || usuario.Estado == models.EstadoAnonimizado {
+		return nil, ErrEliminacionYaSolicitada
+	}
+
+	solicitud := &models.AccountDeletion{
+		UsuarioID:      usuario.ID,
+		Motivo:         motivo,
+		Estado:         models.EliminacionProgramada,
+		EstadoAnterior: usuario.Estado,
+		EjecutarEn:     time.Now().Add(s.gracePeriod),
+	}
+	if err := s.deletions.Create(ctx, solicitud); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrEliminacionYaSolicitada
+		}
+		return nil, err
+	}
+
+	usuario.Estado = models.EstadoEliminacionPendiente
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+	if motivo == models.MotivoRetirarConsentimiento {
+		if err := s.consents.Withdraw(ctx, usuario.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.authService.RevocarTokensUsuario(ctx, usuario.ID, "eliminación de cuenta solicitada"); err != nil {
+		return nil, err
+	}
+
+	evento := models.EventoEliminacionSolicitada
+	if motivo == models.MotivoRetirarConsentimiento {
+		evento = models.EventoConsentimientoRetirado
+	}
+	if err := s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: &usuario.ID,
+		Evento:    evento,
+		Detalle: map[string]interface{}{
+			"solicitud_id": solicitud.ID,
+			"ejecutar_en":  solicitud.EjecutarEn.UTC().Format(time.RFC3339),
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.enviarCancelacion(ctx, usuario, solicitud); err != nil {
+		return nil, err
+	}
+	return solicitud, nil
+}
+
+// enviarCancelacion envía el enlace con el que el usuario puede cancelar la
+// solicitud mientras no se ejecute
+func (s *AccountDeletionService) enviarCancelacion(ctx context.Context, usuario *models.User, solicitud *models.AccountDeletion) error {
+	if err := s.tokens.InvalidateForUser(ctx, usuario.ID, models.PropositoCancelarEliminacion); err != nil {
+		return err
+	}
+	token, err := generarTokenOpaco()
+	if err != nil {
+		return err
+	}
+	err = s.tokens.Create(ctx, &models.UserToken{
+		UsuarioID: usuario.ID,
+		Proposito: models.PropositoCancelarEliminacion,
+		TokenHash: hashToken(token),
+		ExpiraEn:  solicitud.EjecutarEn,
+	})
+	if err != nil {
+		return err
+	}
+
+	accion := "eliminar tu cuenta"
+	if solicitud.Motivo == models.MotivoRetirarConsentimiento {
+		accion = "retirar tu consentimiento para el tratamiento de tus datos"
+	}
+	casos := "se anonimizarán tus casos"
+	if s.caseRetention == "delete" {
+		casos = "se borrarán tus casos"
+	}
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.Correo,
+		Subject: "Solicitud de eliminación de tu cuenta",
+		Body: fmt.Sprintf(`Hola %s,
+
+Recibimos tu solicitud para %s. Tu cuenta quedó bloqueada y el %s
+se borrarán tus datos personales y %s.
+
+Si cambias de opinión, puedes cancelar la solicitud hasta esa fecha abriendo
+el siguiente enlace:
+
+%s/cancel-account-deletion?token=%s
+`, usuario.NombreCompleto, accion, solicitud.EjecutarEn.Format("02/01/2006 15:04"), casos, s.frontendURL, url.QueryEscape(token)),
+	})
+	return nil
+}
+
+// CancelarEliminacion consume el token enviado por correo y devuelve la
+// cuenta al estado que tenía antes de la solicitud. Retirar el consentimiento
+// y cancelar equivale a volver a otorgarlo: las aceptaciones retiradas
+// vuelven a ser vigentes.
+func (s *AccountDeletionService) CancelarEliminacion(ctx context.Context, token string) error {
+	stored, err := s.tokens.FindByHash(ctx, models.PropositoCancelarEliminacion, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTokenCancelacionInvalido
+		}
+		return err
+	}
+	if stored.UsadoEn != nil || time.Now().After(stored.ExpiraEn) {
+		return ErrTokenCancelacionInvalido
+	}
+	solicitud, err := s.deletions.FindScheduledForUser(ctx, stored.UsuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTokenCancelacionInvalido
+		}
+		return err
+	}
+
+	if err := s.tokens.MarkUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, repository.ErrAlreadyUsed) {
+			return ErrTokenCancelacionInvalido
+		}
+		return err
+	}
+
+	ahora := time.Now()
+	solicitud.Estado = models.EliminacionCancelada
+	solicitud.FinalizadaEn = &ahora
+	if err := s.deletions.Update(ctx, solicitud); err != nil {
+		return err
+	}
+
+	usuario, err := s.users.FindByID(ctx, solicitud.UsuarioID)
+	if err != nil {
+		return err
+	}
+	usuario.Estado = solicitud.EstadoAnterior
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return err
+	}
+	if solicitud.Motivo == models.MotivoRetirarConsentimiento {
+		if err := s.consents.Restore(ctx, usuario.ID); err != nil {
+			return err
+		}
+	}
+
+	return s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: &usuario.ID,
+		Evento:    models.EventoEliminacionCancelada,
+		Detalle: map[string]interface{}{
+			"solicitud_id": solicitud.ID,
+			"motivo":       solicitud.Motivo,
+		},
+	})
+}
+
+// Run ejecuta periódicamente las solicitudes cuyo periodo de gracia terminó.
+// Bloquea hasta que ctx se cancela.
+func (s *AccountDeletionService) Run(ctx context.Context) {
+	ticker := time.NewTicker(intervaloEliminaciones)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.EjecutarVencidas(ctx); err != nil {
+				log.Printf("Warning: no se pudieron ejecutar las eliminaciones de cuenta: %v", err)
+			}
+		}
+	}
+}
+
+// EjecutarVencidas anonimiza las cuentas cuyas solicitudes vencieron. Si una
+// falla (por ejemplo, el servicio prediagnostic no responde) queda programada
+// con el error y se reintenta en la siguiente pasada.
+func (s *AccountDeletionService) EjecutarVencidas(ctx context.Context) error {
+	solicitudes, err := s.deletions.ListDue(ctx, time.Now(), loteEliminaciones)
+	if err != nil {
+		return err
+	}
+	for _, solicitud := range solicitudes {
+		if err := s.ejecutar(ctx, solicitud); err != nil {
+			log.Printf("Error anonimizando la cuenta del usuario %d: %v", solicitud.UsuarioID, err)
+			solicitud.UltimoError = err.Error()
+			if err := s.deletions.Update(ctx, solicitud); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ejecutar procesa primero los casos en prediagnostic: si eso falla, la
+// cuenta sigue intacta y la solicitud se reintenta.
+func (s *AccountDeletionService) ejecutar(ctx context.Context, solicitud *models.AccountDeletion) error {
+	usuarioID := strconv.Itoa(solicitud.UsuarioID)
+	casos := "anonimizados"
+	if s.caseRetention == "delete" {
+		casos = "eliminados"
+		if err := s.prediagnosticClient.DeleteCasesByUserID(ctx, usuarioID); err != nil {
+			return err
+		}
+	} else if err := s.prediagnosticClient.AnonymizeCasesByUserID(ctx, usuarioID); err != nil {
+		return err
+	}
+
+	usuario, err := s.users.FindByID(ctx, solicitud.UsuarioID)
+	if err != nil {
+		return err
+	}
+	anonimizar(usuario)
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return err
+	}
+	if err := s.twoFactors.Delete(ctx, usuario.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err := s.exports.DeleteForUser(ctx, usuario.ID); err != nil {
+		return err
+	}
+	// La licencia, la especialidad y el motivo de rechazo identifican al
+	// doctor; de los consentimientos se conservan las versiones, no la IP, y
+	// quedan retirados también cuando se eliminó la cuenta
+	if err := s.doctors.DeleteForUser(ctx, usuario.ID); err != nil {
+		return err
+	}
+	if err := s.consents.Withdraw(ctx, usuario.ID); err != nil {
+		return err
+	}
+	if err := s.consents.AnonymizeForUser(ctx, usuario.ID); err != nil {
+		return err
+	}
+
+	ahora := time.Now()
+	solicitud.Estado = models.EliminacionEjecutada
+	solicitud.UltimoError = ""
+	solicitud.FinalizadaEn = &ahora
+	if err := s.deletions.Update(ctx, solicitud); err != nil {
+		return err
+	}
+
+	return s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: &usuario.ID,
+		Evento:    models.EventoCuentaAnonimizada,
+		Detalle: map[string]interface{}{
+			"solicitud_id": solicitud.ID,
+			"motivo":       solicitud.Motivo,
+			"casos":        casos,
+		},
+	})
+}
+
+// anonimizar reemplaza los datos personales del usuario. El correo y la
+// identificación siguen siendo únicos para no chocar con los índices, y la
+// contraseña vacía no coincide con ningún hash.
+func anonimizar(u *models.User) {
+	u.NombreCompleto = "Usuario eliminado"
+	u.Edad = 0
+	u.Identificacion = fmt.Sprintf("anonimizado-%d", u.ID)
+	u.Correo = fmt.Sprintf("anonimizado-%d@anonimizado.invalid", u.ID)
+	u.CorreoPendiente = ""
+	u.CorreoVerificado = false
+	u.ContrasenaHash = ""
+	u.AceptaTratamientoDatos = false
+	u.Estado = models.EstadoAnonimizado
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var (
+	ErrUsuarioNoEncontrado   = errors.New("USER_NOT_FOUND")
+	ErrRolInvalido           = errors.New("INVALID_ROLE")
+	ErrOperacionSobreSiMismo = errors.New("CANNOT_MODIFY_SELF")
+	ErrTransicionInvalida    = errors.New("INVALID_STATUS_TRANSITION")
+	ErrPerfilDoctorRequerido = errors.New("DOCTOR_PROFILE_REQUIRED")
+)
+
+const (
+	limiteUsuariosPorDefecto = 20
+	limiteUsuariosMaximo     = 100
+)
+
+// AdminService implementa la administración de usuarios reservada al rol
+// admin: listado, activación, cambio de rol y revisión de las solicitudes de
+// doctores. Cada cambio queda en la auditoría con el administrador que lo hizo.
+type AdminService struct {
+	users       repository.UserRepository
+	doctors     repository.DoctorProfileRepository
+	audit       repository.AuditRepository
+	authService *AuthService
+	mail        clients.MailSender
+}
+
+func NewAdminService(users repository.UserRepository, doctors repository.DoctorProfileRepository, audit repository.AuditRepository, authService *AuthService, mail clients.MailSender) *AdminService {
+	return &AdminService{
+		users:       users,
+		doctors:     doctors,
+		audit:       audit,
+		authService: authService,
+		mail:        mail,
+	}
+}
+
+// ListarUsuarios retorna una página de usuarios y el total que cumple el
+// filtro. El límite por defecto es 20 y no puede superar 100.
+func (s *AdminService) ListarUsuarios(ctx context.Context, filtro repository.UserFilter) ([]*models.User, int, error) {
+	if filtro.Rol != "" && !models.RolValido(filtro.Rol) {
+		return nil, 0, ErrRolInvalido
+	}
+	if filtro.Limit <= 0 {
+		filtro.Limit = limiteUsuariosPorDefecto
+	}
+	filtro.Limit = min(filtro.Limit, limiteUsuariosMaximo)
+	filtro.Offset = max(filtro.Offset, 0)
+	return s.users.List(ctx, filtro)
+}
+
+func (s *AdminService) ObtenerUsuario(ctx context.Context, id int) (*models.User, error) {
+	usuario, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUsuarioNoEncontrado
+		}
+		return nil, err
+	}
+	return usuario, nil
+}
+
+// CambiarActivo activa o desactiva la cuenta id. Desactivarla revoca todos sus
+// tokens y sesiones. Los doctores pendientes o rechazados se activan con
+// AprobarDoctor.
+// actorID es el administrador que hace el cambio (0 desde la CLI).
+func (s *AdminService) CambiarActivo(ctx context.Context, actorID, id int, activo bool) (*models.User, error) {
+	if actorID == id {
+		return nil, ErrOperacionSobreSiMismo
+	}
+	usuario, err := s.ObtenerUsuario(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// La cuenta de quien pidió eliminarla solo se recupera con el enlace de
+	// cancelación enviado al titular
+	if usuario.Estado == models.EstadoEliminacionPendiente || usuario.Estado == models.EstadoAnonimizado {
+		return nil, ErrTransicionInvalida
+	}
+
+	estado, evento := models.EstadoInactivo, models.EventoUsuarioDesactivado
+	if activo {
+		if usuario.Estado == models.EstadoPendienteAprobacion || usuario.Estado == models.EstadoRechazado {
+			return nil, ErrTransicionInvalida
+		}
+		estado, evento = models.EstadoActivo, models.EventoUsuarioActivado
+	}
+	if usuario.Estado == estado {
+		return usuario, nil
+	}
+
+	anterior := usuario.Estado
+	usuario.Estado = estado
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+	if !activo {
+		if err := s.authService.RevocarTokensUsuario(ctx, id, "cuenta desactivada"); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.registrar(ctx, actorID, usuario.ID, evento, map[string]interface{}{
+		"estado_anterior": anterior,
+	})
+	return usuario, err
+}
+
+// CambiarRol deja a rol como único rol del usuario id y revoca sus tokens,
+// que llevan los roles anteriores. Solo puede ser doctor quien tiene perfil de
+// doctor (licencia y especialidad), y una solicitud pendiente se revisa con
+// AprobarDoctor o RechazarDoctor.
+func (s *AdminService) CambiarRol(ctx context.Context, actorID, id int, rol string) (*models.User, error) {
+	usuario, err := s.usuarioParaRol(ctx, actorID, id, rol)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(usuario.RolesAsignados(), []string{rol}) {
+		return usuario, nil
+	}
+
+	anterior, anteriores := usuario.Rol, usuario.RolesAsignados()
+	usuario.Rol, usuario.Roles = rol, nil
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+	if err := s.authService.RevocarTokensUsuario(ctx, id, "cambio de rol"); err != nil {
+		return nil, err
+	}
+
+	err = s.registrar(ctx, actorID, usuario.ID, models.EventoRolCambiado, map[string]interface{}{
+		"rol_anterior":     anterior,
+		"roles_anteriores": anteriores,
+		"rol_nuevo":        rol,
+	})
+	return usuario, err
+}
+
+// AgregarRol suma rol a los roles del usuario id, con las mismas reglas que
+// CambiarRol. Los permisos nuevos aplican desde el siguiente access token.
+func (s *AdminService) AgregarRol(ctx context.Context, actorID, id int, rol string) (*models.User, error) {
+	usuario, err := s.usuarioParaRol(ctx, actorID, id, rol)
+	if err != nil {
+		return nil, err
+	}
+	if usuario.TieneRol(rol) {
+		return usuario, nil
+	}
+
+	usuario.Roles = append(usuario.RolesAsignados(), rol)
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+
+	err = s.registrar(ctx, actorID, usuario.ID, models.EventoRolAsignado, map[string]interface{}{
+		"rol": rol,
+	})
+	return usuario, err
+}
+
+// QuitarRol retira rol del usuario id y revoca sus tokens. El usuario debe
+// conservar al menos un rol; si se retira el principal, pasa a serlo el
+// siguiente.
+func (s *AdminService) QuitarRol(ctx context.Context, actorID, id int, rol string) (*models.User, error) {
+	if actorID == id {
+		return nil, ErrOperacionSobreSiMismo
+	}
+	usuario, err := s.ObtenerUsuario(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !usuario.TieneRol(rol) {
+		return usuario, nil
+	}
+	restantes := slices.DeleteFunc(usuario.RolesAsignados(), func(r string) bool { return r == rol })
+	if len(restantes) == 0 {
+		return nil, ErrTransicionInvalida
+	}
+
+	usuario.Rol, usuario.Roles = restantes[0], restantes
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+	if err := s.authService.RevocarTokensUsuario(ctx, id, "rol retirado"); err != nil {
+		return nil, err
+	}
+
+	err = s.registrar(ctx, actorID, usuario.ID, models.EventoRolRetirado, map[string]interface{}{
+		"rol": rol,
+	})
+	return usuario, err
+}
+
+// usuarioParaRol valida que se pueda asignar rol al usuario id y lo retorna
+func (s *AdminService) usuarioParaRol(ctx context.Context, actorID, id int, rol string) (*models.User, error) {
+	if !models.RolValido(rol) {
+		return nil, ErrRolInvalido
+	}
+	if actorID == id {
+		return nil, ErrOperacionSobreSiMismo
+	}
+	usuario, err := s.ObtenerUsuario(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if usuario.Estado == models.EstadoPendienteAprobacion || usuario.Estado == models.EstadoRechazado {
+		return nil, ErrTransicionInvalida
+	}
+	if rol == models.RolDoctor {
+		if _, err := s.doctors.Get(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrPerfilDoctorRequerido
+			}
+			return nil, err
+		}
+	}
+	return usuario, nil
+}
+
+// AprobarDoctor activa la cuenta de un doctor pendiente (o rechazado
+// anteriormente) y se lo notifica por correo.
+func (s *AdminService) AprobarDoctor(ctx context.Context, actorID, id int) (*models.User, error) {
+	usuario, err := s.doctorEnRevision(ctx, id, models.EstadoPendienteAprobacion, models.EstadoRechazado)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin perfil no hay licencia que verificar (cuentas de doctor anteriores a
+	// /register/doctor): deben volver a registrarse
+	if err := s.doctors.Review(ctx, id, revisor(actorID), ""); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPerfilDoctorRequerido
+		}
+		return nil, err
+	}
+	usuario.Estado = models.EstadoActivo
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+	if err := s.registrar(ctx, actorID, usuario.ID, models.EventoDoctorAprobado, nil); err != nil {
+		return nil, err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.Correo,
+		Subject: "Tu cuenta de doctor fue aprobada",
+		Body: fmt.Sprintf(`Hola %s,
+
+Un administrador verificó tu licencia médica y aprobó tu cuenta de doctor.
+Ya puedes iniciar sesión.
+`, usuario.NombreCompleto),
+	})
+	return usuario, nil
+}
+
+// RechazarDoctor rechaza la solicitud pendiente de un doctor indicando el
+// motivo, que se le envía por correo.
+func (s *AdminService) RechazarDoctor(ctx context.Context, actorID, id int, motivo string) (*models.User, error) {
+	motivo = strings.TrimSpace(motivo)
+	if motivo == "" {
+		return nil, ErrDatosEnviados
+	}
+	usuario, err := s.doctorEnRevision(ctx, id, models.EstadoPendienteAprobacion)
+	if err != nil {
+		return nil, err
+	}
+
+	// Una cuenta de doctor sin perfil también se puede rechazar; el motivo
+	// queda en la auditoría y en el correo
+	if err := s.doctors.Review(ctx, id, revisor(actorID), motivo); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	usuario.Estado = models.EstadoRechazado
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+	err = s.registrar(ctx, actorID, usuario.ID, models.EventoDoctorRechazado, map[string]interface{}{
+		"motivo": motivo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.Correo,
+		Subject: "Tu solicitud de cuenta de doctor fue rechazada",
+		Body: fmt.Sprintf(`Hola %s,
+
+Un administrador revisó tu solicitud de cuenta de doctor y no pudo aprobarla.
+
+Motivo: %s
+`, usuario.NombreCompleto, motivo),
+	})
+	return usuario, nil
+}
+
+// doctorEnRevision retorna el usuario id si es un doctor en alguno de los estados dados
+func (s *AdminService) doctorEnRevision(ctx context.Context, id int, estados ...string) (*models.User, error) {
+	usuario, err := s.ObtenerUsuario(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !usuario.TieneRol(models.RolDoctor) || !slices.Contains(estados, usuario.Estado) {
+		return nil, ErrTransicionInvalida
+	}
+	return usuario, nil
+}
+
+// revisor convierte el actor en el revisado_por de la solicitud (nil desde la CLI)
+func revisor(actorID int) *int {
+	if actorID == 0 {
+		return nil
+	}
+	return &actorID
+}
+
+// registrar deja el cambio en la auditoría; "por" es el administrador o "cli"
+func (s *AdminService) registrar(ctx context.Context, actorID, usuarioID int, evento string, detalle map[string]interface{}) error {
+	if detalle == nil {
+		detalle = map[string]interface{}{}
+	}
+	detalle["por"] = "cli"
+	if actorID != 0 {
+		detalle["por"] = actorID
+	}
+	return s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: &usuarioID,
+		Evento:    evento,
+		Detalle:   detalle,
+	})
+}
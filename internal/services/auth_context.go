@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoAutenticado indica que la petición no trae un access token
+var ErrNoAutenticado = errors.New("token de autorización requerido")
+
+// authContextKey es la clave tipada con la que se guarda la autenticación en el contexto
+type authContextKey struct{}
+
+// autenticacion es el resultado de validar el header Authorization de la
+// petición. consentimiento es el resultado de ConsentService.Comprobar para el
+// usuario autenticado.
+type autenticacion struct {
+	claims         *UserClaims
+	err            error
+	consentimiento error
+}
+
+// ContextWithClaims guarda en el contexto los claims del access token validado
+func ContextWithClaims(ctx context.Context, claims *UserClaims) context.Context {
+	return context.WithValue(ctx, authContextKey{}, autenticacion{claims: claims})
+}
+
+// ContextWithConsentError guarda en el contexto los claims del access token y
+// por qué el usuario no puede usar las operaciones que exigen haber aceptado
+// la política vigente (normalmente un *ConsentimientoPendienteError)
+func ContextWithConsentError(ctx context.Context, claims *UserClaims, err error) context.Context {
+	return context.WithValue(ctx, authContextKey{}, autenticacion{claims: claims, consentimiento: err})
+}
+
+// ContextWithAuthError guarda en el contexto por qué falló la validación del
+// token, para informarlo en las operaciones que requieren autenticación
+func ContextWithAuthError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, authContextKey{}, autenticacion{err: err})
+}
+
+// ClaimsFromContext retorna los claims del usuario autenticado, el error de
+// validación del token o ErrNoAutenticado si la petición no traía token
+func ClaimsFromContext(ctx context.Context) (*UserClaims, error) {
+	a, ok := ctx.Value(authContextKey{}).(autenticacion)
+	if !ok {
+		return nil, ErrNoAutenticado
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a.claims, nil
+}
+
+// ConsentFromContext retorna nil si el usuario autenticado aceptó la versión
+// vigente de la política, o el error guardado con ContextWithConsentError
+func ConsentFromContext(ctx context.Context) error {
+	a, _ := ctx.Value(authContextKey{}).(autenticacion)
+	return a.consentimiento
+}
+
+// clientIPContextKey es la clave tipada de la IP del cliente en el contexto
+type clientIPContextKey struct{}
+
+// ContextWithClientIP guarda la IP del cliente, que se registra al aceptar la
+// política de tratamiento de datos
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// ClientIPFromContext retorna la IP guardada con ContextWithClientIP
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey{}).(string)
+	return ip
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const contrasenaPrueba = "contrasena-segura"
+
+// nuevoAuthService arma AuthService con los repositorios en memoria y una
+// clave ES256 en un directorio temporal
+func nuevoAuthService(t *testing.T) (*AuthService, *repository.MemoryUserRepository) {
+	t.Helper()
+
+	jwtCfg := config.JWTConfig{
+		Algorithm:           "ES256",
+		KeysDir:             t.TempDir(),
+		Issuer:              "businesslogic",
+		Audience:            []string{"businesslogic"},
+		KeyRotationInterval: time.Hour,
+		TokenTTL:            15 * time.Minute,
+		RefreshTokenTTL:     time.Hour,
+	}
+	keys, err := NewKeyManager(jwtCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := repository.NewMemoryUserRepository()
+	tokens := repository.NewMemoryUserTokenRepository()
+	lockout := NewLockoutService(repository.NewMemoryLoginAttemptRepository(), repository.NewMemoryAuditRepository(),
+		users, tokens, nil, config.Default().Auth.Lockout, "http://localhost:3000")
+	twoFactor := NewTwoFactorService(repository.NewMemoryTwoFactorRepository(), tokens, users, lockout, config.TwoFactorConfig{})
+	revocations := NewRevocationService(repository.NewMemoryTokenRevocationRepository(), time.Minute)
+
+	auth := NewAuthService(users, repository.NewMemoryRefreshTokenRepository(), revocations, keys, lockout, twoFactor, jwtCfg)
+	return auth, users
+}
+
+// crearUsuarioActivo guarda un paciente activo con el correo verificado
+func crearUsuarioActivo(t *testing.T, users repository.UserRepository, correo string) *models.User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(contrasenaPrueba), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usuario := &models.User{
+		NombreCompleto:   "Usuario de prueba",
+		Edad:             30,
+		Rol:              models.RolPaciente,
+		Identificacion:   correo,
+		Correo:           correo,
+		ContrasenaHash:   string(hash),
+		CorreoVerificado: true,
+		Estado:           models.EstadoActivo,
+	}
+	if err := users.Create(context.Background(), usuario); err != nil {
+		t.Fatal(err)
+	}
+	return usuario
+}
+
+func TestRefrescarSesionFamilias(t *testing.T) {
+	// Cada paso refresca el token guardado con el nombre usar y, si el
+	// refresh es correcto, guarda el nuevo como guardar. Las sesiones "a1" y
+	// "b1" se abren con dos logins del mismo usuario.
+	type paso struct {
+		usar, guardar string
+		err           error
+	}
+	casos := []struct {
+		nombre string
+		pasos  []paso
+	}{
+		{"rotaciones sucesivas", []paso{
+			{"a1", "a2", nil}, {"a2", "a3", nil}, {"a3", "a4", nil},
+		}},
+		{"reutilizar un token rotado revoca la familia", []paso{
+			{"a1", "a2", nil}, {"a1", "", ErrRefreshTokenReutilizado}, {"a2", "", ErrRefreshTokenInvalido},
+		}},
+		{"reutilizar el token más antiguo también revoca la familia", []paso{
+			{"a1", "a2", nil}, {"a2", "a3", nil}, {"a1", "", ErrRefreshTokenReutilizado}, {"a3", "", ErrRefreshTokenInvalido},
+		}},
+		{"la revocación no afecta a otra sesión", []paso{
+			{"a1", "a2", nil}, {"a1", "", ErrRefreshTokenReutilizado}, {"b1", "b2", nil}, {"b2", "b3", nil},
+		}},
+		{"token desconocido", []paso{{"desconocido", "", ErrRefreshTokenInvalido}}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			ctx := context.Background()
+			auth, users := nuevoAuthService(t)
+			usuario := crearUsuarioActivo(t, users, "familia@example.com")
+
+			tokens := map[string]string{"desconocido": "no-emitido"}
+			for _, sesion := range []string{"a1", "b1"} {
+				_, par, _, err := auth.IniciarSesion(ctx, usuario.Correo, contrasenaPrueba, "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				tokens[sesion] = par.RefreshToken
+			}
+
+			for i, p := range c.pasos {
+				_, par, err := auth.RefrescarSesion(ctx, tokens[p.usar])
+				if !errors.Is(err, p.err) {
+					t.Fatalf("paso %d (%s): se esperaba %v, se obtuvo %v", i+1, p.usar, p.err, err)
+				}
+				if err == nil {
+					tokens[p.guardar] = par.RefreshToken
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+)
+
+// ErrCursorInvalido indica que el cursor after no lo generó este listado o
+// se generó con otro orden
+var ErrCursorInvalido = errors.New("INVALID_CURSOR")
+
+const (
+	limiteCasosPorDefecto = 20
+	limiteCasosMaximo     = 100
+)
+
+// Campos por los que se puede ordenar el listado de casos
+const (
+	OrdenCasoFechaSubida  = "fecha_subida"
+	OrdenCasoProbNeumonia = "prob_neumonia"
+	OrdenCasoEstado       = "estado"
+)
+
+// CaseFilter filtra el listado de casos. Los campos vacíos no filtran.
+type CaseFilter struct {
+	// PacienteID se envía al servicio prediagnostic
+	// (GET /prediagnostic/cases/{user_id}); el resto de filtros los aplica
+	// CaseService porque el servicio no los soporta
+	PacienteID string
+	// Estado y Etiqueta se comparan sin distinguir mayúsculas con los valores
+	// que muestra Case (por ejemplo "Pendiente")
+	Estado         string
+	Etiqueta       string
+	DoctorAsignado string
+	// FechaDesde y FechaHasta acotan la fecha de subida; FechaHasta es exclusiva
+	FechaDesde *time.Time
+	FechaHasta *time.Time
+	// ProbMin y ProbMax acotan, inclusive, la probabilidad de neumonía del
+	// modelo; los casos sin resultados no los cumplen
+	ProbMin *float64
+	ProbMax *float64
+}
+
+// CaseOrder ordena el listado de casos. El ID del caso desempata, para que
+// el orden y los cursores sean estables entre páginas.
+type CaseOrder struct {
+	Campo       string
+	Descendente bool
+}
+
+// CasePage es una página del listado de casos con un cursor por caso
+type CasePage struct {
+	Casos              []*model.Case
+	Cursores           []string
+	Total              int
+	HayPaginaSiguiente bool
+	HayPaginaAnterior  bool
+}
+
+// claveCaso son los valores por los que se ordena un caso. Es también el
+// contenido del cursor, así una página sigue siendo válida aunque se creen o
+// eliminen casos entre una consulta y la siguiente.
+type claveCaso struct {
+	Campo       string    `json:"c"`
+	Descendente bool      `json:"d,omitempty"`
+	Fecha       time.Time `json:"f"`
+	Prob        float64   `json:"p"`
+	Estado      string    `json:"e,omitempty"`
+	ID          string    `json:"id"`
+}
+
+type casoListado struct {
+	caso  *model.Case
+	clave claveCaso
+}
+
+// ListCases retorna una página de los casos que cumplen el filtro, después
+// del cursor after. Sin first se usan limiteCasosPorDefecto casos; first se
+// limita a limiteCasosMaximo y con 0 solo se calcula el total.
+func (s *CaseService) ListCases(ctx context.Context, filtro CaseFilter, orden CaseOrder, first *int, after string) (*CasePage, error) {
+	if orden.Campo == "" {
+		orden = CaseOrder{Campo: OrdenCasoFechaSubida, Descendente: true}
+	}
+	if orden.Campo != OrdenCasoFechaSubida && orden.Campo != OrdenCasoProbNeumonia && orden.Campo != OrdenCasoEstado {
+		return nil, fmt.Errorf("%w: orden %q no soportado", ErrDatosEnviados, orden.Campo)
+	}
+	limite := limiteCasosPorDefecto
+	if first != nil {
+		if *first < 0 {
+			return nil, fmt.Errorf("%w: first no puede ser negativo", ErrDatosEnviados)
+		}
+		limite = min(*first, limiteCasosMaximo)
+	}
+
+	var desde *claveCaso
+	if after != "" {
+		clave, err := decodificarCursorCaso(after)
+		if err != nil || clave.Campo != orden.Campo || clave.Descendente != orden.Descendente {
+			return nil, ErrCursorInvalido
+		}
+		desde = clave
+	}
+
+	casos, err := s.casosParaListar(ctx, filtro.PacienteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var listados []casoListado
+	for _, c := range casos {
+		if cumpleFiltro(c.caso, c.clave.Fecha, filtro) {
+			c.clave.Campo = orden.Campo
+			c.clave.Descendente = orden.Descendente
+			listados = append(listados, c)
+		}
+	}
+	slices.SortFunc(listados, func(a, b casoListado) int { return compararClaves(a.clave, b.clave) })
+
+	inicio := 0
+	if desde != nil {
+		inicio = len(listados)
+		for i, c := range listados {
+			if compararClaves(c.clave, *desde) > 0 {
+				inicio = i
+				break
+			}
+		}
+	}
+	fin := min(inicio+limite, len(listados))
+
+	page := &CasePage{
+		Total:              len(listados),
+		HayPaginaSiguiente: fin < len(listados),
+		HayPaginaAnterior:  inicio > 0,
+	}
+	for _, c := range listados[inicio:fin] {
+		cursor, err := codificarCursorCaso(c.clave)
+		if err != nil {
+			return nil, err
+		}
+		page.Casos = append(page.Casos, c.caso)
+		page.Cursores = append(page.Cursores, cursor)
+	}
+
+	if err := s.completarPacientes(ctx, page.Casos); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// casosParaListar obtiene los casos del servicio prediagnostic: solo los del
+// paciente si se filtra por él, o todos
+func (s *CaseService) casosParaListar(ctx context.Context, pacienteID string) ([]casoListado, error) {
+	var rawCases []clients.CaseSummaryDTO
+	var err error
+	if pacienteID != "" {
+		rawCases, err = s.prediagnosticClient.GetCasesByUserID(ctx, pacienteID)
+		if errors.Is(err, clients.ErrSinRadiografias) {
+			return nil, nil
+		}
+	} else {
+		rawCases, err = s.prediagnosticClient.GetCases(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo casos del servicio prediagnostic: %w", err)
+	}
+
+	casos := make([]casoListado, 0, len(rawCases))
+	for _, rawCase := range rawCases {
+		caso, err := s.processAndStandardizeCase(rawCase)
+		if err != nil {
+			continue
+		}
+		clave := claveCaso{Prob: -1, Estado: strings.ToLower(caso.Estado), ID: caso.ID}
+		if rawCase.Fecha != nil {
+			clave.Fecha, _ = time.Parse(time.RFC3339, *rawCase.Fecha)
+		}
+		if caso.Resultados != nil {
+			clave.Prob = caso.Resultados.ProbNeumonia
+		}
+		casos = append(casos, casoListado{caso: caso, clave: clave})
+	}
+	return casos, nil
+}
+
+func cumpleFiltro(c *model.Case, fecha time.Time, filtro CaseFilter) bool {
+	if filtro.Estado != "" && !strings.EqualFold(c.Estado, filtro.Estado) {
+		return false
+	}
+	if filtro.DoctorAsignado != "" && (c.DoctorAsignado == nil || !strings.EqualFold(*c.DoctorAsignado, filtro.DoctorAsignado)) {
+		return false
+	}
+	// Una fecha que no se pudo interpretar no cumple ningún rango
+	if filtro.FechaDesde != nil && (fecha.IsZero() || fecha.Before(*filtro.FechaDesde)) {
+		return false
+	}
+	if filtro.FechaHasta != nil && (fecha.IsZero() || !fecha.Before(*filtro.FechaHasta)) {
+		return false
+	}
+	if filtro.Etiqueta != "" || filtro.ProbMin != nil || filtro.ProbMax != nil {
+		if c.Resultados == nil {
+			return false
+		}
+		if filtro.Etiqueta != "" && !strings.EqualFold(c.Resultados.Etiqueta, filtro.Etiqueta) {
+			return false
+		}
+		if filtro.ProbMin != nil && c.Resultados.ProbNeumonia < *filtro.ProbMin {
+			return false
+		}
+		if filtro.ProbMax != nil && c.Resultados.ProbNeumonia > *filtro.ProbMax {
+			return false
+		}
+	}
+	return true
+}
+
+// compararClaves ordena por el campo de la clave y luego por ID. En orden
+// descendente se invierte la comparación completa, incluido el desempate.
+func compararClaves(a, b claveCaso) int {
+	var c int
+	switch a.Campo {
+	case OrdenCasoFechaSubida:
+		c = a.Fecha.Compare(b.Fecha)
+	case OrdenCasoProbNeumonia:
+		c = cmp.Compare(a.Prob, b.Prob)
+	case OrdenCasoEstado:
+		c = strings.Compare(a.Estado, b.Estado)
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+	if a.Descendente {
+		return -c
+	}
+	return c
+}
+
+func codificarCursorCaso(clave claveCaso) (string, error) {
+	datos, err := json.Marshal(clave)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(datos), nil
+}
+
+func decodificarCursorCaso(cursor string) (*claveCaso, error) {
+	datos, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var clave claveCaso
+	if err := json.Unmarshal(datos, &clave); err != nil {
+		return nil, err
+	}
+	return &clave, nil
+}
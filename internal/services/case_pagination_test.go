@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients/prediagnostictest"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+// nuevoCaseService arma CaseService sobre el servicio prediagnostic falso con
+// los casos dados
+func nuevoCaseService(t *testing.T, casos []prediagnostictest.Caso) (*CaseService, *prediagnostictest.Server) {
+	t.Helper()
+
+	prediagnostic := prediagnostictest.NewServer()
+	t.Cleanup(prediagnostic.Close)
+	for _, c := range casos {
+		prediagnostic.Service.AgregarCaso(c)
+	}
+	return NewCaseService(prediagnostic.PrediagnosticClient(), prediagnostic.Config(), repository.NewMemoryUserRepository()), prediagnostic
+}
+
+// recorrerPaginas pide páginas de tamaño first hasta que no hay siguiente y
+// retorna los IDs en el orden recibido
+func recorrerPaginas(t *testing.T, s *CaseService, orden CaseOrder, first int) []string {
+	t.Helper()
+
+	var ids []string
+	after := ""
+	for pagina := 1; ; pagina++ {
+		page, err := s.ListCases(context.Background(), CaseFilter{}, orden, &first, after)
+		if err != nil {
+			t.Fatalf("página %d: %v", pagina, err)
+		}
+		if page.HayPaginaAnterior != (after != "") {
+			t.Fatalf("página %d: HayPaginaAnterior = %v", pagina, page.HayPaginaAnterior)
+		}
+		for _, c := range page.Casos {
+			ids = append(ids, c.ID)
+		}
+		if !page.HayPaginaSiguiente {
+			return ids
+		}
+		if len(page.Cursores) == 0 || pagina > 100 {
+			t.Fatalf("página %d: HayPaginaSiguiente sin cursores", pagina)
+		}
+		after = page.Cursores[len(page.Cursores)-1]
+	}
+}
+
+func TestListCasesCursores(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, _ := nuevoCaseService(t, []prediagnostictest.Caso{
+		{ID: "RAD-1", UserID: "1", Estado: "pending", FechaSubida: base},
+		{ID: "RAD-2", UserID: "1", Estado: "completed", FechaSubida: base.Add(time.Hour), ProbNeumonia: 0.8, Etiqueta: "Neumonia"},
+		{ID: "RAD-3", UserID: "2", Estado: "completed", FechaSubida: base.Add(2 * time.Hour), ProbNeumonia: 0.2, Etiqueta: "Normal"},
+		// Misma fecha y probabilidad que RAD-3: desempata el ID
+		{ID: "RAD-4", UserID: "2", Estado: "completed", FechaSubida: base.Add(2 * time.Hour), ProbNeumonia: 0.2, Etiqueta: "Normal"},
+		{ID: "RAD-5", UserID: "3", Estado: "validado", FechaSubida: base.Add(3 * time.Hour), ProbNeumonia: 0.5, Etiqueta: "Neumonia"},
+	})
+
+	casos := []struct {
+		nombre string
+		orden  CaseOrder
+		ids    []string
+	}{
+		{"fecha de subida descendente por defecto", CaseOrder{}, []string{"RAD-5", "RAD-4", "RAD-3", "RAD-2", "RAD-1"}},
+		{"fecha de subida ascendente", CaseOrder{Campo: OrdenCasoFechaSubida}, []string{"RAD-1", "RAD-2", "RAD-3", "RAD-4", "RAD-5"}},
+		// Los casos sin resultados tienen probabilidad -1
+		{"probabilidad ascendente", CaseOrder{Campo: OrdenCasoProbNeumonia}, []string{"RAD-1", "RAD-3", "RAD-4", "RAD-5", "RAD-2"}},
+		{"probabilidad descendente", CaseOrder{Campo: OrdenCasoProbNeumonia, Descendente: true}, []string{"RAD-2", "RAD-5", "RAD-4", "RAD-3", "RAD-1"}},
+	}
+	for _, c := range casos {
+		for _, first := range []int{1, 2, 5} {
+			t.Run(fmt.Sprintf("%s, first=%d", c.nombre, first), func(t *testing.T) {
+				ids := recorrerPaginas(t, s, c.orden, first)
+				if !slices.Equal(ids, c.ids) {
+					t.Fatalf("se esperaba %v, se obtuvo %v", c.ids, ids)
+				}
+			})
+		}
+	}
+}
+
+func TestListCasesCursorEstableAnteCambios(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, prediagnostic := nuevoCaseService(t, []prediagnostictest.Caso{
+		{ID: "RAD-1", Estado: "pending", FechaSubida: base},
+		{ID: "RAD-2", Estado: "pending", FechaSubida: base.Add(time.Hour)},
+		{ID: "RAD-3", Estado: "pending", FechaSubida: base.Add(2 * time.Hour)},
+	})
+	ctx := context.Background()
+	first := 2
+
+	primera, err := s.ListCases(ctx, CaseFilter{}, CaseOrder{}, &first, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Un caso nuevo queda antes del cursor y no desplaza la página siguiente
+	prediagnostic.Service.AgregarCaso(prediagnostictest.Caso{ID: "RAD-4", Estado: "pending", FechaSubida: base.Add(3 * time.Hour)})
+
+	segunda, err := s.ListCases(ctx, CaseFilter{}, CaseOrder{}, &first, primera.Cursores[len(primera.Cursores)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segunda.Casos) != 1 || segunda.Casos[0].ID != "RAD-1" {
+		t.Fatalf("se esperaba solo RAD-1 en la segunda página, se obtuvo %d casos", len(segunda.Casos))
+	}
+	if segunda.Total != 4 {
+		t.Fatalf("Total = %d, se esperaba 4", segunda.Total)
+	}
+}
+
+func TestListCasesParametrosInvalidos(t *testing.T) {
+	s, _ := nuevoCaseService(t, []prediagnostictest.Caso{
+		{ID: "RAD-1", Estado: "pending", FechaSubida: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+	})
+	ctx := context.Background()
+
+	uno := 1
+	page, err := s.ListCases(ctx, CaseFilter{}, CaseOrder{}, &uno, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursorFecha := page.Cursores[0]
+	negativo, cero := -1, 0
+
+	casos := []struct {
+		nombre string
+		orden  CaseOrder
+		first  *int
+		after  string
+		err    error
+	}{
+		{"cursor de otro campo de orden", CaseOrder{Campo: OrdenCasoProbNeumonia, Descendente: true}, nil, cursorFecha, ErrCursorInvalido},
+		{"cursor de otra dirección", CaseOrder{Campo: OrdenCasoFechaSubida}, nil, cursorFecha, ErrCursorInvalido},
+		{"cursor que no es base64", CaseOrder{}, nil, "no es un cursor!", ErrCursorInvalido},
+		{"cursor que no es JSON", CaseOrder{}, nil, "bm8tanNvbg", ErrCursorInvalido},
+		{"first negativo", CaseOrder{}, &negativo, "", ErrDatosEnviados},
+		{"orden no soportado", CaseOrder{Campo: "paciente"}, nil, "", ErrDatosEnviados},
+		{"first cero solo cuenta", CaseOrder{}, &cero, "", nil},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			page, err := s.ListCases(ctx, CaseFilter{}, c.orden, c.first, c.after)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("se esperaba %v, se obtuvo %v", c.err, err)
+			}
+			if err == nil && (len(page.Casos) != 0 || page.Total != 1 || !page.HayPaginaSiguiente) {
+				t.Fatalf("con first 0 se esperaba solo el total, se obtuvo %d casos y total %d", len(page.Casos), page.Total)
+			}
+		})
+	}
+}
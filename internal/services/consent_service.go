@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var (
+	ErrConsentimientoRequerido       = errors.New("CONSENT_REQUIRED")
+	ErrVersionPoliticaDesactualizada = errors.New("POLICY_VERSION_OUTDATED")
+	ErrSinPoliticaVigente            = errors.New("NO_PRIVACY_POLICY")
+)
+
+// ConsentimientoPendienteError indica que el usuario no ha aceptado la versión
+// vigente de la política de tratamiento de datos
+type ConsentimientoPendienteError struct {
+	Version int
+}
+
+func (e *ConsentimientoPendienteError) Error() string { return ErrConsentimientoRequerido.Error() }
+func (e *ConsentimientoPendienteError) Unwrap() error { return ErrConsentimientoRequerido }
+
+// ConsentService administra las versiones de la política de tratamiento de
+// datos y el registro de quién aceptó cada una, cuándo y desde qué IP.
+type ConsentService struct {
+	consents repository.ConsentRepository
+	audit    repository.AuditRepository
+}
+
+func NewConsentService(consents repository.ConsentRepository, audit repository.AuditRepository) *ConsentService {
+	return &ConsentService{consents: consents, audit: audit}
+}
+
+// PoliticaVigente retorna la versión vigente de la política
+func (s *ConsentService) PoliticaVigente(ctx context.Context) (*models.PrivacyPolicy, error) {
+	politica, err := s.consents.CurrentPolicy(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSinPoliticaVigente
+		}
+		return nil, err
+	}
+	return politica, nil
+}
+
+// Comprobar retorna un *ConsentimientoPendienteError si el usuario no aceptó
+// la versión vigente. Sin ninguna política publicada no hay nada que aceptar.
+func (s *ConsentService) Comprobar(ctx context.Context, usuarioID int) error {
+	version, aceptada, err := s.consents.CurrentVersionForUser(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !aceptada {
+		return &ConsentimientoPendienteError{Version: version}
+	}
+	return nil
+}
+
+// Aceptar registra que el usuario aceptó la versión dada, que debe ser la
+// vigente: así no se registra la aceptación de un texto que el usuario no vio.
+// Aceptar de nuevo una versión ya aceptada retorna el registro existente.
+func (s *ConsentService) Aceptar(ctx context.Context, usuarioID, version int, ip string) (*models.Consent, error) {
+	politica, err := s.PoliticaVigente(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version != politica.Version {
+		return nil, ErrVersionPoliticaDesactualizada
+	}
+
+	consentimiento := &models.Consent{UsuarioID: usuarioID, Version: version, IP: ip}
+	if err := s.consents.RecordAcceptance(ctx, consentimiento); err != nil {
+		if !errors.Is(err, repository.ErrDuplicate) {
+			return nil, err
+		}
+		historial, err := s.consents.ListForUser(ctx, usuarioID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range historial {
+			if c.Version == version {
+				return c, nil
+			}
+		}
+		return nil, repository.ErrNotFound
+	}
+	return consentimiento, nil
+}
+
+// AceptarVigente registra la aceptación de la versión vigente; se usa al
+// registrarse, cuando el formulario ya exige aceptar la política
+func (s *ConsentService) AceptarVigente(ctx context.Context, usuarioID int, ip string) error {
+	politica, err := s.PoliticaVigente(ctx)
+	if err != nil {
+		if errors.Is(err, ErrSinPoliticaVigente) {
+			return nil
+		}
+		return err
+	}
+	_, err = s.Aceptar(ctx, usuarioID, politica.Version, ip)
+	return err
+}
+
+// Historial retorna las versiones que aceptó el usuario, la más reciente primero
+func (s *ConsentService) Historial(ctx context.Context, usuarioID int) ([]*models.Consent, error) {
+	return s.consents.ListForUser(ctx, usuarioID)
+}
+
+// Publicar crea una versión nueva de la política. Desde ese momento todos los
+// usuarios deben aceptarla antes de seguir usando GraphQL.
+// actorID es el administrador que la publica.
+func (s *ConsentService) Publicar(ctx context.Context, actorID int, contenido string) (*models.PrivacyPolicy, error) {
+	contenido = strings.TrimSpace(contenido)
+	if contenido == "" {
+		return nil, ErrDatosEnviados
+	}
+
+	politica := &models.PrivacyPolicy{Contenido: contenido, PublicadaPor: &actorID}
+	if err := s.consents.PublishPolicy(ctx, politica); err != nil {
+		return nil, err
+	}
+
+	err := s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: &actorID,
+		Evento:    models.EventoPoliticaPublicada,
+		Detalle:   map[string]interface{}{"version": politica.Version},
+	})
+	return politica, err
+}
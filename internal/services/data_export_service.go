@@ -0,0 +1,459 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var (
+	ErrExportacionNoEncontrada = errors.New("EXPORT_NOT_FOUND")
+	ErrExportacionNoDisponible = errors.New("EXPORT_NOT_READY")
+	ErrExportacionMuyGrande    = errors.New("EXPORT_TOO_LARGE")
+)
+
+// intervaloPurgaExportaciones es cada cuánto Run borra los ZIP expirados
+const intervaloPurgaExportaciones = 10 * time.Minute
+
+// nombreArchivoInvalido reemplaza lo que no debe ir en una ruta del ZIP
+var nombreArchivoInvalido = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// DataExportService arma, a pedido del titular, un ZIP con todos sus datos
+// personales: el registro de usuario, sus aceptaciones de la política de
+// tratamiento de datos y cada caso con su diagnóstico y su radiografía, más un manifest.json que describe el contenido. La generación
+// corre en segundo plano; el usuario consulta el estado y descarga el ZIP
+// mientras no expire.
+type DataExportService struct {
+	users               repository.UserRepository
+	exports             repository.DataExportRepository
+	consents            repository.ConsentRepository
+	audit               repository.AuditRepository
+	mail                clients.MailSender
+	prediagnosticClient clients.PrediagnosticAPI
+	ttl                 time.Duration
+	timeout             time.Duration
+	maxBytes            int
+}
+
+func NewDataExportService(users repository.UserRepository, exports repository.DataExportRepository,
+	consents repository.ConsentRepository, audit repository.AuditRepository,
+	mail clients.MailSender, prediagnosticClient clients.PrediagnosticAPI, privacyCfg config.PrivacyConfig) *DataExportService {
+	return &DataExportService{
+		users:               users,
+		exports:             exports,
+		consents:            consents,
+		audit:               audit,
+		mail:                mail,
+		prediagnosticClient: prediagnosticClient,
+		ttl:                 privacyCfg.ExportTTL,
+		timeout:             privacyCfg.ExportTimeout,
+		maxBytes:            privacyCfg.ExportMaxBytes,
+	}
+}
+
+// SolicitarExportacion inicia la generación del ZIP del usuario. Si ya tiene
+// una exportación en curso la retorna en lugar de crear otra.
+func (s *DataExportService) SolicitarExportacion(ctx context.Context, usuarioID int) (*models.DataExport, error) {
+	ultima, err := s.exports.FindLatestForUser(ctx, usuarioID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil && (ultima.Estado == models.ExportacionPendiente || ultima.Estado == models.ExportacionProcesando) &&
+		time.Since(ultima.CreadoEn) < s.timeout {
+		return ultima, nil
+	}
+
+	exportacion := &models.DataExport{
+		ID:        uuid.NewString(),
+		UsuarioID: usuarioID,
+		Estado:    models.ExportacionPendiente,
+	}
+	if err := s.exports.Create(ctx, exportacion); err != nil {
+		return nil, err
+	}
+	if err := s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: &usuarioID,
+		Evento:    models.EventoExportacionPedida,
+		Detalle:   map[string]interface{}{"exportacion_id": exportacion.ID},
+	}); err != nil {
+		return nil, err
+	}
+
+	// La generación no depende de la petición que la pidió
+	go func(e models.DataExport) {
+		genCtx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		s.generar(genCtx, &e)
+	}(*exportacion)
+
+	return exportacion, nil
+}
+
+// ObtenerExportacion retorna el estado de una exportación del usuario. Las de
+// otros usuarios se tratan como inexistentes.
+func (s *DataExportService) ObtenerExportacion(ctx context.Context, usuarioID int, id string) (*models.DataExport, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrExportacionNoEncontrada
+	}
+	exportacion, err := s.exports.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExportacionNoEncontrada
+		}
+		return nil, err
+	}
+	if exportacion.UsuarioID != usuarioID {
+		return nil, ErrExportacionNoEncontrada
+	}
+	return exportacion, nil
+}
+
+// Descargar retorna el ZIP de una exportación completada y no expirada, y
+// deja la descarga en la auditoría
+func (s *DataExportService) Descargar(ctx context.Context, usuarioID int, id string) ([]byte, error) {
+	exportacion, err := s.ObtenerExportacion(ctx, usuarioID, id)
+	if err != nil {
+		return nil, err
+	}
+	if exportacion.Estado != models.ExportacionCompletada ||
+		(exportacion.ExpiraEn != nil && time.Now().After(*exportacion.ExpiraEn)) {
+		return nil, ErrExportacionNoDisponible
+	}
+	archivo, err := s.exports.GetFile(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExportacionNoDisponible
+		}
+		return nil, err
+	}
+	if err := s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: &usuarioID,
+		Evento:    models.EventoExportacionDescargada,
+		Detalle:   map[string]interface{}{"exportacion_id": id},
+	}); err != nil {
+		return nil, err
+	}
+	return archivo, nil
+}
+
+// Run borra periódicamente los ZIP expirados y marca como fallidas las
+// exportaciones que quedaron a medias. Bloquea hasta que ctx se cancela.
+func (s *DataExportService) Run(ctx context.Context) {
+	ticker := time.NewTicker(intervaloPurgaExportaciones)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ahora := time.Now()
+			if err := s.exports.Expire(ctx, ahora, ahora.Add(-s.timeout)); err != nil {
+				log.Printf("Warning: no se pudieron purgar las exportaciones de datos: %v", err)
+			}
+		}
+	}
+}
+
+// generar arma el ZIP y guarda el resultado de la exportación
+func (s *DataExportService) generar(ctx context.Context, e *models.DataExport) {
+	e.Estado = models.ExportacionProcesando
+	if err := s.exports.Update(ctx, e); err != nil {
+		log.Printf("Error actualizando exportación %s: %v", e.ID, err)
+		return
+	}
+
+	usuario, archivo, err := s.armarZIP(ctx, e.UsuarioID)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	ahora := time.Now()
+	e.CompletadoEn = &ahora
+	if err != nil {
+		log.Printf("Error generando exportación %s del usuario %d: %v", e.ID, e.UsuarioID, err)
+		e.Estado = models.ExportacionFallida
+		e.Error = "no se pudo generar la exportación"
+		if errors.Is(err, ErrExportacionMuyGrande) {
+			e.Error = fmt.Sprintf("la exportación supera el tamaño máximo de %d MiB", s.maxBytes>>20)
+		}
+	} else {
+		expira := ahora.Add(s.ttl)
+		e.Estado = models.ExportacionCompletada
+		e.Archivo = archivo
+		e.ExpiraEn = &expira
+	}
+
+	// La generación pudo agotar ctx; el resultado se guarda igual
+	saveCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.exports.Update(saveCtx, e); err != nil {
+		log.Printf("Error guardando exportación %s: %v", e.ID, err)
+		return
+	}
+
+	if e.Estado == models.ExportacionCompletada {
+		enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+			To:      usuario.Correo,
+			Subject: "Tu copia de datos personales está lista",
+			Body: fmt.Sprintf(`Hola %s,
+
+La copia de tus datos personales que pediste ya se puede descargar desde tu
+perfil. Estará disponible durante %s.
+
+Si no pediste esta copia, cambia tu contraseña de inmediato.
+`, usuario.NombreCompleto, formatDuration(s.ttl)),
+		})
+	}
+}
+
+// archivoExportado describe una entrada del ZIP en manifest.json
+type archivoExportado struct {
+	Ruta   string `json:"ruta"`
+	Tipo   string `json:"tipo"`
+	CasoID string `json:"caso_id,omitempty"`
+	SHA256 string `json:"sha256"`
+	Bytes  int    `json:"bytes"`
+}
+
+type manifestExportacion struct {
+	GeneradoEn   time.Time          `json:"generado_en"`
+	UsuarioID    int                `json:"usuario_id"`
+	Archivos     []archivoExportado `json:"archivos"`
+	Advertencias []string           `json:"advertencias"`
+}
+
+// usuarioExportado son los datos del registro de usuario que se entregan; el
+// hash de la contraseña no es un dato del titular y no se incluye.
+// AceptaTratamientoDatos sale del registro de consentimientos.
+type usuarioExportado struct {
+	ID                     int       `json:"id"`
+	NombreCompleto         string    `json:"nombre_completo"`
+	Edad                   int       `json:"edad"`
+	Identificacion         string    `json:"identificacion"`
+	Correo                 string    `json:"correo"`
+	CorreoVerificado       bool      `json:"correo_verificado"`
+	CorreoPendiente        string    `json:"correo_pendiente,omitempty"`
+	Rol                    string    `json:"rol"`
+	Roles                  []string  `json:"roles"`
+	Estado                 string    `json:"estado"`
+	AceptaTratamientoDatos bool      `json:"acepta_tratamiento_datos"`
+	FechaCreacion          time.Time `json:"fecha_creacion"`
+}
+
+// consentimientoExportado es una aceptación de la política de tratamiento de
+// datos
+type consentimientoExportado struct {
+	Version    int        `json:"version"`
+	IP         string     `json:"ip,omitempty"`
+	AceptadoEn time.Time  `json:"aceptado_en"`
+	RetiradoEn *time.Time `json:"retirado_en,omitempty"`
+}
+
+// escritorLimitado acumula el ZIP en memoria y falla con
+// ErrExportacionMuyGrande en cuanto supera limite bytes, antes de que el
+// buffer siga creciendo
+type escritorLimitado struct {
+	buf    bytes.Buffer
+	limite int
+}
+
+func (w *escritorLimitado) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limite {
+		return 0, ErrExportacionMuyGrande
+	}
+	return w.buf.Write(p)
+}
+
+// zipExportacion escribe las entradas del ZIP y las registra en el manifest
+type zipExportacion struct {
+	w        *zip.Writer
+	manifest manifestExportacion
+}
+
+func (z *zipExportacion) agregar(ruta, tipo, casoID string, contenido []byte) error {
+	f, err := z.w.Create(ruta)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(contenido); err != nil {
+		return err
+	}
+	suma := sha256.Sum256(contenido)
+	z.manifest.Archivos = append(z.manifest.Archivos, archivoExportado{
+		Ruta:   ruta,
+		Tipo:   tipo,
+		CasoID: casoID,
+		SHA256: hex.EncodeToString(suma[:]),
+		Bytes:  len(contenido),
+	})
+	return nil
+}
+
+func (z *zipExportacion) agregarJSON(ruta, tipo, casoID string, v interface{}) error {
+	contenido, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return z.agregar(ruta, tipo, casoID, contenido)
+}
+
+// armarZIP reúne los datos del usuario. Un caso sin diagnóstico no es un
+// error; si falla la descarga del diagnóstico o de la radiografía de un caso
+// se deja una advertencia en el manifest y se sigue con el resto. Si el ZIP
+// supera maxBytes se detiene con ErrExportacionMuyGrande.
+func (s *DataExportService) armarZIP(ctx context.Context, usuarioID int) (*models.User, []byte, error) {
+	usuario, err := s.users.FindByID(ctx, usuarioID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	casos, err := s.prediagnosticClient.GetCasesByUserID(ctx, strconv.Itoa(usuarioID))
+	if err != nil {
+		if !errors.Is(err, clients.ErrSinRadiografias) {
+			return nil, nil, fmt.Errorf("error obteniendo casos: %w", err)
+		}
+		casos = nil
+	}
+
+	salida := &escritorLimitado{limite: s.maxBytes}
+	z := &zipExportacion{
+		w: zip.NewWriter(salida),
+		manifest: manifestExportacion{
+			GeneradoEn:   time.Now().UTC(),
+			UsuarioID:    usuarioID,
+			Archivos:     []archivoExportado{},
+			Advertencias: []string{},
+		},
+	}
+
+	_, aceptada, err := s.consents.CurrentVersionForUser(ctx, usuarioID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, err
+	}
+	consentimientos, err := s.consents.ListForUser(ctx, usuarioID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := z.agregarJSON("usuario.json", "usuario", "", usuarioExportado{
+		ID:                     usuario.ID,
+		NombreCompleto:         usuario.NombreCompleto,
+		Edad:                   usuario.Edad,
+		Identificacion:         usuario.Identificacion,
+		Correo:                 usuario.Correo,
+		CorreoVerificado:       usuario.CorreoVerificado,
+		CorreoPendiente:        usuario.CorreoPendiente,
+		Rol:                    usuario.Rol,
+		Roles:                  usuario.RolesAsignados(),
+		Estado:                 usuario.Estado,
+		AceptaTratamientoDatos: aceptada,
+		FechaCreacion:          usuario.FechaCreacion,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	exportados := make([]consentimientoExportado, 0, len(consentimientos))
+	for _, c := range consentimientos {
+		exportados = append(exportados, consentimientoExportado{
+			Version:    c.Version,
+			IP:         c.IP,
+			AceptadoEn: c.AceptadoEn,
+			RetiradoEn: c.RetiradoEn,
+		})
+	}
+	if err := z.agregarJSON("consentimientos.json", "consentimientos", "", exportados); err != nil {
+		return nil, nil, err
+	}
+
+	for i, caso := range casos {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		casoID := caso.CaseID()
+		carpeta := "casos/" + nombreArchivoInvalido.ReplaceAllString(casoID, "_")
+		if casoID == "" {
+			carpeta = fmt.Sprintf("casos/sin_id_%d", i+1)
+		}
+
+		if err := z.agregarJSON(carpeta+"/caso.json", "caso", casoID, caso); err != nil {
+			return nil, nil, err
+		}
+
+		if casoID != "" {
+			diagnostico, err := s.prediagnosticClient.GetDiagnostic(ctx, casoID)
+			switch {
+			case err == nil:
+				if err := z.agregarJSON(carpeta+"/diagnostico.json", "diagnostico", casoID, diagnostico); err != nil {
+					return nil, nil, err
+				}
+			case errors.Is(err, clients.ErrDiagnosticoNoEncontrado):
+			default:
+				z.manifest.Advertencias = append(z.manifest.Advertencias,
+					fmt.Sprintf("caso %s: no se pudo obtener el diagnóstico: %v", casoID, err))
+			}
+		}
+
+		if nombre := nombreRadiografia(caso); nombre != "" {
+			imagen, err := s.prediagnosticClient.GetImage(ctx, nombre)
+			if err != nil {
+				z.manifest.Advertencias = append(z.manifest.Advertencias,
+					fmt.Sprintf("caso %s: no se pudo obtener la radiografía: %v", casoID, err))
+				continue
+			}
+			ruta := carpeta + "/radiografia" + strings.ToLower(path.Ext(nombre))
+			if err := z.agregar(ruta, "radiografia", casoID, imagen); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// El manifest va al final porque describe las demás entradas
+	manifest, err := json.MarshalIndent(z.manifest, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := z.w.Create("manifest.json")
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := f.Write(manifest); err != nil {
+		return nil, nil, err
+	}
+	if err := z.w.Close(); err != nil {
+		return nil, nil, err
+	}
+	return usuario, salida.buf.Bytes(), nil
+}
+
+// nombreRadiografia extrae el nombre del archivo de radiografia_ruta, que el
+// servicio prediagnostic guarda con separadores de Windows o de Unix
+func nombreRadiografia(caso clients.CaseSummaryDTO) string {
+	if caso.RadiografiaRuta == nil {
+		return ""
+	}
+	ruta := strings.ReplaceAll(*caso.RadiografiaRuta, "\\", "/")
+	nombre := path.Base(ruta)
+	if nombre == "." || nombre == "/" || nombreArchivoInvalido.MatchString(nombre) {
+		return ""
+	}
+	return nombre
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/unobeswarch/businesslogic/internal/clients"
@@ -8,17 +11,17 @@ import (
 )
 
 type DiagnosticService struct {
-	client *clients.PreDiagnosticClient
+	client clients.PrediagnosticAPI
 }
 
-func NewDiagnosticService(baseURL string) *DiagnosticService {
+func NewDiagnosticService(client clients.PrediagnosticAPI) *DiagnosticService {
 	return &DiagnosticService{
-		client: clients.NewPrediagnosticClient(baseURL),
+		client: client,
 	}
 }
 
 // CreateDiagnostic procesa la creación de un diagnóstico
-func (s *DiagnosticService) CreateDiagnostic(prediagnosticID, aprobacion, comentario string) (*models.DiagnosticResponse, error) {
+func (s *DiagnosticService) CreateDiagnostic(ctx context.Context, prediagnosticID, aprobacion, comentario string) (*models.DiagnosticResponse, error) {
 	// Validar entrada
 	if aprobacion != "Si" && aprobacion != "No" {
 		return &models.DiagnosticResponse{
@@ -35,7 +38,8 @@ func (s *DiagnosticService) CreateDiagnostic(prediagnosticID, aprobacion, coment
 	}
 
 	// Enviar solicitud al servicio de prediagnóstico
-	result, err := s.client.CreateDiagnostic(prediagnosticID, aprobacion, comentario)
+	result, err := s.client.CreateDiagnostic(ctx, claveIdempotenciaDiagnostico(ctx, prediagnosticID, aprobacion, comentario),
+		prediagnosticID, aprobacion, comentario)
 	if err != nil {
 		return &models.DiagnosticResponse{
 			Success: false,
@@ -44,15 +48,12 @@ func (s *DiagnosticService) CreateDiagnostic(prediagnosticID, aprobacion, coment
 	}
 
 	// Procesar respuesta
-	success, ok := result["success"].(bool)
-	if !ok {
-		// Si no hay campo "success", inferir el éxito basado en el mensaje
-		success = false
-	}
+	// Si no hay campo "success", inferir el éxito basado en el mensaje
+	success := result.Success != nil && *result.Success
 
-	message, ok := result["message"].(string)
-	if !ok {
-		message = "Diagnóstico procesado"
+	message := "Diagnóstico procesado"
+	if result.Message != nil {
+		message = *result.Message
 	}
 
 	// Si el mensaje indica éxito pero success es false, corregir
@@ -62,7 +63,10 @@ func (s *DiagnosticService) CreateDiagnostic(prediagnosticID, aprobacion, coment
 		success = true
 	}
 
-	diagnosticID, _ := result["diagnostic_id"].(string)
+	var diagnosticID string
+	if result.DiagnosticID != nil {
+		diagnosticID = *result.DiagnosticID
+	}
 
 	return &models.DiagnosticResponse{
 		Success:      success,
@@ -70,3 +74,21 @@ func (s *DiagnosticService) CreateDiagnostic(prediagnosticID, aprobacion, coment
 		DiagnosticID: diagnosticID,
 	}, nil
 }
+
+// claveIdempotenciaDiagnostico identifica un diagnóstico por doctor, caso y
+// contenido. Si el doctor reenvía el mismo diagnóstico (por ejemplo porque
+// la respuesta anterior no llegó) el servicio prediagnostic lo reconoce y no
+// crea un duplicado.
+func claveIdempotenciaDiagnostico(ctx context.Context, prediagnosticID, aprobacion, comentario string) string {
+	doctorID := ""
+	if claims, err := ClaimsFromContext(ctx); err == nil {
+		doctorID = claims.UserID
+	}
+	h := sha256.New()
+	for _, parte := range []string{"diagnostico", doctorID, prediagnosticID, aprobacion, comentario} {
+		// El largo delante de cada parte evita que dos combinaciones distintas
+		// formen el mismo texto
+		fmt.Fprintf(h, "%d:%s", len(parte), parte)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var ErrLicenciaExistente = errors.New("LICENSE_ALREADY_REGISTERED")
+
+// DoctorService recibe las solicitudes de cuenta de doctor. La cuenta queda
+// pendiente de aprobación hasta que un administrador la revisa con
+// AdminService.AprobarDoctor o AdminService.RechazarDoctor.
+type DoctorService struct {
+	users   repository.UserRepository
+	doctors repository.DoctorProfileRepository
+}
+
+func NewDoctorService(users repository.UserRepository, doctors repository.DoctorProfileRepository) *DoctorService {
+	return &DoctorService{
+		users:   users,
+		doctors: doctors,
+	}
+}
+
+// RegistrarSolicitud crea la cuenta de un doctor, pendiente de aprobación,
+// junto con su número de licencia médica y especialidad.
+func (s *DoctorService) RegistrarSolicitud(ctx context.Context, u models.User, perfil models.DoctorProfile) (int, time.Time, error) {
+	perfil.NumeroLicencia = strings.TrimSpace(perfil.NumeroLicencia)
+	perfil.Especialidad = strings.TrimSpace(perfil.Especialidad)
+	if perfil.NumeroLicencia == "" || len(perfil.NumeroLicencia) > 50 ||
+		perfil.Especialidad == "" || len(perfil.Especialidad) > 100 {
+		return 0, time.Time{}, ErrDatosEnviados
+	}
+
+	u.Rol = models.RolDoctor
+	u.Estado = models.EstadoPendienteAprobacion
+	if err := prepararRegistro(ctx, s.users, &u); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	existe, err := s.doctors.LicenseExists(ctx, perfil.NumeroLicencia)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if existe {
+		return 0, time.Time{}, ErrLicenciaExistente
+	}
+
+	if err := s.doctors.CreateWithUser(ctx, &u, &perfil); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return 0, time.Time{}, ErrLicenciaExistente
+		}
+		return 0, time.Time{}, err
+	}
+
+	log.Printf("Nueva solicitud de doctor %d (licencia %s) pendiente de aprobación", u.ID, perfil.NumeroLicencia)
+	return u.ID, u.FechaCreacion, nil
+}
+
+// ObtenerPerfil retorna el perfil de doctor del usuario, o nil si no tiene
+func (s *DoctorService) ObtenerPerfil(ctx context.Context, usuarioID int) (*models.DoctorProfile, error) {
+	perfil, err := s.doctors.Get(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return perfil, nil
+}
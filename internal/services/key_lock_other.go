@@ -0,0 +1,9 @@
+//go:build !unix
+
+package services
+
+// lockKeysDir no toma ningún candado fuera de Unix; rotateIfNeeded igual
+// relee el directorio antes de generar una clave.
+func lockKeysDir(dir string) (func(), error) {
+	return func() {}, nil
+}
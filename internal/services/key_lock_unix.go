@@ -0,0 +1,30 @@
+//go:build unix
+
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockKeysDir toma un candado exclusivo sobre el directorio de claves para
+// que una sola réplica a la vez decida si falta una clave y la genere.
+// Retorna la función que lo libera.
+func lockKeysDir(dir string) (func(), error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, ".lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+)
+
+// kidTimeLayout es el prefijo de cada kid: indica desde cuándo la clave se usa
+// para firmar. Ej: 20261017T000000Z.pem o 20261017T000000Z-a1b2.pem
+const kidTimeLayout = "20060102T150405Z"
+
+// JWKSCacheMaxAge es cuánto pueden cachear los verificadores externos el JWKS.
+// Las claves nuevas se publican al menos este tiempo antes de empezar a firmar.
+const JWKSCacheMaxAge = 5 * time.Minute
+
+var errClaveDesconocida = errors.New("clave de firma desconocida o retirada")
+
+// SigningKey es una clave privada de firma cargada desde un archivo PEM
+type SigningKey struct {
+	ID         string
+	Algorithm  string // RS256 o ES256, según el tipo de clave
+	ActiveFrom time.Time
+	signer     crypto.Signer
+}
+
+// Public retorna la clave pública usada para verificar y publicar en el JWKS
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.signer.Public()
+}
+
+// KeyManager administra las claves asimétricas con las que se firman los JWT.
+//
+// Cada archivo <kid>.pem del directorio de claves es una clave privada cuyo kid
+// empieza con la fecha de activación. Se firma con la clave activa más reciente;
+// una clave publicada con fecha futura aparece en el JWKS antes de usarse, y una
+// clave reemplazada sigue verificando hasta que expiren los tokens que firmó.
+type KeyManager struct {
+	dir              string
+	algorithm        string
+	tokenTTL         time.Duration
+	rotationInterval time.Duration
+	reloadInterval   time.Duration
+
+	mu   sync.RWMutex
+	keys []*SigningKey // ordenadas por ActiveFrom
+}
+
+func NewKeyManager(cfg config.JWTConfig) (*KeyManager, error) {
+	m := &KeyManager{
+		dir:              cfg.KeysDir,
+		algorithm:        cfg.Algorithm,
+		tokenTTL:         cfg.TokenTTL,
+		rotationInterval: cfg.KeyRotationInterval,
+		reloadInterval:   cfg.KeyReloadInterval,
+	}
+
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	if err := m.rotateIfNeeded(); err != nil {
+		return nil, err
+	}
+	if _, err := m.SigningKey(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Run recarga periódicamente el directorio de claves (para ver las claves
+// creadas por otras réplicas u operadores) y rota la clave si corresponde.
+func (m *KeyManager) Run(ctx context.Context) {
+	if m.reloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.reload(); err != nil {
+				log.Printf("Warning: no se pudieron recargar las claves JWT: %v", err)
+				continue
+			}
+			if err := m.rotateIfNeeded(); err != nil {
+				log.Printf("Warning: no se pudo rotar la clave JWT: %v", err)
+			}
+		}
+	}
+}
+
+// SigningKey retorna la clave con la que se deben firmar los tokens nuevos
+func (m *KeyManager) SigningKey() (*SigningKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if key := activeKey(m.keys, time.Now()); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no hay claves JWT activas en %s", m.dir)
+}
+
+// VerificationKey retorna la clave publicada con el kid indicado
+func (m *KeyManager) VerificationKey(kid string) (*SigningKey, error) {
+	for _, key := range m.PublishedKeys() {
+		if key.ID == kid {
+			return key, nil
+		}
+	}
+	return nil, errClaveDesconocida
+}
+
+// PublishedKeys retorna las claves que deben aparecer en el JWKS: la activa,
+// las programadas a futuro y las anteriores que aún no se retiran.
+func (m *KeyManager) PublishedKeys() []*SigningKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	var published []*SigningKey
+	for i, key := range m.keys {
+		if m.retired(m.keys, i, now) {
+			continue
+		}
+		published = append(published, key)
+	}
+	return published
+}
+
+// retired indica si keys[i] ya se retiró: su sucesora lleva activa más que la
+// vida de un token, así que no queda ningún token vigente firmado con ella
+func (m *KeyManager) retired(keys []*SigningKey, i int, now time.Time) bool {
+	if i+1 >= len(keys) {
+		return false
+	}
+	successor := keys[i+1]
+	return !successor.ActiveFrom.After(now) && now.After(successor.ActiveFrom.Add(m.tokenTTL))
+}
+
+func (m *KeyManager) reload() error {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			entries = nil
+		} else {
+			return fmt.Errorf("error leyendo directorio de claves %s: %w", m.dir, err)
+		}
+	}
+
+	var keys []*SigningKey
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+		key, err := loadSigningKey(filepath.Join(m.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ActiveFrom.Before(keys[j].ActiveFrom) })
+
+	m.mu.Lock()
+	m.keys = keys
+	m.mu.Unlock()
+	return nil
+}
+
+// rotateIfNeeded genera una clave nueva cuando la activa supera el intervalo
+// de rotación. La nueva se programa a futuro (dos recargas más la caché del
+// JWKS) para que todas las réplicas y verificadores externos la conozcan
+// antes de que firme tokens. Como el directorio es compartido, la decisión se
+// repite con el candado del directorio tomado y después de releerlo: si otra
+// réplica ya generó la clave, no se genera otra. También borra los archivos
+// de las claves retiradas.
+func (m *KeyManager) rotateIfNeeded() error {
+	if m.rotationInterval <= 0 {
+		return nil
+	}
+	if _, ok := m.nextActivation(time.Now()); !ok {
+		return m.pruneRetired()
+	}
+
+	unlock, err := lockKeysDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("error bloqueando directorio de claves %s: %w", m.dir, err)
+	}
+	defer unlock()
+
+	if err := m.reload(); err != nil {
+		return err
+	}
+	activation, ok := m.nextActivation(time.Now())
+	if !ok {
+		return m.pruneRetired()
+	}
+
+	key, err := m.generateKey(activation)
+	if err != nil {
+		return err
+	}
+	log.Printf("Nueva clave JWT %s (%s) activa desde %s", key.ID, key.Algorithm, key.ActiveFrom.Format(time.RFC3339))
+
+	if err := m.reload(); err != nil {
+		return err
+	}
+	return m.pruneRetired()
+}
+
+// nextActivation indica si hace falta generar una clave y desde cuándo debe
+// firmar
+func (m *KeyManager) nextActivation(now time.Time) (time.Time, bool) {
+	m.mu.RLock()
+	active := activeKey(m.keys, now)
+	var latest *SigningKey
+	if len(m.keys) > 0 {
+		latest = m.keys[len(m.keys)-1]
+	}
+	m.mu.RUnlock()
+
+	switch {
+	case active == nil:
+		// Primer arranque (o todas las claves son futuras): se necesita firmar ya
+		return now, latest == nil
+	case latest != active:
+		// Ya hay una clave programada
+		return time.Time{}, false
+	case now.Sub(active.ActiveFrom) < m.rotationInterval:
+		return time.Time{}, false
+	default:
+		return now.Add(2*m.reloadInterval + JWKSCacheMaxAge), true
+	}
+}
+
+// pruneRetired borra los archivos de las claves retiradas. Solo se llama con
+// la rotación automática activa; las claves administradas a mano se dejan.
+func (m *KeyManager) pruneRetired() error {
+	now := time.Now()
+	m.mu.RLock()
+	var retired []*SigningKey
+	for i, key := range m.keys {
+		if m.retired(m.keys, i, now) {
+			retired = append(retired, key)
+		}
+	}
+	m.mu.RUnlock()
+
+	if len(retired) == 0 {
+		return nil
+	}
+	for _, key := range retired {
+		// Otra réplica pudo borrarla primero
+		err := os.Remove(filepath.Join(m.dir, key.ID+".pem"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error borrando clave retirada %s: %w", key.ID, err)
+		}
+		log.Printf("Clave JWT retirada %s borrada", key.ID)
+	}
+	return m.reload()
+}
+
+func (m *KeyManager) generateKey(activation time.Time) (*SigningKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch m.algorithm {
+	case "ES256":
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 2)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	kid := activation.UTC().Format(kidTimeLayout) + "-" + hex.EncodeToString(suffix)
+
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return nil, err
+	}
+	// Se escribe en un temporal y se renombra para que otras réplicas nunca lean un archivo a medias
+	tmp, err := os.CreateTemp(m.dir, ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if err := pem.Encode(tmp, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(m.dir, kid+".pem")); err != nil {
+		return nil, err
+	}
+
+	return newSigningKey(kid, signer)
+}
+
+func loadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s no contiene un bloque PEM", path)
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parseando clave %s: %w", path, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("tipo de clave no soportado en %s", path)
+	}
+
+	kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+	key, err := newSigningKey(kid, signer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+func newSigningKey(kid string, signer crypto.Signer) (*SigningKey, error) {
+	if len(kid) < len(kidTimeLayout) {
+		return nil, fmt.Errorf("kid %q debe empezar con la fecha de activación (%s)", kid, kidTimeLayout)
+	}
+	activeFrom, err := time.Parse(kidTimeLayout, kid[:len(kidTimeLayout)])
+	if err != nil {
+		return nil, fmt.Errorf("kid %q debe empezar con la fecha de activación (%s): %w", kid, kidTimeLayout, err)
+	}
+
+	var algorithm string
+	switch k := signer.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("la clave RSA %s debe tener al menos 2048 bits", kid)
+		}
+		algorithm = "RS256"
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("la clave EC %s debe usar la curva P-256", kid)
+		}
+		algorithm = "ES256"
+	default:
+		return nil, fmt.Errorf("tipo de clave no soportado para %s (solo RSA y EC P-256)", kid)
+	}
+
+	return &SigningKey{ID: kid, Algorithm: algorithm, ActiveFrom: activeFrom, signer: signer}, nil
+}
+
+// activeKey retorna la clave más reciente ya activa; keys debe estar ordenada
+func activeKey(keys []*SigningKey, now time.Time) *SigningKey {
+	for i := len(keys) - 1; i >= 0; i-- {
+		if !keys[i].ActiveFrom.After(now) {
+			return keys[i]
+		}
+	}
+	return nil
+}
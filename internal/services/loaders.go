@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/dataloader"
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+const (
+	// esperaLote es cuánto espera un loader a que los resolvers de la misma
+	// petición pidan más claves antes de cargar el lote
+	esperaLote = 2 * time.Millisecond
+	// maxLote limita cuántas claves se cargan en cada lote
+	maxLote = 100
+	// maxPeticionesParalelas limita las llamadas simultáneas al servicio
+	// prediagnostic, que no tiene endpoints para consultar varios IDs a la vez
+	maxPeticionesParalelas = 8
+)
+
+// Loaders agrupa los dataloaders de una petición GraphQL. Se crean en cada
+// petición (handlers.DataLoaderMiddleware) porque guardan lo que cargan.
+type Loaders struct {
+	// UserByID carga usuarios de la tabla usuarios; un ID inexistente da nil
+	UserByID *dataloader.Loader[int, *models.User]
+	// DiagnosticByCaseID carga el diagnóstico médico de un caso; nil si aún
+	// no tiene
+	DiagnosticByCaseID *dataloader.Loader[string, *model.Diagnostic]
+	// PreDiagnosticByID carga prediagnósticos del servicio prediagnostic
+	PreDiagnosticByID *dataloader.Loader[string, *model.PreDiagnostic]
+}
+
+func NewLoaders(users repository.UserRepository, caseService *CaseService, prediagnosticService *PreDiagnosticService) *Loaders {
+	return &Loaders{
+		UserByID: dataloader.New(func(ctx context.Context, ids []int) ([]*models.User, []error) {
+			encontrados, err := users.FindByIDs(ctx, ids)
+			if err != nil {
+				return nil, errorParaTodas(len(ids), err)
+			}
+			usuarios := make([]*models.User, len(ids))
+			for i, id := range ids {
+				usuarios[i] = encontrados[id]
+			}
+			return usuarios, nil
+		}, esperaLote, maxLote),
+		DiagnosticByCaseID: dataloader.New(caseService.GetDiagnosticsByCaseIDs, esperaLote, maxLote),
+		PreDiagnosticByID:  dataloader.New(prediagnosticService.GetPreDiagnosticsByIDs, esperaLote, maxLote),
+	}
+}
+
+// loadersContextKey es la clave tipada con la que se guardan los loaders en el contexto
+type loadersContextKey struct{}
+
+// ContextWithLoaders guarda en el contexto los loaders de la petición
+func ContextWithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersContextKey{}, loaders)
+}
+
+// LoadersFromContext retorna los loaders que dejó DataLoaderMiddleware
+func LoadersFromContext(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersContextKey{}).(*Loaders)
+	return loaders
+}
+
+// LoadUser carga con el loader de la petición el usuario cuyo ID viene como
+// texto (como en los datos del servicio prediagnostic). Retorna nil si el ID
+// no es válido o el usuario no existe.
+func (l *Loaders) LoadUser(ctx context.Context, id string) (*models.User, error) {
+	usuarioID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, nil
+	}
+	return l.UserByID.Load(ctx, usuarioID)
+}
+
+// cargarEnParalelo llama a cargar para cada clave con a lo sumo
+// maxPeticionesParalelas llamadas simultáneas. Un panic al procesar una
+// respuesta inesperada se convierte en el error de esa clave: fuera de la
+// goroutine del resolver, gqlgen no lo recupera.
+func cargarEnParalelo[K comparable, V any](keys []K, cargar func(K) (V, error)) ([]V, []error) {
+	valores := make([]V, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	turnos := make(chan struct{}, maxPeticionesParalelas)
+	for i, key := range keys {
+		wg.Add(1)
+		turnos <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-turnos }()
+			defer func() {
+				if p := recover(); p != nil {
+					errs[i] = fmt.Errorf("respuesta inválida para %v: %v", key, p)
+				}
+			}()
+			valores[i], errs[i] = cargar(key)
+		}()
+	}
+	wg.Wait()
+	return valores, errs
+}
+
+func errorParaTodas(n int, err error) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var (
+	ErrCuentaBloqueada         = errors.New("ACCOUNT_LOCKED")
+	ErrDemasiadosIntentos      = errors.New("TOO_MANY_ATTEMPTS")
+	ErrTokenDesbloqueoInvalido = errors.New("INVALID_UNLOCK_TOKEN")
+)
+
+// BloqueoError indica que el login está bloqueado hasta Hasta. Err es
+// ErrCuentaBloqueada (se alcanzó el máximo de fallos de la cuenta) o
+// ErrDemasiadosIntentos (espera entre fallos, o bloqueo de la IP).
+type BloqueoError struct {
+	Err   error
+	Hasta time.Time
+}
+
+func (e *BloqueoError) Error() string { return e.Err.Error() }
+func (e *BloqueoError) Unwrap() error { return e.Err }
+
+// LockoutService limita los intentos de login por cuenta y por IP con espera
+// exponencial entre fallos y bloqueo temporal, y audita los bloqueos.
+type LockoutService struct {
+	attempts    repository.LoginAttemptRepository
+	audit       repository.AuditRepository
+	users       repository.UserRepository
+	tokens      repository.UserTokenRepository
+	mail        clients.MailSender
+	cfg         config.LockoutConfig
+	frontendURL string
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+func NewLockoutService(attempts repository.LoginAttemptRepository, audit repository.AuditRepository, users repository.UserRepository, tokens repository.UserTokenRepository, mail clients.MailSender, cfg config.LockoutConfig, frontendURL string) *LockoutService {
+	return &LockoutService{
+		attempts:    attempts,
+		audit:       audit,
+		users:       users,
+		tokens:      tokens,
+		mail:        mail,
+		cfg:         cfg,
+		frontendURL: frontendURL,
+	}
+}
+
+// Comprobar retorna un *BloqueoError si la cuenta o la IP deben esperar antes
+// de volver a intentar. Se llama antes de revisar la contraseña, para que un
+// atacante no pueda seguir probando durante el bloqueo.
+func (s *LockoutService) Comprobar(ctx context.Context, correo, ip string) error {
+	now := time.Now()
+
+	var bloqueo *BloqueoError
+	for _, clave := range clavesLogin(correo, ip) {
+		a, err := s.attempts.Get(ctx, clave)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if a.BloqueadoHasta == nil || !a.BloqueadoHasta.After(now) {
+			continue
+		}
+
+		codigo := ErrDemasiadosIntentos
+		if strings.HasPrefix(clave, "cuenta:") && a.Fallos >= s.cfg.MaxFailedAttempts {
+			codigo = ErrCuentaBloqueada
+		}
+		if bloqueo == nil {
+			bloqueo = &BloqueoError{Err: codigo, Hasta: *a.BloqueadoHasta}
+			continue
+		}
+		// Se informa el bloqueo más largo; el de la cuenta tiene prioridad en el código
+		if a.BloqueadoHasta.After(bloqueo.Hasta) {
+			bloqueo.Hasta = *a.BloqueadoHasta
+		}
+		if codigo == ErrCuentaBloqueada {
+			bloqueo.Err = codigo
+		}
+	}
+
+	if bloqueo != nil {
+		return bloqueo
+	}
+	return nil
+}
+
+// RegistrarFallo suma un intento fallido a la cuenta y a la IP y aplica la
+// espera o el bloqueo que corresponda.
+func (s *LockoutService) RegistrarFallo(ctx context.Context, correo, ip string) error {
+	now := time.Now()
+	reiniciarAntes := now.Add(-s.cfg.FailureWindow)
+
+	fallos, err := s.attempts.RecordFailure(ctx, claveCuenta(correo), now, reiniciarAntes)
+	if err != nil {
+		return err
+	}
+	if espera := s.espera(fallos, s.cfg.MaxFailedAttempts); espera > 0 {
+		hasta := now.Add(espera)
+		if err := s.attempts.Block(ctx, claveCuenta(correo), hasta); err != nil {
+			return err
+		}
+		if fallos >= s.cfg.MaxFailedAttempts {
+			if err := s.bloquearCuenta(ctx, correo, ip, fallos, hasta); err != nil {
+				return err
+			}
+		}
+	}
+
+	if ip != "" {
+		fallos, err := s.attempts.RecordFailure(ctx, claveIP(ip), now, reiniciarAntes)
+		if err != nil {
+			return err
+		}
+		if espera := s.espera(fallos, s.cfg.MaxFailedAttemptsPerIP); espera > 0 {
+			hasta := now.Add(espera)
+			if err := s.attempts.Block(ctx, claveIP(ip), hasta); err != nil {
+				return err
+			}
+			if fallos >= s.cfg.MaxFailedAttemptsPerIP {
+				log.Printf("IP %s bloqueada hasta %s tras %d intentos fallidos", ip, hasta.Format(time.RFC3339), fallos)
+				err := s.audit.Record(ctx, &models.AuditEvent{
+					Evento: models.EventoIPBloqueada,
+					IP:     ip,
+					Detalle: map[string]interface{}{
+						"fallos":          fallos,
+						"bloqueado_hasta": hasta,
+					},
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	s.purgar(now)
+	return nil
+}
+
+// RegistrarExito reinicia el contador de la cuenta tras un login correcto.
+// El de la IP se mantiene: una cuenta válida no debe servir para seguir
+// probando contraseñas de otras desde la misma dirección.
+func (s *LockoutService) RegistrarExito(ctx context.Context, correo string) error {
+	return s.attempts.Reset(ctx, claveCuenta(correo))
+}
+
+// ComprobarSegundoFactor retorna un *BloqueoError si el usuario debe esperar
+// antes de volver a probar un código de segundo factor para desactivarlo o
+// regenerar los códigos de recuperación
+func (s *LockoutService) ComprobarSegundoFactor(ctx context.Context, usuarioID int) error {
+	a, err := s.attempts.Get(ctx, claveSegundoFactor(usuarioID))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if a.BloqueadoHasta != nil && a.BloqueadoHasta.After(time.Now()) {
+		return &BloqueoError{Err: ErrDemasiadosIntentos, Hasta: *a.BloqueadoHasta}
+	}
+	return nil
+}
+
+// RegistrarFalloSegundoFactor suma un código incorrecto del usuario con la
+// misma espera y bloqueo que los fallos de contraseña de una cuenta. Con un
+// access token robado no se pueden probar todos los códigos TOTP.
+func (s *LockoutService) RegistrarFalloSegundoFactor(ctx context.Context, usuarioID int) error {
+	now := time.Now()
+	clave := claveSegundoFactor(usuarioID)
+	fallos, err := s.attempts.RecordFailure(ctx, clave, now, now.Add(-s.cfg.FailureWindow))
+	if err != nil {
+		return err
+	}
+	if espera := s.espera(fallos, s.cfg.MaxFailedAttempts); espera > 0 {
+		if err := s.attempts.Block(ctx, clave, now.Add(espera)); err != nil {
+			return err
+		}
+	}
+	s.purgar(now)
+	return nil
+}
+
+// RegistrarExitoSegundoFactor reinicia el contador de códigos incorrectos del usuario
+func (s *LockoutService) RegistrarExitoSegundoFactor(ctx context.Context, usuarioID int) error {
+	return s.attempts.Reset(ctx, claveSegundoFactor(usuarioID))
+}
+
+// DesbloquearConToken desbloquea la cuenta con el enlace enviado por correo al bloquearla
+func (s *LockoutService) DesbloquearConToken(ctx context.Context, token string) error {
+	stored, err := s.tokens.FindByHash(ctx, models.PropositoDesbloquearCuenta, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTokenDesbloqueoInvalido
+		}
+		return err
+	}
+	if stored.UsadoEn != nil || time.Now().After(stored.ExpiraEn) {
+		return ErrTokenDesbloqueoInvalido
+	}
+	if err := s.tokens.MarkUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, repository.ErrAlreadyUsed) {
+			return ErrTokenDesbloqueoInvalido
+		}
+		return err
+	}
+
+	usuario, err := s.users.FindByID(ctx, stored.UsuarioID)
+	if err != nil {
+		return err
+	}
+	return s.desbloquear(ctx, usuario.Correo, &usuario.ID, "correo")
+}
+
+// DesbloquearCuenta desbloquea la cuenta a pedido de un administrador (actor)
+func (s *LockoutService) DesbloquearCuenta(ctx context.Context, correo, actor string) error {
+	var usuarioID *int
+	usuario, err := s.users.FindByEmail(ctx, correo)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	} else {
+		usuarioID = &usuario.ID
+		if err := s.tokens.InvalidateForUser(ctx, usuario.ID, models.PropositoDesbloquearCuenta); err != nil {
+			return err
+		}
+	}
+	return s.desbloquear(ctx, correo, usuarioID, actor)
+}
+
+func (s *LockoutService) desbloquear(ctx context.Context, correo string, usuarioID *int, por string) error {
+	if err := s.attempts.Reset(ctx, claveCuenta(correo)); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, &models.AuditEvent{
+		UsuarioID: usuarioID,
+		Evento:    models.EventoCuentaDesbloqueada,
+		Detalle: map[string]interface{}{
+			"correo": correo,
+			"por":    por,
+		},
+	})
+}
+
+// bloquearCuenta audita el bloqueo y, si la cuenta existe, envía al dueño un
+// enlace para desbloquearla antes de que termine el bloqueo.
+func (s *LockoutService) bloquearCuenta(ctx context.Context, correo, ip string, fallos int, hasta time.Time) error {
+	evento := &models.AuditEvent{
+		Evento: models.EventoCuentaBloqueada,
+		IP:     ip,
+		Detalle: map[string]interface{}{
+			"correo":          correo,
+			"fallos":          fallos,
+			"bloqueado_hasta": hasta,
+		},
+	}
+
+	usuario, err := s.users.FindByEmail(ctx, correo)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if usuario != nil {
+		evento.UsuarioID = &usuario.ID
+	}
+	log.Printf("Cuenta %s bloqueada hasta %s tras %d intentos fallidos", correo, hasta.Format(time.RFC3339), fallos)
+	if err := s.audit.Record(ctx, evento); err != nil {
+		return err
+	}
+	if usuario == nil {
+		return nil
+	}
+
+	if err := s.tokens.InvalidateForUser(ctx, usuario.ID, models.PropositoDesbloquearCuenta); err != nil {
+		return err
+	}
+	token, err := generarTokenOpaco()
+	if err != nil {
+		return err
+	}
+	err = s.tokens.Create(ctx, &models.UserToken{
+		UsuarioID: usuario.ID,
+		Proposito: models.PropositoDesbloquearCuenta,
+		TokenHash: hashToken(token),
+		ExpiraEn:  hasta,
+	})
+	if err != nil {
+		return err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.Correo,
+		Subject: "Tu cuenta fue bloqueada temporalmente",
+		Body: fmt.Sprintf(`Hola %s,
+
+Detectamos %d intentos fallidos de inicio de sesión en tu cuenta, por lo que
+la bloqueamos temporalmente durante %s.
+
+Si fuiste tú, puedes desbloquearla ahora con el siguiente enlace:
+
+%s/unlock-account?token=%s
+
+Si no fuiste tú, te recomendamos restablecer tu contraseña.
+`, usuario.NombreCompleto, fallos, formatDuration(s.cfg.LockoutDuration), s.frontendURL, url.QueryEscape(token)),
+	})
+	return nil
+}
+
+// espera calcula cuánto debe esperar una clave tras su fallo número fallos:
+// nada en el primero, BackoffBase en el segundo duplicándose hasta
+// BackoffMax, y LockoutDuration al alcanzar max.
+func (s *LockoutService) espera(fallos, max int) time.Duration {
+	if fallos >= max {
+		return s.cfg.LockoutDuration
+	}
+	if fallos < 2 || s.cfg.BackoffBase <= 0 {
+		return 0
+	}
+
+	d := s.cfg.BackoffBase
+	for i := 2; i < fallos && d < s.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, s.cfg.BackoffMax)
+}
+
+// purgar elimina, como mucho una vez por hora, los contadores que ya no aplican
+func (s *LockoutService) purgar(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPurge) < time.Hour {
+		return
+	}
+	s.lastPurge = now
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.attempts.PurgeExpired(ctx, now.Add(-s.cfg.FailureWindow)); err != nil {
+			log.Printf("Warning: no se pudieron purgar intentos de login expirados: %v", err)
+		}
+	}()
+}
+
+func claveCuenta(correo string) string {
+	return "cuenta:" + strings.ToLower(strings.TrimSpace(correo))
+}
+
+func claveIP(ip string) string {
+	return "ip:" + ip
+}
+
+func claveSegundoFactor(usuarioID int) string {
+	return fmt.Sprintf("2fa:%d", usuarioID)
+}
+
+func clavesLogin(correo, ip string) []string {
+	if ip == "" {
+		return []string{claveCuenta(correo)}
+	}
+	return []string{claveCuenta(correo), claveIP(ip)}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var lockoutPrueba = config.LockoutConfig{
+	MaxFailedAttempts:      5,
+	MaxFailedAttemptsPerIP: 8,
+	BackoffBase:            time.Second,
+	BackoffMax:             6 * time.Second,
+	LockoutDuration:        15 * time.Minute,
+	FailureWindow:          time.Hour,
+}
+
+func nuevoLockoutService(cfg config.LockoutConfig) *LockoutService {
+	users := repository.NewMemoryUserRepository()
+	return NewLockoutService(repository.NewMemoryLoginAttemptRepository(), repository.NewMemoryAuditRepository(),
+		users, repository.NewMemoryUserTokenRepository(), nil, cfg, "http://localhost:3000")
+}
+
+func TestEsperaEntreFallos(t *testing.T) {
+	s := nuevoLockoutService(lockoutPrueba)
+
+	casos := []struct {
+		fallos int
+		max    int
+		espera time.Duration
+	}{
+		{1, 10, 0},
+		{2, 10, time.Second},
+		{3, 10, 2 * time.Second},
+		{4, 10, 4 * time.Second},
+		{5, 10, 6 * time.Second}, // 8s limitado por BackoffMax
+		{9, 10, 6 * time.Second},
+		{10, 10, 15 * time.Minute},
+		{5, 5, 15 * time.Minute},
+		{12, 5, 15 * time.Minute},
+	}
+	for _, c := range casos {
+		if espera := s.espera(c.fallos, c.max); espera != c.espera {
+			t.Errorf("espera(%d, %d) = %v, se esperaba %v", c.fallos, c.max, espera, c.espera)
+		}
+	}
+
+	sinBackoff := lockoutPrueba
+	sinBackoff.BackoffBase = 0
+	if espera := nuevoLockoutService(sinBackoff).espera(3, 5); espera != 0 {
+		t.Errorf("sin BackoffBase solo se bloquea al llegar al máximo, se obtuvo %v", espera)
+	}
+}
+
+func TestBloqueoPorFallos(t *testing.T) {
+	const correo = "bloqueo@example.com"
+
+	casos := []struct {
+		nombre string
+		// fallos de login de la cuenta desde ip, uno por cada IP de la lista
+		ips    []string
+		exito  bool
+		correo string
+		ip     string
+		err    error
+	}{
+		{"primer fallo no espera", []string{"10.0.0.1"}, false, correo, "10.0.0.1", nil},
+		{"segundo fallo espera", []string{"10.0.0.1", "10.0.0.1"}, false, correo, "10.0.0.1", ErrDemasiadosIntentos},
+		{"la espera es de la cuenta, no solo de la IP", []string{"10.0.0.1", "10.0.0.2"}, false, correo, "10.0.0.3", ErrDemasiadosIntentos},
+		{"máximo de fallos bloquea la cuenta", []string{"a", "b", "c", "d", "e"}, false, correo, "f", ErrCuentaBloqueada},
+		{"el correo se normaliza", []string{"10.0.0.1", "10.0.0.1"}, false, " BLOQUEO@example.com ", "", ErrDemasiadosIntentos},
+		{"la espera de la IP alcanza a otras cuentas", []string{"10.0.0.1", "10.0.0.1"}, false, "otra@example.com", "10.0.0.1", ErrDemasiadosIntentos},
+		{"otra cuenta desde otra IP no espera", []string{"10.0.0.1", "10.0.0.1"}, false, "otra@example.com", "10.0.0.9", nil},
+		{"un login correcto reinicia la cuenta", []string{"10.0.0.1", "10.0.0.2"}, true, correo, "10.0.0.3", nil},
+		{"un login correcto no reinicia la IP", []string{"10.0.0.1", "10.0.0.1"}, true, "otra@example.com", "10.0.0.1", ErrDemasiadosIntentos},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			ctx := context.Background()
+			s := nuevoLockoutService(lockoutPrueba)
+			for _, ip := range c.ips {
+				if err := s.RegistrarFallo(ctx, correo, ip); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if c.exito {
+				if err := s.RegistrarExito(ctx, correo); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := s.Comprobar(ctx, c.correo, c.ip)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("se esperaba %v, se obtuvo %v", c.err, err)
+			}
+			var bloqueo *BloqueoError
+			if errors.As(err, &bloqueo) && !bloqueo.Hasta.After(time.Now()) {
+				t.Fatalf("el bloqueo debe terminar en el futuro, termina %v", bloqueo.Hasta)
+			}
+		})
+	}
+}
+
+func TestBloqueoSegundoFactor(t *testing.T) {
+	const usuarioID = 3
+
+	casos := []struct {
+		nombre string
+		fallos int
+		exito  bool
+		err    error
+	}{
+		{"un código incorrecto no espera", 1, false, nil},
+		{"dos códigos incorrectos esperan", 2, false, ErrDemasiadosIntentos},
+		{"el máximo de fallos bloquea", lockoutPrueba.MaxFailedAttempts, false, ErrDemasiadosIntentos},
+		{"un código correcto reinicia el contador", 2, true, nil},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			ctx := context.Background()
+			s := nuevoLockoutService(lockoutPrueba)
+			for range c.fallos {
+				if err := s.RegistrarFalloSegundoFactor(ctx, usuarioID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if c.exito {
+				if err := s.RegistrarExitoSegundoFactor(ctx, usuarioID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := s.ComprobarSegundoFactor(ctx, usuarioID); !errors.Is(err, c.err) {
+				t.Fatalf("se esperaba %v, se obtuvo %v", c.err, err)
+			}
+			// Los códigos incorrectos no bloquean el login ni a otros usuarios
+			if err := s.ComprobarSegundoFactor(ctx, usuarioID+1); err != nil {
+				t.Fatalf("otro usuario no debe esperar: %v", err)
+			}
+		})
+	}
+}
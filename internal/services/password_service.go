@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrTokenRestablecimientoInvalido = errors.New("INVALID_RESET_TOKEN")
+
+// PasswordService implementa la recuperación de contraseña con tokens de un
+// solo uso enviados por correo.
+type PasswordService struct {
+	users         repository.UserRepository
+	tokens        repository.UserTokenRepository
+	mail          clients.MailSender
+	authService   *AuthService
+	resetTTL      time.Duration
+	resetInterval time.Duration
+	frontendURL   string
+}
+
+func NewPasswordService(users repository.UserRepository, tokens repository.UserTokenRepository, mail clients.MailSender, authService *AuthService, resetTTL, resetInterval time.Duration, frontendURL string) *PasswordService {
+	return &PasswordService{
+		users:         users,
+		tokens:        tokens,
+		mail:          mail,
+		authService:   authService,
+		resetTTL:      resetTTL,
+		resetInterval: resetInterval,
+		frontendURL:   frontendURL,
+	}
+}
+
+// SolicitarRestablecimiento genera un token de restablecimiento y lo envía al
+// correo del usuario. No informa si el correo existe, para no permitir
+// enumerar cuentas; el envío se hace en segundo plano por la misma razón.
+// Si el último enlace se envió hace menos de resetInterval no se envía otro,
+// también sin avisar, y el anterior sigue siendo válido.
+func (s *PasswordService) SolicitarRestablecimiento(ctx context.Context, correo string) error {
+	usuario, err := s.users.FindByEmail(ctx, correo)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ultimo, err := s.tokens.FindLatestForUser(ctx, usuario.ID, models.PropositoRestablecerContrasena)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if ultimo != nil && time.Since(ultimo.CreadoEn) < s.resetInterval {
+		return nil
+	}
+
+	// Solo el último enlace enviado es válido
+	if err := s.tokens.InvalidateForUser(ctx, usuario.ID, models.PropositoRestablecerContrasena); err != nil {
+		return err
+	}
+
+	token, err := generarTokenOpaco()
+	if err != nil {
+		return err
+	}
+	err = s.tokens.Create(ctx, &models.UserToken{
+		UsuarioID: usuario.ID,
+		Proposito: models.PropositoRestablecerContrasena,
+		TokenHash: hashToken(token),
+		ExpiraEn:  time.Now().Add(s.resetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.Correo,
+		Subject: "Restablecimiento de contraseña",
+		Body: fmt.Sprintf(`Hola %s,
+
+Recibimos una solicitud para restablecer la contraseña de tu cuenta.
+Para elegir una nueva contraseña abre el siguiente enlace (válido por %s):
+
+%s/reset-password?token=%s
+
+Si no solicitaste este cambio puedes ignorar este correo.
+`, usuario.NombreCompleto, formatDuration(s.resetTTL), s.frontendURL, url.QueryEscape(token)),
+	})
+	return nil
+}
+
+// RestablecerContrasena consume el token, guarda la nueva contraseña y cierra
+// todas las sesiones existentes del usuario.
+func (s *PasswordService) RestablecerContrasena(ctx context.Context, token, nuevaContrasena string) error {
+	if len(nuevaContrasena) < 8 {
+		return ErrDatosEnviados
+	}
+
+	stored, err := s.tokens.FindByHash(ctx, models.PropositoRestablecerContrasena, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTokenRestablecimientoInvalido
+		}
+		return err
+	}
+	if stored.UsadoEn != nil || time.Now().After(stored.ExpiraEn) {
+		return ErrTokenRestablecimientoInvalido
+	}
+
+	if err := s.tokens.MarkUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, repository.ErrAlreadyUsed) {
+			return ErrTokenRestablecimientoInvalido
+		}
+		return err
+	}
+
+	usuario, err := s.users.FindByID(ctx, stored.UsuarioID)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(nuevaContrasena), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	usuario.ContrasenaHash = string(hash)
+	// Abrir el enlace recibido por correo también demuestra que el correo es suyo
+	usuario.CorreoVerificado = true
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return err
+	}
+
+	return s.authService.RevocarTokensUsuario(ctx, usuario.ID, "restablecimiento de contraseña")
+}
+
+// enviarCorreoEnSegundoPlano envía el correo sin bloquear la petición: la
+// respuesta no debe depender (ni en tiempo ni en resultado) del servidor SMTP.
+func enviarCorreoEnSegundoPlano(mail clients.MailSender, usuarioID int, msg clients.MailMessage) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mail.Send(ctx, msg); err != nil {
+			log.Printf("Error enviando correo %q al usuario %d: %v", msg.Subject, usuarioID, err)
+		}
+	}()
+}
+
+// formatDuration expresa una duración en minutos u horas para los correos
+func formatDuration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		h := int(d.Hours())
+		if h == 1 {
+			return "1 hora"
+		}
+		return fmt.Sprintf("%d horas", h)
+	}
+	return fmt.Sprintf("%d minutos", int(d.Minutes()))
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+// Permisos conocidos. Los que terminan en :own solo valen sobre recursos del
+// propio usuario; el mismo permiso con :any vale sobre cualquier recurso.
+const (
+	PermisoLeerCasoPropio              = "case:read:own"
+	PermisoLeerCualquierCaso           = "case:read:any"
+	PermisoCrearCaso                   = "case:create"
+	PermisoLeerPrediagnosticoPropio    = "prediagnostic:read:own"
+	PermisoLeerCualquierPrediagnostico = "prediagnostic:read:any"
+	PermisoCrearDiagnostico            = "diagnostic:create"
+	PermisoAdministrarUsuarios         = "user:admin"
+)
+
+var ErrPermisoDenegado = errors.New("PERMISSION_DENIED")
+
+// Recurso identifica sobre qué se comprueba un permiso :own
+type Recurso struct {
+	// PropietarioID es el id_usuario dueño del recurso
+	PropietarioID string
+}
+
+// PermissionService resuelve los permisos del usuario autenticado a partir de
+// sus roles y la asignación rol → permisos de la configuración
+// (auth.permissions).
+type PermissionService struct {
+	users    repository.UserRepository
+	permisos map[string][]string
+}
+
+func NewPermissionService(users repository.UserRepository, permisos map[string][]string) *PermissionService {
+	return &PermissionService{
+		users:    users,
+		permisos: permisos,
+	}
+}
+
+// Can verifica que el usuario autenticado en ctx tenga permiso sobre recurso.
+// Un permiso :own se cumple con el mismo permiso :any, o con :own si el
+// usuario es el propietario de recurso (nil no pertenece a nadie).
+func (s *PermissionService) Can(ctx context.Context, permiso string, recurso *Recurso) error {
+	claims, err := ClaimsFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	candidatos := []string{permiso}
+	if base, ok := strings.CutSuffix(permiso, ":own"); ok {
+		candidatos = []string{base + ":any"}
+		if recurso != nil && recurso.PropietarioID == claims.UserID {
+			candidatos = append(candidatos, permiso)
+		}
+	}
+	return s.otorgado(ctx, claims, permiso, candidatos)
+}
+
+// TienePermiso verifica que el usuario autenticado tenga permiso con
+// cualquier alcance; la propiedad de un recurso :own se comprueba después con
+// Can. Lo usa la directiva @hasPermission de GraphQL.
+func (s *PermissionService) TienePermiso(ctx context.Context, permiso string) error {
+	claims, err := ClaimsFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	candidatos := []string{permiso}
+	if base, ok := strings.CutSuffix(permiso, ":own"); ok {
+		candidatos = append(candidatos, base+":any")
+	}
+	return s.otorgado(ctx, claims, permiso, candidatos)
+}
+
+// otorgado busca un rol del usuario que otorgue alguno de los candidatos. Los
+// permisos del rol doctor solo valen mientras la cuenta siga aprobada.
+func (s *PermissionService) otorgado(ctx context.Context, claims *UserClaims, permiso string, candidatos []string) error {
+	var errDoctor error
+	for _, rol := range claims.Roles {
+		if !slices.ContainsFunc(s.permisos[rol], func(p string) bool { return slices.Contains(candidatos, p) }) {
+			continue
+		}
+		if rol != models.RolDoctor {
+			return nil
+		}
+		if errDoctor = comprobarDoctorAprobado(ctx, s.users, claims.UserID); errDoctor == nil {
+			return nil
+		}
+	}
+	if errDoctor != nil {
+		return errDoctor
+	}
+	return fmt.Errorf("%w: se requiere %s", ErrPermisoDenegado, permiso)
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/graph/model"
+)
+
+// Mapeo de las respuestas del servicio prediagnostic (clients.*DTO) a los
+// modelos de GraphQL. Cuando falta un campo obligatorio se retorna un error
+// que nombra el recurso y el campo.
+
+func faltaCampo(recurso, campo string) error {
+	return fmt.Errorf("%s: falta el campo %s en la respuesta del servicio prediagnostic", recurso, campo)
+}
+
+// caseSummaryToGraph convierte un caso de los listados en un Case. El nombre
+// y el correo del paciente los completa después CaseService.completarPacientes.
+func caseSummaryToGraph(c clients.CaseSummaryDTO, publicURL string) (*model.Case, error) {
+	caseID := c.CaseID()
+	if caseID == "" {
+		return nil, errors.New("caso sin prediagnostico_id ni id en la respuesta del servicio prediagnostic")
+	}
+
+	pacienteID := ""
+	if c.UserID != nil {
+		pacienteID = c.UserID.String()
+	}
+
+	fechaSubida := formatearFecha(c.Fecha)
+
+	// Los resultados del modelo vienen directamente en el caso
+	var resultados *model.ResultadosModelo
+	if c.Probabilidad != nil {
+		resultados = &model.ResultadosModelo{
+			ProbNeumonia:       *c.Probabilidad,
+			Etiqueta:           valorOPorDefecto(c.DiagnosticoIA, "Sin diagnóstico"),
+			FechaProcesamiento: fechaSubida, // el listado no trae la fecha de procesamiento
+		}
+	}
+
+	url := urlRadiografia(publicURL, valorOPorDefecto(c.RadiografiaRuta, ""))
+	if url == "" {
+		url = "/placeholder-radiography.jpg"
+	}
+
+	doctorAsignado := valorOPorDefecto(c.DoctorAsignado, "")
+	return &model.Case{
+		ID:             caseID,
+		PacienteID:     pacienteID,
+		PacienteNombre: valorOPorDefecto(c.PacienteNombre, pacienteNoDisponible),
+		FechaSubida:    fechaSubida,
+		Estado:         estandarizarEstado(c.Estado),
+		URLRadiografia: url,
+		Resultados:     resultados,
+		DoctorAsignado: &doctorAsignado,
+	}, nil
+}
+
+// caseDetailToGraph convierte la respuesta de GET /prediagnostic/case/{id} en
+// el CaseDetail de HU7. CaseDetail.diagnostic lo resuelve aparte su resolver.
+func caseDetailToGraph(c *clients.CaseDTO, publicURL string) (*model.CaseDetail, error) {
+	if c.PrediagnosticoID == "" {
+		return nil, faltaCampo("caso", "prediagnostico_id")
+	}
+	recurso := "caso " + c.PrediagnosticoID
+	if c.UserID == "" {
+		return nil, faltaCampo(recurso, "user_id")
+	}
+
+	estado := valorOPorDefecto(c.Estado, "Pendiente")
+	fechaSubida := formatearFecha(c.FechaSubida)
+
+	var resultados *model.ResultadosModelo
+	if r := c.ResultadoModelo; r != nil {
+		resultados = &model.ResultadosModelo{
+			Etiqueta:           valorOPorDefecto(r.Etiqueta, "No disponible"),
+			FechaProcesamiento: formatearFecha(c.FechaProcesamiento),
+		}
+		if r.ProbabilidadNeumonia != nil {
+			resultados.ProbNeumonia = *r.ProbabilidadNeumonia
+		}
+	}
+
+	url := urlRadiografia(publicURL, valorOPorDefecto(c.RadiografiaRuta, ""))
+	return &model.CaseDetail{
+		ID:            c.PrediagnosticoID,
+		RadiografiaID: c.PrediagnosticoID, // mismo ID para simplificar
+		URLImagen:     url,
+		Estado:        estado,
+		FechaSubida:   fechaSubida,
+		PreDiagnostic: &model.PreDiagnostic{
+			PrediagnosticID:  c.PrediagnosticoID,
+			PacienteID:       c.UserID.String(),
+			Urlrad:           url,
+			Estado:           estado,
+			ResultadosModelo: resultados,
+			FechaSubida:      fechaSubida,
+		},
+	}, nil
+}
+
+// preDiagnosticToGraph convierte la respuesta de GET /prediagnostic/case/{id}
+// en el PreDiagnostic de getPreDiagnostic, que exige todos los campos
+func preDiagnosticToGraph(id string, c *clients.CaseDTO) (*model.PreDiagnostic, error) {
+	recurso := "prediagnóstico " + id
+	switch {
+	case c.UserID == "":
+		return nil, faltaCampo(recurso, "user_id")
+	case c.RadiografiaRuta == nil:
+		return nil, faltaCampo(recurso, "radiografia_ruta")
+	case c.Estado == nil:
+		return nil, faltaCampo(recurso, "estado")
+	case c.FechaSubida == nil:
+		return nil, faltaCampo(recurso, "fecha_subida")
+	case c.FechaProcesamiento == nil:
+		return nil, faltaCampo(recurso, "fecha_procesamiento")
+	case c.ResultadoModelo == nil:
+		return nil, faltaCampo(recurso, "resultado_modelo")
+	case c.ResultadoModelo.ProbabilidadNeumonia == nil:
+		return nil, faltaCampo(recurso, "resultado_modelo.probabilidad_neumonia")
+	case c.ResultadoModelo.Etiqueta == nil:
+		return nil, faltaCampo(recurso, "resultado_modelo.etiqueta")
+	}
+
+	return &model.PreDiagnostic{
+		PrediagnosticID: id,
+		PacienteID:      c.UserID.String(),
+		Urlrad:          *c.RadiografiaRuta,
+		Estado:          *c.Estado,
+		ResultadosModelo: &model.ResultadosModelo{
+			ProbNeumonia:       *c.ResultadoModelo.ProbabilidadNeumonia,
+			Etiqueta:           *c.ResultadoModelo.Etiqueta,
+			FechaProcesamiento: *c.FechaProcesamiento,
+		},
+		FechaSubida: *c.FechaSubida,
+	}, nil
+}
+
+// diagnosticToGraph convierte el diagnóstico médico; los nombres de la base
+// del servicio difieren de los del esquema GraphQL
+func diagnosticToGraph(caseID string, d *clients.DiagnosticDTO) (*model.Diagnostic, error) {
+	recurso := "diagnóstico del caso " + caseID
+	if d.DiagnosticID() == "" {
+		return nil, faltaCampo(recurso, "id")
+	}
+	if d.CaseID == "" {
+		return nil, faltaCampo(recurso, "case_id")
+	}
+
+	return &model.Diagnostic{
+		ID:               d.DiagnosticID(),
+		PrediagnosticoID: d.CaseID,          // "case_id" → "prediagnosticoId"
+		Aprobacion:       d.Validacion,      // "validacion" → "aprobacion"
+		Comentarios:      d.Diagnostico,     // "diagnostico" → "comentarios"
+		FechaRevision:    d.FechaValidacion, // "fecha_validacion" → "fechaRevision"
+		DoctorNombre:     d.DoctorNombre,
+	}, nil
+}
+
+// urlRadiografia construye la URL pública de la imagen a partir de la ruta
+// guardada por el servicio (ej. "storage\\radiografias\\RAD-xxx.jpg")
+func urlRadiografia(publicURL, ruta string) string {
+	if ruta == "" {
+		return ""
+	}
+	partes := strings.Split(ruta, "\\")
+	return fmt.Sprintf("%s/prediagnostic/image/%s", publicURL, partes[len(partes)-1])
+}
+
+func valorOPorDefecto(valor *string, porDefecto string) string {
+	if valor == nil {
+		return porDefecto
+	}
+	return *valor
+}
+
+// formatearFecha muestra las fechas RFC3339 como "02/01/2006 15:04"; otros
+// formatos se muestran tal como llegan
+func formatearFecha(fecha *string) string {
+	if fecha == nil {
+		return "Fecha no disponible"
+	}
+	if parsedTime, err := time.Parse(time.RFC3339, *fecha); err == nil {
+		return parsedTime.Format("02/01/2006 15:04")
+	}
+	return *fecha
+}
+
+// estandarizarEstado traduce los estados del servicio a un formato legible
+func estandarizarEstado(estado *string) string {
+	if estado == nil {
+		return "Estado desconocido"
+	}
+	switch *estado {
+	case "pending":
+		return "Pendiente"
+	case "processing":
+		return "En procesamiento"
+	case "completed":
+		return "Completado"
+	case "error":
+		return "Error"
+	case "reviewed":
+		return "Revisado"
+	default:
+		return *estado
+	}
+}
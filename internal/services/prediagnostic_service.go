@@ -1,39 +1,55 @@
 package services
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
 	"github.com/unobeswarch/businesslogic/internal/clients"
 	"github.com/unobeswarch/businesslogic/internal/graph/model"
 )
 
 type PreDiagnosticService struct {
-	client *clients.PreDiagnosticClient
+	client clients.PrediagnosticAPI
 }
 
-func NewPrediagnosticService(baseURL string) *PreDiagnosticService {
+func NewPrediagnosticService(client clients.PrediagnosticAPI) *PreDiagnosticService {
 	return &PreDiagnosticService{
-		client: clients.NewPrediagnosticClient(baseURL),
+		client: client,
 	}
 }
 
 // GetPreDiagnosticByID busca un prediagnóstico por ID
-func (s *PreDiagnosticService) GetPreDiagnosticByID(id string) (*model.PreDiagnostic, error) {
-	data, err := s.client.GetPreDiagnostic(id)
+func (s *PreDiagnosticService) GetPreDiagnosticByID(ctx context.Context, id string) (*model.PreDiagnostic, error) {
+	data, err := s.client.GetPreDiagnostic(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Mapear JSON -> GraphQL model (usando los nombres correctos del JSON)
-	resultados := data["resultado_modelo"].(map[string]interface{})
-	return &model.PreDiagnostic{
-		PrediagnosticID: id,
-		PacienteID:      data["user_id"].(string),
-		Urlrad:          data["radiografia_ruta"].(string),
-		Estado:          data["estado"].(string),
-		ResultadosModelo: &model.ResultadosModelo{
-			ProbNeumonia:       resultados["probabilidad_neumonia"].(float64),
-			Etiqueta:           resultados["etiqueta"].(string),
-			FechaProcesamiento: data["fecha_procesamiento"].(string),
-		},
-		FechaSubida: data["fecha_subida"].(string),
-	}, nil
+	return preDiagnosticToGraph(id, data)
+}
+
+// GetPreDiagnosticsByIDs busca varios prediagnósticos para PreDiagnosticByID
+func (s *PreDiagnosticService) GetPreDiagnosticsByIDs(ctx context.Context, ids []string) ([]*model.PreDiagnostic, []error) {
+	return cargarEnParalelo(ids, func(id string) (*model.PreDiagnostic, error) {
+		return s.GetPreDiagnosticByID(ctx, id)
+	})
+}
+
+// UploadImage valida la radiografía y la envía al servicio prediagnostic
+func (s *PreDiagnosticService) UploadImage(ctx context.Context, userID, filename string, imagen io.Reader) error {
+	if !strings.HasSuffix(strings.ToLower(filename), ".jpg") {
+		return fmt.Errorf("solo se permiten archivos con extensión .jpg")
+	}
+
+	respuesta, err := s.client.ProcessImage(ctx, userID, filename, imagen)
+	if err != nil {
+		return err
+	}
+	if respuesta.PrediagnosticoID != nil {
+		log.Printf("Radiografía %s del usuario %s enviada como prediagnóstico %s", filename, userID, *respuesta.PrediagnosticoID)
+	}
+	return nil
 }
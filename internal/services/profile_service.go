@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrIdentificacionEnUso  = errors.New("IDENTIFICATION_ALREADY_REGISTERED")
+	ErrContrasenaIncorrecta = errors.New("INVALID_CURRENT_PASSWORD")
+)
+
+// CambiosPerfil son los datos del registro que el usuario puede cambiar. Los
+// campos nil no se modifican.
+type CambiosPerfil struct {
+	NombreCompleto *string
+	Edad           *int
+	Correo         *string
+	Identificacion *string
+}
+
+// ProfileService permite al usuario autenticado consultar y actualizar los
+// datos que dio al registrarse y cambiar su contraseña.
+type ProfileService struct {
+	users               repository.UserRepository
+	verificationService *VerificationService
+	authService         *AuthService
+	mail                clients.MailSender
+}
+
+func NewProfileService(users repository.UserRepository, verificationService *VerificationService, authService *AuthService, mail clients.MailSender) *ProfileService {
+	return &ProfileService{
+		users:               users,
+		verificationService: verificationService,
+		authService:         authService,
+		mail:                mail,
+	}
+}
+
+func (s *ProfileService) ObtenerPerfil(ctx context.Context, usuarioID int) (*models.User, error) {
+	usuario, err := s.users.FindByID(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUsuarioNoEncontrado
+		}
+		return nil, err
+	}
+	return usuario, nil
+}
+
+// ActualizarPerfil valida y guarda los cambios del perfil. Un correo nuevo no
+// reemplaza al actual: queda como correo pendiente hasta que el usuario abra
+// el enlace que se le envía (VerificationService.ConfirmarCambioCorreo).
+func (s *ProfileService) ActualizarPerfil(ctx context.Context, usuarioID int, cambios CambiosPerfil) (*models.User, error) {
+	usuario, err := s.ObtenerPerfil(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cambios.NombreCompleto != nil {
+		nombre := strings.TrimSpace(*cambios.NombreCompleto)
+		if nombre == "" || len(nombre) > 150 {
+			return nil, ErrDatosEnviados
+		}
+		usuario.NombreCompleto = nombre
+	}
+
+	if cambios.Edad != nil {
+		if *cambios.Edad <= 0 || *cambios.Edad > 130 {
+			return nil, ErrDatosEnviados
+		}
+		usuario.Edad = *cambios.Edad
+	}
+
+	if cambios.Identificacion != nil {
+		identificacion := strings.TrimSpace(*cambios.Identificacion)
+		if identificacion == "" || len(identificacion) > 50 {
+			return nil, ErrDatosEnviados
+		}
+		if identificacion != usuario.Identificacion {
+			if err := comprobarLibre(s.users.FindByIdentificacion(ctx, identificacion)); err != nil {
+				if errors.Is(err, ErrUsuarioExistente) {
+					return nil, ErrIdentificacionEnUso
+				}
+				return nil, err
+			}
+			usuario.Identificacion = identificacion
+		}
+	}
+
+	correoNuevo := false
+	if cambios.Correo != nil {
+		correo := strings.TrimSpace(*cambios.Correo)
+		if direccion, err := mail.ParseAddress(correo); err != nil || direccion.Address != correo || len(correo) > 255 {
+			return nil, ErrDatosEnviados
+		}
+		switch correo {
+		case usuario.Correo:
+			// Volver al correo actual cancela el cambio pendiente
+			usuario.CorreoPendiente = ""
+		case usuario.CorreoPendiente:
+		default:
+			if err := comprobarLibre(s.users.FindByEmail(ctx, correo)); err != nil {
+				if errors.Is(err, ErrUsuarioExistente) {
+					return nil, ErrCorreoEnUso
+				}
+				return nil, err
+			}
+			usuario.CorreoPendiente = correo
+			correoNuevo = true
+		}
+	}
+
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return nil, err
+	}
+	if correoNuevo {
+		if err := s.verificationService.EnviarCambioCorreo(ctx, usuario); err != nil {
+			return nil, err
+		}
+	}
+	return usuario, nil
+}
+
+// comprobarLibre recibe el resultado de buscar otro usuario por un dato único
+// y retorna ErrUsuarioExistente si lo encontró
+func comprobarLibre(_ *models.User, err error) error {
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return ErrUsuarioExistente
+}
+
+// CambiarContrasena verifica la contraseña actual, guarda la nueva y cierra
+// todas las sesiones del usuario, incluida la actual.
+func (s *ProfileService) CambiarContrasena(ctx context.Context, usuarioID int, actual, nueva string) error {
+	if len(nueva) < 8 || nueva == actual {
+		return ErrDatosEnviados
+	}
+	usuario, err := s.ObtenerPerfil(ctx, usuarioID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(usuario.ContrasenaHash), []byte(actual)); err != nil {
+		return ErrContrasenaIncorrecta
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(nueva), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	usuario.ContrasenaHash = string(hash)
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return err
+	}
+	if err := s.authService.RevocarTokensUsuario(ctx, usuario.ID, "cambio de contraseña"); err != nil {
+		return err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.Correo,
+		Subject: "Tu contraseña fue cambiada",
+		Body: fmt.Sprintf(`Hola %s,
+
+La contraseña de tu cuenta acaba de cambiar y se cerraron todas tus sesiones.
+Si no hiciste este cambio, restablece tu contraseña de inmediato.
+`, usuario.NombreCompleto),
+	})
+	return nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+// RevocationService consulta la lista de revocación de tokens con una caché
+// en proceso, para no ir a PostgreSQL en cada petición autenticada.
+//
+// Las revocaciones hechas por esta réplica se reflejan de inmediato; las
+// hechas por otras réplicas se ven como máximo después de cacheTTL.
+type RevocationService struct {
+	repo     repository.TokenRevocationRepository
+	cacheTTL time.Duration
+
+	mu        sync.Mutex
+	tokens    map[string]revocationEntry
+	users     map[int]userRevocationEntry
+	lastPurge time.Time
+}
+
+type revocationEntry struct {
+	revoked   bool
+	expiresAt time.Time // hasta cuándo es válida la entrada en caché
+}
+
+type userRevocationEntry struct {
+	since     *time.Time
+	expiresAt time.Time
+}
+
+func NewRevocationService(repo repository.TokenRevocationRepository, cacheTTL time.Duration) *RevocationService {
+	return &RevocationService{
+		repo:     repo,
+		cacheTTL: cacheTTL,
+		tokens:   make(map[string]revocationEntry),
+		users:    make(map[int]userRevocationEntry),
+	}
+}
+
+// IsRevoked indica si el token identificado por jti (emitido en issuedAt para
+// el usuario dado) fue revocado individualmente o por una revocación masiva.
+func (s *RevocationService) IsRevoked(ctx context.Context, jti string, usuarioID int, issuedAt time.Time) (bool, error) {
+	since, err := s.userRevokedSince(ctx, usuarioID)
+	if err != nil {
+		return false, err
+	}
+	// Los claims iat tienen resolución de segundos: un token emitido en el
+	// mismo segundo que la revocación se considera revocado, aunque sea
+	// posterior, porque no se puede saber si fue emitido antes
+	if since != nil && !issuedAt.After(since.Truncate(time.Second)) {
+		return true, nil
+	}
+
+	return s.tokenRevoked(ctx, jti)
+}
+
+// RevokeToken agrega un access token a la lista de revocación hasta su expiración
+func (s *RevocationService) RevokeToken(ctx context.Context, jti string, usuarioID int, expiresAt time.Time) error {
+	if err := s.repo.RevokeToken(ctx, jti, usuarioID, expiresAt); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Una revocación es definitiva: se cachea hasta que el token expire
+	s.tokens[jti] = revocationEntry{revoked: true, expiresAt: expiresAt}
+	return nil
+}
+
+// RevokeUser invalida todos los access tokens emitidos hasta ahora para el usuario
+func (s *RevocationService) RevokeUser(ctx context.Context, usuarioID int, motivo string) error {
+	now := time.Now()
+	if err := s.repo.RevokeUserTokens(ctx, usuarioID, now, motivo); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[usuarioID] = userRevocationEntry{since: &now, expiresAt: now.Add(s.cacheTTL)}
+	return nil
+}
+
+func (s *RevocationService) tokenRevoked(ctx context.Context, jti string) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.tokens[jti]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := s.repo.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = revocationEntry{revoked: revoked, expiresAt: now.Add(s.cacheTTL)}
+	s.purgeCacheLocked(now)
+	s.mu.Unlock()
+
+	return revoked, nil
+}
+
+func (s *RevocationService) userRevokedSince(ctx context.Context, usuarioID int) (*time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.users[usuarioID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.since, nil
+	}
+
+	since, err := s.repo.UserRevokedSince(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.users[usuarioID] = userRevocationEntry{since: since, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return since, nil
+}
+
+// purgeCacheLocked elimina entradas vencidas de la caché y, como mucho una vez
+// por hora, las revocaciones expiradas de la base de datos.
+func (s *RevocationService) purgeCacheLocked(now time.Time) {
+	if now.Sub(s.lastPurge) < time.Hour {
+		return
+	}
+	s.lastPurge = now
+
+	for jti, entry := range s.tokens {
+		if now.After(entry.expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for id, entry := range s.users {
+		if now.After(entry.expiresAt) {
+			delete(s.users, id)
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.repo.PurgeExpired(ctx); err != nil {
+			log.Printf("Warning: no se pudieron purgar tokens revocados expirados: %v", err)
+		}
+	}()
+}
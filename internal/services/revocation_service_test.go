@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+func TestIsRevokedCorteRevocacionMasiva(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryTokenRevocationRepository()
+	revocations := NewRevocationService(repo, time.Minute)
+
+	const usuarioID = 7
+	if err := revocations.RevokeUser(ctx, usuarioID, "prueba"); err != nil {
+		t.Fatal(err)
+	}
+	desde, err := repo.UserRevokedSince(ctx, usuarioID)
+	if err != nil || desde == nil {
+		t.Fatalf("no quedó registrada la revocación: %v", err)
+	}
+	segundo := desde.Truncate(time.Second)
+
+	casos := []struct {
+		nombre    string
+		jti       string
+		usuarioID int
+		emitido   time.Time
+		revocado  bool
+	}{
+		{"emitido antes de la revocación", "antes", usuarioID, segundo.Add(-time.Hour), true},
+		// iat tiene resolución de segundos: un token con el iat del segundo de
+		// la revocación pudo emitirse antes o después, y se trata como revocado
+		{"iat en el segundo de la revocación", "mismo-segundo", usuarioID, segundo, true},
+		{"emitido en el segundo siguiente", "despues", usuarioID, segundo.Add(time.Second), false},
+		{"otro usuario", "otro", usuarioID + 1, segundo.Add(-time.Hour), false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			revocado, err := revocations.IsRevoked(ctx, c.jti, c.usuarioID, c.emitido)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revocado != c.revocado {
+				t.Fatalf("se esperaba revocado=%v, se obtuvo %v", c.revocado, revocado)
+			}
+		})
+	}
+}
+
+func TestIsRevokedTokenIndividual(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryTokenRevocationRepository()
+	revocations := NewRevocationService(repo, time.Minute)
+	ahora := time.Now()
+
+	if err := revocations.RevokeToken(ctx, "revocado", 1, ahora.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// Otra réplica con la caché vencida consulta el repositorio
+	otraReplica := NewRevocationService(repo, 0)
+
+	casos := []struct {
+		nombre      string
+		revocations *RevocationService
+		jti         string
+		revocado    bool
+	}{
+		{"token revocado", revocations, "revocado", true},
+		{"token revocado visto desde otra réplica", otraReplica, "revocado", true},
+		{"otro token del mismo usuario", revocations, "vigente", false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			revocado, err := c.revocations.IsRevoked(ctx, c.jti, 1, ahora)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revocado != c.revocado {
+				t.Fatalf("se esperaba revocado=%v, se obtuvo %v", c.revocado, revocado)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var (
+	ErrCodigoSegundoFactorInvalido = errors.New("INVALID_TWO_FACTOR_CODE")
+	ErrDesafioInvalido             = errors.New("INVALID_CHALLENGE_TOKEN")
+	ErrSegundoFactorYaActivo       = errors.New("TWO_FACTOR_ALREADY_ENABLED")
+	ErrSegundoFactorNoActivo       = errors.New("TWO_FACTOR_NOT_ENABLED")
+	ErrSegundoFactorObligatorio    = errors.New("TWO_FACTOR_REQUIRED")
+)
+
+// Parámetros TOTP (RFC 6238) compatibles con las apps autenticadoras habituales
+const (
+	totpPeriodo = 30 // segundos
+	totpDigitos = 6
+	// totpTolerancia acepta el código del paso anterior y del siguiente por desfase de reloj
+	totpTolerancia = 1
+
+	cantidadCodigosRecuperacion = 10
+
+	// maxIntentosDesafio es la cantidad de códigos incorrectos que admite un
+	// desafío; después se consume y hay que volver a ingresar la contraseña
+	maxIntentosDesafio = 5
+)
+
+var totpBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Desafio2FA se entrega en /auth cuando la cuenta requiere segundo factor.
+// Enrolamiento indica que la cuenta debe configurar TOTP antes de continuar.
+type Desafio2FA struct {
+	Token        string
+	ExpiresIn    int64
+	Enrolamiento bool
+}
+
+// Enrolamiento2FA contiene el secreto nuevo y el URI otpauth:// que el
+// frontend muestra como código QR
+type Enrolamiento2FA struct {
+	Secreto string
+	URI     string
+}
+
+// TwoFactorService administra el segundo factor TOTP: enrolamiento, códigos
+// de recuperación y los desafíos del login en dos pasos.
+type TwoFactorService struct {
+	repo    repository.TwoFactorRepository
+	tokens  repository.UserTokenRepository
+	users   repository.UserRepository
+	lockout *LockoutService
+	cfg     config.TwoFactorConfig
+}
+
+func NewTwoFactorService(repo repository.TwoFactorRepository, tokens repository.UserTokenRepository, users repository.UserRepository, lockout *LockoutService, cfg config.TwoFactorConfig) *TwoFactorService {
+	return &TwoFactorService{
+		repo:    repo,
+		tokens:  tokens,
+		users:   users,
+		lockout: lockout,
+		cfg:     cfg,
+	}
+}
+
+// CrearDesafio retorna un desafío si el usuario tiene 2FA activo o su rol lo
+// exige, o nil si puede iniciar sesión solo con la contraseña.
+func (s *TwoFactorService) CrearDesafio(ctx context.Context, usuario *models.User) (*Desafio2FA, error) {
+	activo, err := s.activo(ctx, usuario.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !activo && !s.obligatorio(usuario) {
+		return nil, nil
+	}
+
+	token, err := generarTokenOpaco()
+	if err != nil {
+		return nil, err
+	}
+	err = s.tokens.Create(ctx, &models.UserToken{
+		UsuarioID: usuario.ID,
+		Proposito: models.PropositoDesafioSegundoFactor,
+		TokenHash: hashToken(token),
+		ExpiraEn:  time.Now().Add(s.cfg.ChallengeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Desafio2FA{
+		Token:        token,
+		ExpiresIn:    int64(s.cfg.ChallengeTTL.Seconds()),
+		Enrolamiento: !activo,
+	}, nil
+}
+
+// UsuarioDeDesafio valida el desafío sin consumirlo y retorna su usuario
+func (s *TwoFactorService) UsuarioDeDesafio(ctx context.Context, token string) (*models.User, *models.UserToken, error) {
+	stored, err := s.tokens.FindByHash(ctx, models.PropositoDesafioSegundoFactor, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrDesafioInvalido
+		}
+		return nil, nil, err
+	}
+	if stored.UsadoEn != nil || time.Now().After(stored.ExpiraEn) {
+		return nil, nil, ErrDesafioInvalido
+	}
+
+	usuario, err := s.users.FindByID(ctx, stored.UsuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrDesafioInvalido
+		}
+		return nil, nil, err
+	}
+	return usuario, stored, nil
+}
+
+// CompletarDesafio verifica el código (TOTP o de recuperación) y consume el
+// desafío. Un código incorrecto no consume el desafío, para permitir
+// reintentar, salvo que sea el intento maxIntentosDesafio.
+func (s *TwoFactorService) CompletarDesafio(ctx context.Context, desafio *models.UserToken, codigo string) error {
+	f, err := s.repo.Get(ctx, desafio.UsuarioID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if f == nil || f.ActivadoEn == nil {
+		return ErrSegundoFactorObligatorio
+	}
+
+	if err := s.verificarCodigo(ctx, f, codigo); err != nil {
+		if err == ErrCodigoSegundoFactorInvalido {
+			intentos, errIntento := s.tokens.RegisterFailedAttempt(ctx, desafio.ID)
+			if errIntento != nil {
+				return errIntento
+			}
+			if intentos >= maxIntentosDesafio {
+				if errUso := s.tokens.MarkUsed(ctx, desafio.ID); errUso != nil && !errors.Is(errUso, repository.ErrAlreadyUsed) {
+					return errUso
+				}
+			}
+		}
+		return err
+	}
+
+	if err := s.tokens.MarkUsed(ctx, desafio.ID); err != nil {
+		if errors.Is(err, repository.ErrAlreadyUsed) {
+			return ErrDesafioInvalido
+		}
+		return err
+	}
+	return nil
+}
+
+// Enrolar genera un secreto nuevo pendiente de confirmación
+func (s *TwoFactorService) Enrolar(ctx context.Context, usuarioID int) (*Enrolamiento2FA, error) {
+	usuario, err := s.users.FindByID(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	activo, err := s.activo(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	if activo {
+		return nil, ErrSegundoFactorYaActivo
+	}
+
+	// 160 bits, el tamaño recomendado por RFC 4226 para HMAC-SHA1
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secreto := totpBase32.EncodeToString(raw)
+	if err := s.repo.SavePending(ctx, usuarioID, secreto); err != nil {
+		return nil, err
+	}
+
+	return &Enrolamiento2FA{Secreto: secreto, URI: s.provisioningURI(usuario.Correo, secreto)}, nil
+}
+
+// Confirmar activa el enrolamiento pendiente con un código de la app y
+// retorna los códigos de recuperación (solo se muestran esta vez).
+func (s *TwoFactorService) Confirmar(ctx context.Context, usuarioID int, codigo string) ([]string, error) {
+	f, err := s.repo.Get(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSegundoFactorNoActivo
+		}
+		return nil, err
+	}
+	if f.ActivadoEn != nil {
+		return nil, ErrSegundoFactorYaActivo
+	}
+
+	paso, ok := validarTOTP(f.Secreto, codigo, time.Now())
+	if !ok {
+		return nil, ErrCodigoSegundoFactorInvalido
+	}
+	if err := s.repo.Activate(ctx, usuarioID, paso); err != nil {
+		return nil, err
+	}
+
+	return s.reemplazarCodigosRecuperacion(ctx, usuarioID)
+}
+
+// Desactivar quita el segundo factor tras verificar un código. No se permite
+// para los roles que lo tienen obligatorio.
+func (s *TwoFactorService) Desactivar(ctx context.Context, usuarioID int, codigo string) error {
+	usuario, err := s.users.FindByID(ctx, usuarioID)
+	if err != nil {
+		return err
+	}
+	if s.obligatorio(usuario) {
+		return ErrSegundoFactorObligatorio
+	}
+
+	f, err := s.activado(ctx, usuarioID)
+	if err != nil {
+		return err
+	}
+	if err := s.verificarCodigoLimitado(ctx, f, codigo); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, usuarioID)
+}
+
+// RegenerarCodigosRecuperacion invalida los códigos anteriores y entrega unos nuevos
+func (s *TwoFactorService) RegenerarCodigosRecuperacion(ctx context.Context, usuarioID int, codigo string) ([]string, error) {
+	f, err := s.activado(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verificarCodigoLimitado(ctx, f, codigo); err != nil {
+		return nil, err
+	}
+	return s.reemplazarCodigosRecuperacion(ctx, usuarioID)
+}
+
+func (s *TwoFactorService) activo(ctx context.Context, usuarioID int) (bool, error) {
+	_, err := s.activado(ctx, usuarioID)
+	if errors.Is(err, ErrSegundoFactorNoActivo) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *TwoFactorService) activado(ctx context.Context, usuarioID int) (*models.TwoFactor, error) {
+	f, err := s.repo.Get(ctx, usuarioID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSegundoFactorNoActivo
+		}
+		return nil, err
+	}
+	if f.ActivadoEn == nil {
+		return nil, ErrSegundoFactorNoActivo
+	}
+	return f, nil
+}
+
+// obligatorio indica si alguno de los roles del usuario exige 2FA
+func (s *TwoFactorService) obligatorio(usuario *models.User) bool {
+	return slices.ContainsFunc(usuario.RolesAsignados(), func(rol string) bool {
+		return slices.Contains(s.cfg.RequiredRoles, rol)
+	})
+}
+
+// verificarCodigo acepta un código TOTP de 6 dígitos (una sola vez por paso)
+// o un código de recuperación sin usar
+func (s *TwoFactorService) verificarCodigo(ctx context.Context, f *models.TwoFactor, codigo string) error {
+	codigo = strings.TrimSpace(codigo)
+
+	if esCodigoTOTP(codigo) {
+		paso, ok := validarTOTP(f.Secreto, codigo, time.Now())
+		if !ok {
+			return ErrCodigoSegundoFactorInvalido
+		}
+		if err := s.repo.UseStep(ctx, f.UsuarioID, paso); err != nil {
+			if errors.Is(err, repository.ErrAlreadyUsed) {
+				return ErrCodigoSegundoFactorInvalido
+			}
+			return err
+		}
+		return nil
+	}
+
+	err := s.repo.UseRecoveryCode(ctx, f.UsuarioID, hashToken(normalizarCodigoRecuperacion(codigo)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCodigoSegundoFactorInvalido
+		}
+		return err
+	}
+	return nil
+}
+
+// verificarCodigoLimitado es verificarCodigo para las operaciones con access
+// token, que no tienen un desafío que limite los intentos: los códigos
+// incorrectos se cuentan por usuario en LockoutService.
+func (s *TwoFactorService) verificarCodigoLimitado(ctx context.Context, f *models.TwoFactor, codigo string) error {
+	if err := s.lockout.ComprobarSegundoFactor(ctx, f.UsuarioID); err != nil {
+		return err
+	}
+	if err := s.verificarCodigo(ctx, f, codigo); err != nil {
+		if err == ErrCodigoSegundoFactorInvalido {
+			if errFallo := s.lockout.RegistrarFalloSegundoFactor(ctx, f.UsuarioID); errFallo != nil {
+				return errFallo
+			}
+		}
+		return err
+	}
+	return s.lockout.RegistrarExitoSegundoFactor(ctx, f.UsuarioID)
+}
+
+func (s *TwoFactorService) reemplazarCodigosRecuperacion(ctx context.Context, usuarioID int) ([]string, error) {
+	codigos := make([]string, cantidadCodigosRecuperacion)
+	hashes := make([]string, cantidadCodigosRecuperacion)
+	for i := range codigos {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		// 10 caracteres base32 (50 bits) mostrados como xxxxx-xxxxx
+		c := strings.ToLower(totpBase32.EncodeToString(raw))[:10]
+		codigos[i] = c[:5] + "-" + c[5:]
+		hashes[i] = hashToken(c)
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, usuarioID, hashes); err != nil {
+		return nil, err
+	}
+	return codigos, nil
+}
+
+// provisioningURI arma el URI otpauth:// (formato Key URI de Google Authenticator)
+func (s *TwoFactorService) provisioningURI(correo, secreto string) string {
+	q := url.Values{}
+	q.Set("secret", secreto)
+	q.Set("issuer", s.cfg.Issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigitos))
+	q.Set("period", fmt.Sprint(totpPeriodo))
+
+	label := url.PathEscape(s.cfg.Issuer + ":" + correo)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// validarTOTP compara el código con los pasos vecinos al actual y retorna el
+// paso que coincidió
+func validarTOTP(secreto, codigo string, now time.Time) (int64, bool) {
+	key, err := totpBase32.DecodeString(strings.ToUpper(secreto))
+	if err != nil || !esCodigoTOTP(codigo) {
+		return 0, false
+	}
+
+	actual := now.Unix() / totpPeriodo
+	for d := int64(-totpTolerancia); d <= totpTolerancia; d++ {
+		if hmac.Equal([]byte(codigoTOTP(key, actual+d)), []byte(codigo)) {
+			return actual + d, true
+		}
+	}
+	return 0, false
+}
+
+// codigoTOTP calcula el HOTP (RFC 4226) del paso con HMAC-SHA1
+func codigoTOTP(key []byte, paso int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(paso))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigitos, bin%1_000_000)
+}
+
+func esCodigoTOTP(codigo string) bool {
+	if len(codigo) != totpDigitos {
+		return false
+	}
+	for _, r := range codigo {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizarCodigoRecuperacion(codigo string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(codigo))
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/config"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+// codigoEnPaso calcula el código TOTP del secreto desplazado pasos periodos
+// respecto de ahora
+func codigoEnPaso(t *testing.T, secreto string, ahora time.Time, pasos int64) string {
+	t.Helper()
+
+	key, err := totpBase32.DecodeString(secreto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codigoTOTP(key, ahora.Unix()/totpPeriodo+pasos)
+}
+
+func TestValidarTOTP(t *testing.T) {
+	const secreto = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890" de RFC 6238
+	ahora := time.Now()
+
+	casos := []struct {
+		nombre string
+		codigo string
+		valido bool
+	}{
+		{"paso actual", codigoEnPaso(t, secreto, ahora, 0), true},
+		{"paso anterior por desfase de reloj", codigoEnPaso(t, secreto, ahora, -1), true},
+		{"paso siguiente por desfase de reloj", codigoEnPaso(t, secreto, ahora, 1), true},
+		{"dos pasos atrás", codigoEnPaso(t, secreto, ahora, -2), false},
+		{"dos pasos adelante", codigoEnPaso(t, secreto, ahora, 2), false},
+		{"menos dígitos", "12345", false},
+		{"con letras", "12a456", false},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if _, ok := validarTOTP(secreto, c.codigo, ahora); ok != c.valido {
+				t.Fatalf("validarTOTP(%q) = %v, se esperaba %v", c.codigo, ok, c.valido)
+			}
+		})
+	}
+
+	// Vector de prueba de RFC 6238 (SHA1, T = 59s), recortado a 6 dígitos
+	if paso, ok := validarTOTP(secreto, "287082", time.Unix(59, 0)); !ok || paso != 1 {
+		t.Fatalf("vector de RFC 6238: paso %d, válido %v", paso, ok)
+	}
+}
+
+// dosFactoresActivos enrola y confirma TOTP para un usuario nuevo y retorna
+// su secreto, sus códigos de recuperación y el momento de la confirmación
+func dosFactoresActivos(t *testing.T) (*TwoFactorService, int, string, []string, time.Time) {
+	t.Helper()
+	ctx := context.Background()
+
+	users := repository.NewMemoryUserRepository()
+	usuario := crearUsuarioActivo(t, users, "totp@example.com")
+	tokens := repository.NewMemoryUserTokenRepository()
+	lockout := NewLockoutService(repository.NewMemoryLoginAttemptRepository(), repository.NewMemoryAuditRepository(),
+		users, tokens, nil, lockoutPrueba, "http://localhost:3000")
+	s := NewTwoFactorService(repository.NewMemoryTwoFactorRepository(), tokens, users, lockout,
+		config.TwoFactorConfig{Issuer: "businesslogic", ChallengeTTL: 5 * time.Minute})
+
+	enrolamiento, err := s.Enrolar(ctx, usuario.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmado := time.Now()
+	codigos, err := s.Confirmar(ctx, usuario.ID, codigoEnPaso(t, enrolamiento.Secreto, confirmado, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codigos) != cantidadCodigosRecuperacion {
+		t.Fatalf("se esperaban %d códigos de recuperación, se obtuvieron %d", cantidadCodigosRecuperacion, len(codigos))
+	}
+	return s, usuario.ID, enrolamiento.Secreto, codigos, confirmado
+}
+
+func TestVerificarCodigoSegundoFactor(t *testing.T) {
+	// Los pasos se ejecutan en orden sobre el mismo usuario: un código TOTP o
+	// de recuperación usado una vez ya no sirve
+	s, usuarioID, secreto, recuperacion, ahora := dosFactoresActivos(t)
+
+	pasos := []struct {
+		nombre string
+		codigo string
+		err    error
+	}{
+		{"el código usado al confirmar no se repite", codigoEnPaso(t, secreto, ahora, 0), ErrCodigoSegundoFactorInvalido},
+		{"código del paso siguiente", codigoEnPaso(t, secreto, ahora, 1), nil},
+		{"un paso anterior al último usado", codigoEnPaso(t, secreto, ahora, -1), ErrCodigoSegundoFactorInvalido},
+		{"código TOTP de otro paso lejano", codigoEnPaso(t, secreto, ahora, 5), ErrCodigoSegundoFactorInvalido},
+		{"código de recuperación", recuperacion[0], nil},
+		{"código de recuperación ya usado", recuperacion[0], ErrCodigoSegundoFactorInvalido},
+		{"código de recuperación en mayúsculas y sin guion", strings.ToUpper(strings.ReplaceAll(recuperacion[1], "-", "")), nil},
+		{"código de recuperación con espacios alrededor", "  " + recuperacion[2] + " ", nil},
+		{"código de recuperación inexistente", "aaaaa-bbbbb", ErrCodigoSegundoFactorInvalido},
+	}
+	for _, p := range pasos {
+		f, err := s.activado(context.Background(), usuarioID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.verificarCodigo(context.Background(), f, p.codigo); !errors.Is(err, p.err) {
+			t.Fatalf("%s: se esperaba %v, se obtuvo %v", p.nombre, p.err, err)
+		}
+	}
+}
+
+func TestRegenerarCodigosRecuperacion(t *testing.T) {
+	ctx := context.Background()
+	s, usuarioID, _, anteriores, _ := dosFactoresActivos(t)
+
+	nuevos, err := s.RegenerarCodigosRecuperacion(ctx, usuarioID, anteriores[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		nombre string
+		codigo string
+		err    error
+	}{
+		{"un código anterior ya no vale", anteriores[1], ErrCodigoSegundoFactorInvalido},
+		{"un código nuevo vale", nuevos[0], nil},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			f, err := s.activado(ctx, usuarioID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.verificarCodigo(ctx, f, c.codigo); !errors.Is(err, c.err) {
+				t.Fatalf("se esperaba %v, se obtuvo %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestCodigosIncorrectosConAccessTokenSeLimitan(t *testing.T) {
+	ctx := context.Background()
+	s, usuarioID, _, recuperacion, _ := dosFactoresActivos(t)
+
+	// Los intentos van en orden; desde el segundo fallo se espera BackoffBase
+	intentos := []struct {
+		nombre string
+		codigo string
+		err    error
+	}{
+		{"primer código incorrecto", "aaaaa-bbbbb", ErrCodigoSegundoFactorInvalido},
+		{"segundo código incorrecto", "aaaaa-bbbbb", ErrCodigoSegundoFactorInvalido},
+		{"durante la espera no se revisa ni un código correcto", recuperacion[0], ErrDemasiadosIntentos},
+	}
+	for _, i := range intentos {
+		if err := s.Desactivar(ctx, usuarioID, i.codigo); !errors.Is(err, i.err) {
+			t.Fatalf("%s: se esperaba %v, se obtuvo %v", i.nombre, i.err, err)
+		}
+	}
+	if _, err := s.activado(ctx, usuarioID); err != nil {
+		t.Fatalf("el segundo factor debía seguir activo: %v", err)
+	}
+}
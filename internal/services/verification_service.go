@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/unobeswarch/businesslogic/internal/clients"
+	"github.com/unobeswarch/businesslogic/internal/models"
+	"github.com/unobeswarch/businesslogic/internal/repository"
+)
+
+var (
+	ErrTokenVerificacionInvalido = errors.New("INVALID_VERIFICATION_TOKEN")
+	ErrReenvioMuyFrecuente       = errors.New("VERIFICATION_RESEND_THROTTLED")
+	ErrCorreoEnUso               = errors.New("EMAIL_ALREADY_REGISTERED")
+)
+
+// VerificationService confirma que el usuario es dueño del correo con el que
+// se registró, enviándole un token de un solo uso.
+type VerificationService struct {
+	users          repository.UserRepository
+	tokens         repository.UserTokenRepository
+	mail           clients.MailSender
+	ttl            time.Duration
+	resendInterval time.Duration
+	frontendURL    string
+}
+
+func NewVerificationService(users repository.UserRepository, tokens repository.UserTokenRepository, mail clients.MailSender, ttl, resendInterval time.Duration, frontendURL string) *VerificationService {
+	return &VerificationService{
+		users:          users,
+		tokens:         tokens,
+		mail:           mail,
+		ttl:            ttl,
+		resendInterval: resendInterval,
+		frontendURL:    frontendURL,
+	}
+}
+
+// EnviarVerificacion emite un token nuevo (invalidando los anteriores) y envía
+// el enlace de verificación al correo del usuario.
+func (s *VerificationService) EnviarVerificacion(ctx context.Context, usuarioID int) error {
+	usuario, err := s.users.FindByID(ctx, usuarioID)
+	if err != nil {
+		return err
+	}
+	if usuario.CorreoVerificado {
+		return nil
+	}
+	return s.enviar(ctx, usuario)
+}
+
+// ReenviarVerificacion vuelve a enviar el enlace a una cuenta sin verificar.
+// Como en la recuperación de contraseña, no informa si el correo existe o ya
+// está verificado. Si el último envío fue hace menos de resendInterval
+// retorna ErrReenvioMuyFrecuente y cuánto falta para poder reenviar.
+func (s *VerificationService) ReenviarVerificacion(ctx context.Context, correo string) (time.Duration, error) {
+	usuario, err := s.users.FindByEmail(ctx, correo)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if usuario.CorreoVerificado {
+		return 0, nil
+	}
+
+	ultimo, err := s.tokens.FindLatestForUser(ctx, usuario.ID, models.PropositoVerificarCorreo)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return 0, err
+	}
+	if ultimo != nil {
+		if espera := s.resendInterval - time.Since(ultimo.CreadoEn); espera > 0 {
+			return espera, ErrReenvioMuyFrecuente
+		}
+	}
+
+	return 0, s.enviar(ctx, usuario)
+}
+
+// VerificarCorreo consume el token y marca el correo del usuario como verificado
+func (s *VerificationService) VerificarCorreo(ctx context.Context, token string) error {
+	stored, err := s.tokens.FindByHash(ctx, models.PropositoVerificarCorreo, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTokenVerificacionInvalido
+		}
+		return err
+	}
+	if stored.UsadoEn != nil || time.Now().After(stored.ExpiraEn) {
+		return ErrTokenVerificacionInvalido
+	}
+
+	if err := s.tokens.MarkUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, repository.ErrAlreadyUsed) {
+			return ErrTokenVerificacionInvalido
+		}
+		return err
+	}
+
+	usuario, err := s.users.FindByID(ctx, stored.UsuarioID)
+	if err != nil {
+		return err
+	}
+	if usuario.CorreoVerificado {
+		return nil
+	}
+	usuario.CorreoVerificado = true
+	return s.users.Update(ctx, usuario)
+}
+
+// EnviarCambioCorreo envía al correo pendiente del usuario el enlace que
+// confirma el cambio de correo pedido desde su perfil
+func (s *VerificationService) EnviarCambioCorreo(ctx context.Context, usuario *models.User) error {
+	if err := s.tokens.InvalidateForUser(ctx, usuario.ID, models.PropositoCambiarCorreo); err != nil {
+		return err
+	}
+
+	token, err := generarTokenOpaco()
+	if err != nil {
+		return err
+	}
+	err = s.tokens.Create(ctx, &models.UserToken{
+		UsuarioID: usuario.ID,
+		Proposito: models.PropositoCambiarCorreo,
+		TokenHash: hashToken(token),
+		ExpiraEn:  time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.CorreoPendiente,
+		Subject: "Confirma tu nuevo correo",
+		Body: fmt.Sprintf(`Hola %s,
+
+Pediste usar esta dirección como el correo de tu cuenta. Para confirmar el
+cambio abre el siguiente enlace (válido por %s):
+
+%s/verify-email/change?token=%s
+
+Hasta que lo confirmes seguirás iniciando sesión con tu correo actual.
+`, usuario.NombreCompleto, formatDuration(s.ttl), s.frontendURL, url.QueryEscape(token)),
+	})
+	return nil
+}
+
+// ConfirmarCambioCorreo consume el token y reemplaza el correo del usuario por
+// el pendiente. Se avisa al correo anterior por si el cambio no fue suyo.
+func (s *VerificationService) ConfirmarCambioCorreo(ctx context.Context, token string) error {
+	stored, err := s.tokens.FindByHash(ctx, models.PropositoCambiarCorreo, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTokenVerificacionInvalido
+		}
+		return err
+	}
+	if stored.UsadoEn != nil || time.Now().After(stored.ExpiraEn) {
+		return ErrTokenVerificacionInvalido
+	}
+
+	usuario, err := s.users.FindByID(ctx, stored.UsuarioID)
+	if err != nil {
+		return err
+	}
+	if usuario.CorreoPendiente == "" {
+		return ErrTokenVerificacionInvalido
+	}
+	// Otra cuenta pudo registrarse con el correo mientras el cambio estaba pendiente
+	otro, err := s.users.FindByEmail(ctx, usuario.CorreoPendiente)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if otro != nil && otro.ID != usuario.ID {
+		return ErrCorreoEnUso
+	}
+
+	if err := s.tokens.MarkUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, repository.ErrAlreadyUsed) {
+			return ErrTokenVerificacionInvalido
+		}
+		return err
+	}
+
+	anterior := usuario.Correo
+	usuario.Correo = usuario.CorreoPendiente
+	usuario.CorreoPendiente = ""
+	usuario.CorreoVerificado = true
+	if err := s.users.Update(ctx, usuario); err != nil {
+		return err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      anterior,
+		Subject: "El correo de tu cuenta cambió",
+		Body: fmt.Sprintf(`Hola %s,
+
+El correo de tu cuenta ahora es %s. Si no hiciste este cambio, contacta
+a soporte de inmediato.
+`, usuario.NombreCompleto, usuario.Correo),
+	})
+	return nil
+}
+
+func (s *VerificationService) enviar(ctx context.Context, usuario *models.User) error {
+	if err := s.tokens.InvalidateForUser(ctx, usuario.ID, models.PropositoVerificarCorreo); err != nil {
+		return err
+	}
+
+	token, err := generarTokenOpaco()
+	if err != nil {
+		return err
+	}
+	err = s.tokens.Create(ctx, &models.UserToken{
+		UsuarioID: usuario.ID,
+		Proposito: models.PropositoVerificarCorreo,
+		TokenHash: hashToken(token),
+		ExpiraEn:  time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	enviarCorreoEnSegundoPlano(s.mail, usuario.ID, clients.MailMessage{
+		To:      usuario.Correo,
+		Subject: "Verifica tu correo",
+		Body: fmt.Sprintf(`Hola %s,
+
+Gracias por registrarte. Para activar tu cuenta confirma tu correo abriendo
+el siguiente enlace (válido por %s):
+
+%s/verify-email?token=%s
+
+Si no creaste esta cuenta puedes ignorar este correo.
+`, usuario.NombreCompleto, formatDuration(s.ttl), s.frontendURL, url.QueryEscape(token)),
+	})
+	return nil
+}